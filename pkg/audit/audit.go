@@ -0,0 +1,3347 @@
+// Package audit implements the audit check engine: built-in and user-defined
+// checks that inspect a ClickHouse cluster through system tables and produce
+// severity-ranked findings. It has no TUI dependencies, so other tools can
+// embed it (e.g. run audits from an ops bot) without shelling out to the
+// binary.
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"github.com/Slach/clickhouse-timeline/pkg/client"
+	"github.com/Slach/clickhouse-timeline/pkg/config"
+	"github.com/rs/zerolog/log"
+)
+
+// Auditor runs the audit check catalog against one cluster
+type Auditor struct {
+	clickHouse *client.Client
+	cluster    string
+	cfg        *config.Config
+	queryCtx   func() context.Context
+	// backupAPI is an optional clickhouse-backup API endpoint the backup
+	// checks query in addition to system.backups (see WithBackupAPI)
+	backupAPI string
+	localHost string
+	// query failures collected while checks run, reported as findings so
+	// partial results are visible instead of silently incomplete
+	failures   []string
+	failuresMu sync.Mutex
+}
+
+// NewAuditor creates an auditor for the given connection, cluster and config
+// (cfg may be nil when no severity overrides are needed)
+func NewAuditor(clickHouse *client.Client, cluster string, cfg *config.Config) *Auditor {
+	return &Auditor{clickHouse: clickHouse, cluster: cluster, cfg: cfg}
+}
+
+// WithQueryContext makes the auditor run its queries under contexts supplied
+// by fn; the TUI passes its per-page cancellable context here
+func (a *Auditor) WithQueryContext(fn func() context.Context) *Auditor {
+	a.queryCtx = fn
+	return a
+}
+
+func (a *Auditor) queryContext() context.Context {
+	if a.queryCtx != nil {
+		return a.queryCtx()
+	}
+	return context.Background()
+}
+
+// localHostName resolves the connected node's hostName() once; it stamps
+// findings from checks that query only the local node, so the Host column is
+// populated consistently across all checks
+func (a *Auditor) localHostName() string {
+	if a.localHost != "" {
+		return a.localHost
+	}
+	row := a.queryRow("SELECT hostName()")
+	if row != nil {
+		if err := row.Scan(&a.localHost); err != nil {
+			log.Error().Err(err).Msg("can't resolve local hostName")
+		}
+	}
+	return a.localHost
+}
+
+// eachHostRow runs a per-host aggregate (first selected column must be
+// hostName()) and invokes fn for every returned row, so replicas produce
+// separate findings instead of only the first scanned host. Query errors are
+// treated as "no finding", matching the single-row checks.
+func (a *Auditor) eachHostRow(query string, fn func(rows *sql.Rows) error) {
+	rows, err := a.query(query)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close eachHostRow rows")
+		}
+	}()
+	for rows.Next() {
+		if scanErr := fn(rows); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan eachHostRow row")
+		}
+	}
+}
+
+// auditHostTimeout caps how long a fanned-out audit query may spend per host,
+// so one stuck replica doesn't stall the whole run
+const auditHostTimeout = 60 // seconds
+
+// auditWorkers bounds how many checks run concurrently; each check issues a
+// handful of queries, so this stays below the client's connection pool size
+const auditWorkers = 4
+
+// clusterTable renders the FROM source for a system table: fanned out to
+// every host via clusterAllReplicas() when a cluster is configured, the local
+// table otherwise, so checks read the same way in both modes and findings
+// carry the right hostName()
+func (a *Auditor) clusterTable(name string) string {
+	if a.cluster == "" {
+		return "system." + name
+	}
+	return fmt.Sprintf("clusterAllReplicas('%s', system.%s)", chquery.EscapeString(a.cluster), name)
+}
+
+// withClusterSettings appends the fan-out settings to queries that reach out
+// to other hosts: unreachable replicas yield partial results instead of
+// failing the check, and each host gets its own execution timeout
+func (a *Auditor) withClusterSettings(query string) string {
+	if a.cluster == "" || !strings.Contains(query, "clusterAllReplicas(") {
+		return query
+	}
+	return fmt.Sprintf("%s SETTINGS skip_unavailable_shards=1, max_execution_time=%d", query, auditHostTimeout)
+}
+
+// query runs a check query through the cluster execution layer, recording
+// failures for the partial failure report
+func (a *Auditor) query(query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := a.clickHouse.QueryContext(a.queryContext(), a.withClusterSettings(query), args...)
+	if err != nil {
+		a.noteFailure(err)
+	}
+	return rows, err
+}
+
+// queryRow is the single-row variant of query
+func (a *Auditor) queryRow(query string, args ...interface{}) *sql.Row {
+	row := a.clickHouse.QueryRowContext(a.queryContext(), a.withClusterSettings(query), args...)
+	if row != nil && row.Err() != nil {
+		a.noteFailure(row.Err())
+	}
+	return row
+}
+
+func (a *Auditor) noteFailure(err error) {
+	// a cancelled audit is not a partial one, don't report every aborted query
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	a.failuresMu.Lock()
+	a.failures = append(a.failures, err.Error())
+	a.failuresMu.Unlock()
+}
+
+// takeFailures drains the failures collected since the last call
+func (a *Auditor) takeFailures() []string {
+	a.failuresMu.Lock()
+	failures := a.failures
+	a.failures = nil
+	a.failuresMu.Unlock()
+	return failures
+}
+
+// Result represents a single audit finding
+type Result struct {
+	ID       string
+	Host     string
+	Object   string
+	Severity string
+	Details  string
+	Values   map[string]float64
+	// Group is the check family the finding came from (filled by RunChecks)
+	Group string
+	// Cluster identifies which cluster the finding came from (filled by
+	// RunChecks); headless multi-context audits relabel it per connection so
+	// a combined fleet report stays attributable
+	Cluster string
+	// Remediation is a SQL/settings template addressing the finding; checks
+	// can set a specific one, RunChecks fills the rest from the hint catalog
+	Remediation string
+}
+
+// auditCheck is a single named entry in the audit check catalog; ids lists
+// the finding ID prefixes the check can produce, so --check selectors can run
+// only the relevant groups
+type auditCheck struct {
+	name string
+	ids  []string
+	fn   func() []Result
+}
+
+func (a *Auditor) auditChecks() []auditCheck {
+	checks := []auditCheck{
+		{"System Counts", []string{"A0.1"}, a.checkSystemCounts},
+		{"System Logs", []string{"A0.2"}, a.checkSystemLogs},
+		{"Rates", []string{"A0.3"}, a.checkRates},
+		{"Partitions", []string{"A1.1"}, a.checkPartitions},
+		{"Active Parts", []string{"A1.5.01.1"}, a.checkActiveParts},
+		{"Marks Cache", []string{"A1.2.02", "A1.2.03", "A1.2.04", "A1.2.05", "A1.2.06"}, a.checkMarksCache},
+		{"Tables", []string{"A1.3"}, a.checkTables},
+		{"Background Pools", []string{"A1.4"}, a.checkBackgroundPools},
+		{"Uncompressed Cache", []string{"A1.5.01", "A1.5.02"}, a.checkUncompressedCache},
+		{"Replication Queue", []string{"A1.6"}, a.checkReplicationQueue},
+		{"Memory Usage", []string{"A1.7", "A3.0.15"}, a.checkMemoryUsage},
+		{"Disk Usage", []string{"A1.8"}, a.checkDiskUsage},
+		{"Mutations", []string{"A1.9"}, a.checkMutations},
+		{"Primary Key Marks", []string{"A1.2.01"}, a.checkPrimaryKeyMarks},
+		{"Primary Keys", []string{"A2.4"}, a.checkPrimaryKeys},
+		{"Materialized Views", []string{"A2.2"}, a.checkMaterializedViews},
+		{"Performance Metrics", []string{"A3.0"}, a.checkPerformanceMetrics},
+		{"Version Check", []string{"A.2.1"}, a.checkVersions},
+		{"Long Names", []string{"A0.0.6"}, a.checkLongNames},
+		{"Dependencies", []string{"A2.3"}, a.checkDependencies},
+		{"Backups", []string{"A4.0"}, a.checkBackups},
+		{"Access Control", []string{"A5.0"}, a.checkAccessControl},
+	}
+
+	// User-defined checks from ~/.clickhouse-timeline/audit-checks.yaml join
+	// the catalog, so they get progress reporting, sorting and details for free
+	for _, custom := range loadCustomAuditChecks() {
+		check := custom
+		name := check.Name
+		if name == "" {
+			name = check.ID
+		}
+		checks = append(checks, auditCheck{name, []string{check.ID}, func() []Result {
+			return a.runCustomCheck(check)
+		}})
+	}
+
+	return checks
+}
+
+// RunChecks executes every audit check sequentially, reporting progress via
+// the optional callback, and returns all results
+func (a *Auditor) RunChecks(progress func(name string, step, total int)) []Result {
+	return a.runChecks(a.auditChecks(), progress)
+}
+
+// RunChecksFiltered executes only the checks picked by the comma-separated
+// selectors. A selector is either a check group name ("Replication Queue",
+// case and separators ignored) or a finding ID or ID prefix ("A1.6", "A0.2");
+// ID selectors additionally narrow the returned findings to matching IDs.
+func (a *Auditor) RunChecksFiltered(selectors string, progress func(name string, step, total int)) ([]Result, error) {
+	checks := a.auditChecks()
+
+	var idSelectors []string
+	selectedNames := make(map[string]bool)
+	nameSelected := make(map[string]bool)
+	var selected []auditCheck
+	for _, raw := range strings.Split(selectors, ",") {
+		selector := strings.TrimSpace(raw)
+		if selector == "" {
+			continue
+		}
+		matched := false
+		for _, check := range checks {
+			if normalizeCheckName(selector) == normalizeCheckName(check.name) {
+				matched = true
+				nameSelected[check.name] = true
+				if !selectedNames[check.name] {
+					selectedNames[check.name] = true
+					selected = append(selected, check)
+				}
+				continue
+			}
+			for _, id := range check.ids {
+				if checkIDMatches(selector, id) {
+					matched = true
+					idSelectors = append(idSelectors, selector)
+					if !selectedNames[check.name] {
+						selectedNames[check.name] = true
+						selected = append(selected, check)
+					}
+					break
+				}
+			}
+		}
+		if !matched {
+			names := make([]string, 0, len(checks))
+			for _, check := range checks {
+				names = append(names, check.name)
+			}
+			return nil, fmt.Errorf("unknown check '%s', available: %s", selector, strings.Join(names, ", "))
+		}
+	}
+
+	results := a.runChecks(selected, progress)
+
+	// Groups picked only via an ID selector return just the matching findings;
+	// groups picked by name keep everything they produced
+	if len(idSelectors) > 0 {
+		filtered := results[:0]
+		for _, result := range results {
+			keep := nameSelected[result.Group]
+			for _, selector := range idSelectors {
+				if keep {
+					break
+				}
+				if checkIDMatches(selector, result.ID) || checkIDMatches(result.ID, selector) {
+					keep = true
+				}
+			}
+			if keep {
+				filtered = append(filtered, result)
+			}
+		}
+		results = filtered
+	}
+	return results, nil
+}
+
+// runChecks executes the checks on a bounded worker pool; results keep the
+// catalog order regardless of which check finishes first. Cancelling the
+// query context (the TUI does this on Esc) aborts in-flight queries
+// server-side and skips the checks that haven't started yet.
+func (a *Auditor) runChecks(checks []auditCheck, progress func(name string, step, total int)) []Result {
+	localHost := a.localHostName()
+	ctx := a.queryContext()
+
+	resultsPerCheck := make([][]Result, len(checks))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var progressMu sync.Mutex
+	completed := 0
+	for worker := 0; worker < auditWorkers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				resultsPerCheck[i] = checks[i].fn()
+				progressMu.Lock()
+				completed++
+				step := completed
+				progressMu.Unlock()
+				if progress != nil {
+					progress(checks[i].name, step, len(checks))
+				}
+			}
+		}()
+	}
+	for i := range checks {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	allResults := make([]Result, 0)
+	for i, check := range checks {
+		checkResults := resultsPerCheck[i]
+		for j := range checkResults {
+			checkResults[j].Group = check.name
+			checkResults[j].Cluster = a.cluster
+			// node-local checks don't know their host, stamp it here
+			if checkResults[j].Host == "" {
+				checkResults[j].Host = localHost
+			}
+			if checkResults[j].Remediation == "" {
+				checkResults[j].Remediation = remediationFor(checkResults[j].ID)
+			}
+		}
+		allResults = append(allResults, checkResults...)
+	}
+	// queries that failed mid-check become findings themselves, so a
+	// partially unreachable cluster is visible in the report
+	for _, failure := range a.takeFailures() {
+		allResults = append(allResults, Result{
+			ID:       "A0.0",
+			Host:     localHost,
+			Cluster:  a.cluster,
+			Object:   "audit",
+			Severity: "Minor",
+			Details:  fmt.Sprintf("check ran partially: %s", failure),
+			Values:   map[string]float64{},
+			Group:    "Audit Engine",
+		})
+	}
+	return a.applyAuditOverrides(allResults)
+}
+
+// normalizeCheckName makes group name matching forgiving about case and
+// separators, so "replication-queue" selects "Replication Queue"
+func normalizeCheckName(name string) string {
+	replacer := strings.NewReplacer(" ", "", "-", "", "_", "")
+	return strings.ToLower(replacer.Replace(name))
+}
+
+// checkIDMatches reports whether selector picks id: exact match or a
+// dot-separated prefix in either direction ("A1.6" picks "A1.6.1" and
+// "A1.6.1" picks the group registered under "A1.6")
+func checkIDMatches(selector, id string) bool {
+	return selector == id || strings.HasPrefix(id, selector+".") || strings.HasPrefix(selector, id+".")
+}
+
+// Impact estimates the blast radius of a finding from its Values:
+// byte-sized values outrank row counts, which outrank plain object counts,
+// so a finding touching terabytes sorts above one touching a few tables
+func Impact(result Result) float64 {
+	var maxBytes, maxRows, maxOther float64
+	for key, value := range result.Values {
+		lowerKey := strings.ToLower(key)
+		switch {
+		case strings.Contains(lowerKey, "bytes") || strings.Contains(lowerKey, "size"):
+			if value > maxBytes {
+				maxBytes = value
+			}
+		case strings.Contains(lowerKey, "rows"):
+			if value > maxRows {
+				maxRows = value
+			}
+		default:
+			if value > maxOther {
+				maxOther = value
+			}
+		}
+	}
+	if maxBytes > 0 {
+		return maxBytes
+	}
+	if maxRows > 0 {
+		return maxRows
+	}
+	return maxOther
+}
+
+// SortResults sorts results by severity (Critical, Major, Moderate, Minor)
+func SortResults(results []Result) {
+	severityOrder := map[string]int{
+		"Critical": 0,
+		"Major":    1,
+		"Moderate": 2,
+		"Minor":    3,
+	}
+
+	// Simple sort
+	for i := 0; i < len(results); i++ {
+		for j := i + 1; j < len(results); j++ {
+			if severityOrder[results[i].Severity] > severityOrder[results[j].Severity] {
+				results[i], results[j] = results[j], results[i]
+			}
+		}
+	}
+}
+
+// SortResultsByImpact sorts results by estimated blast radius (descending),
+// falling back to severity order for equal impact
+func SortResultsByImpact(results []Result) {
+	severityOrder := map[string]int{
+		"Critical": 0,
+		"Major":    1,
+		"Moderate": 2,
+		"Minor":    3,
+	}
+
+	// Simple sort
+	for i := 0; i < len(results); i++ {
+		for j := i + 1; j < len(results); j++ {
+			impactI := Impact(results[i])
+			impactJ := Impact(results[j])
+			if impactI < impactJ || (impactI == impactJ && severityOrder[results[i].Severity] > severityOrder[results[j].Severity]) {
+				results[i], results[j] = results[j], results[i]
+			}
+		}
+	}
+}
+
+// Audit check implementations
+func (a *Auditor) checkSystemCounts() []Result {
+	var results []Result
+
+	// Check replicated tables count
+	a.eachHostRow(fmt.Sprintf("SELECT hostName() AS h, count() FROM %s WHERE engine LIKE 'Replicated%%' GROUP BY h", a.clusterTable("tables")), func(rows *sql.Rows) error {
+		var host string
+		var replicatedCount int64
+		if err := rows.Scan(&host, &replicatedCount); err != nil {
+			return err
+		}
+		severity := ""
+		if replicatedCount > 2000 {
+			severity = "Critical"
+		} else if replicatedCount > 900 {
+			severity = "Major"
+		} else if replicatedCount > 200 {
+			severity = "Moderate"
+		}
+
+		if severity != "" {
+			results = append(results, Result{
+				ID:       "A0.1.01",
+				Host:     host,
+				Object:   "ReplicatedTables",
+				Severity: severity,
+				Details:  fmt.Sprintf("Too many replicated tables (count: %d) - background_schedule_pool_size should be tuned", replicatedCount),
+				Values:   map[string]float64{"replicated_tables_count": float64(replicatedCount)},
+			})
+
+		}
+		return nil
+	})
+
+	// Check MergeTree tables count
+	a.eachHostRow(fmt.Sprintf("SELECT hostName() AS h, count() FROM %s WHERE engine LIKE '%%MergeTree%%' GROUP BY h", a.clusterTable("tables")), func(rows *sql.Rows) error {
+		var host string
+		mergeTreeCount := 0
+		if err := rows.Scan(&host, &mergeTreeCount); err != nil {
+			return err
+		}
+		severity := ""
+		if mergeTreeCount > 10000 {
+			severity = "Critical"
+		} else if mergeTreeCount > 3000 {
+			severity = "Major"
+		} else if mergeTreeCount > 1000 {
+			severity = "Moderate"
+		}
+		if severity != "" {
+			results = append(results, Result{
+				ID:       "A0.1.02",
+				Host:     host,
+				Object:   "MergeTreeTables",
+				Severity: severity,
+				Details:  fmt.Sprintf("Too many MergeTree tables (count: %d)", mergeTreeCount),
+				Values:   map[string]float64{"merge_tree_tables_count": float64(mergeTreeCount)},
+			})
+
+		}
+		return nil
+	})
+
+	// Check databases count
+	a.eachHostRow(fmt.Sprintf("SELECT hostName() AS h, count() FROM %s GROUP BY h", a.clusterTable("databases")), func(rows *sql.Rows) error {
+		var host string
+		databasesCount := 0
+		if err := rows.Scan(&host, &databasesCount); err != nil {
+			return err
+		}
+		severity := ""
+		if databasesCount > 1000 {
+			severity = "Critical"
+		} else if databasesCount > 300 {
+			severity = "Major"
+		} else if databasesCount > 100 {
+			severity = "Moderate"
+		}
+
+		if severity != "" {
+			results = append(results, Result{
+				ID:       "A0.1.03",
+				Host:     host,
+				Object:   "Databases",
+				Severity: severity,
+				Details:  fmt.Sprintf("Too many databases (count: %d)", databasesCount),
+				Values:   map[string]float64{"databases_count": float64(databasesCount)},
+			})
+		}
+		return nil
+	})
+
+	// Check column files in parts vs inodes
+	a.eachHostRow(fmt.Sprintf(`
+		SELECT 
+			hostName() AS h,
+			(SELECT count() * 4 FROM %s) as column_files_in_parts_count,
+			(SELECT min(value) FROM %s WHERE metric='FilesystemMainPathTotalINodes') as total_inodes,
+			column_files_in_parts_count / total_inodes as ratio
+		GROUP BY h
+	`, a.clusterTable("parts_columns"), a.clusterTable("asynchronous_metrics")), func(rows *sql.Rows) error {
+		var host string
+		var columnFilesCount, totalInodes int64
+		var inodesRatio float64
+		if err := rows.Scan(&host, &columnFilesCount, &totalInodes, &inodesRatio); err != nil {
+			return err
+		}
+		if inodesRatio <= 0.5 {
+			return nil
+		}
+		severity := ""
+		if inodesRatio > 0.8 {
+			severity = "Critical"
+		} else if inodesRatio > 0.7 {
+			severity = "Major"
+		} else if inodesRatio > 0.6 {
+			severity = "Moderate"
+		}
+
+		if severity != "" {
+			results = append(results, Result{
+				ID:       "A0.1.04",
+				Host:     host,
+				Object:   "PartsColumns",
+				Severity: severity,
+				Details:  fmt.Sprintf("Total columns files in parts too close to max inodes (column_files: %d, inodes: %d)", columnFilesCount, totalInodes),
+				Values: map[string]float64{
+					"column_files_in_parts_count": float64(columnFilesCount),
+					"total_inodes":                float64(totalInodes),
+				},
+			})
+		}
+		return nil
+	})
+
+	// Check total parts count
+	a.eachHostRow(fmt.Sprintf("SELECT hostName() AS h, count() FROM %s GROUP BY h", a.clusterTable("parts")), func(rows *sql.Rows) error {
+		var host string
+		partsCount := 0
+		if err := rows.Scan(&host, &partsCount); err != nil {
+			return err
+		}
+		severity := ""
+		if partsCount > 120000 {
+			severity = "Critical"
+		} else if partsCount > 90000 {
+			severity = "Major"
+		} else if partsCount > 60000 {
+			severity = "Moderate"
+		}
+		if severity != "" {
+			results = append(results, Result{
+				ID:       "A0.1.05",
+				Host:     host,
+				Object:   "Parts",
+				Severity: severity,
+				Details:  fmt.Sprintf("Too many parts (count: %d)", partsCount),
+				Values:   map[string]float64{"parts_count": float64(partsCount)},
+			})
+		}
+		return nil
+	})
+
+	// Check obsolete inactive parts
+	a.eachHostRow(fmt.Sprintf(`
+		WITH (SELECT max(modification_time) FROM %s) AS max_ts
+		SELECT hostName() AS h, count()
+		FROM %s
+		WHERE NOT active
+		AND ((remove_time > 0 AND remove_time < max_ts - INTERVAL 20 MINUTE) 
+		     OR (remove_time = 0 AND modification_time < max_ts - INTERVAL 20 MINUTE))
+		GROUP BY h
+	`, a.clusterTable("parts"), a.clusterTable("parts")), func(rows *sql.Rows) error {
+		var host string
+		var obsoletePartsCount int64
+		if err := rows.Scan(&host, &obsoletePartsCount); err != nil {
+			return err
+		}
+		if obsoletePartsCount <= 0 {
+			return nil
+		}
+		severity := ""
+		if obsoletePartsCount > 5000 {
+			severity = "Critical"
+		} else if obsoletePartsCount > 2000 {
+			severity = "Major"
+		} else if obsoletePartsCount > 500 {
+			severity = "Moderate"
+		}
+
+		if severity != "" {
+			results = append(results, Result{
+				ID:       "A0.1.06",
+				Host:     host,
+				Object:   "Obsolete parts",
+				Severity: severity,
+				Details:  fmt.Sprintf("Number of inactive parts which were removed long ago (count: %d)", obsoletePartsCount),
+				Values:   map[string]float64{"parts_count": float64(obsoletePartsCount)},
+			})
+		}
+		return nil
+	})
+
+	// Check for too many tiny replicated tables
+	a.eachHostRow(fmt.Sprintf(`
+		WITH
+			(total_rows < 1000000) AND (total_bytes < 10000000) AS tiny_table,
+			(total_rows < 100000000) AND (total_bytes < 1000000000) AND (NOT tiny_table) AS small_table,
+			(total_rows > 1000000000) OR (total_bytes > 100000000000) AS big_table
+		SELECT
+			hostName() AS h,
+			countIf(tiny_table) as tiny_tables_count,
+			countIf(small_table) as small_tables_count,
+			countIf((NOT big_table) AND (NOT small_table) AND (NOT tiny_table)) as medium_tables_count,
+			countIf(big_table) as big_tables_count,
+			count() AS tables_count
+		FROM %s
+		WHERE engine LIKE 'Replicated%%MergeTree'
+		GROUP BY h
+	`, a.clusterTable("tables")), func(rows *sql.Rows) error {
+		var host string
+		var tinyTablesCount, smallTablesCount, mediumTablesCount, bigTablesCount, tablesCount int64
+		if err := rows.Scan(&host, &tinyTablesCount, &smallTablesCount, &mediumTablesCount, &bigTablesCount, &tablesCount); err != nil {
+			return err
+		}
+		if ((tinyTablesCount + smallTablesCount) > int64(float64(tablesCount)*0.85)) || ((tinyTablesCount + smallTablesCount) > 100) {
+			results = append(results, Result{
+				ID:       "A0.1.07",
+				Host:     host,
+				Object:   "Tables Size",
+				Severity: "Major",
+				Details:  fmt.Sprintf("Most of your Replicated tables are tiny, consider options to combine similar data together in fewer tables (tiny: %d, small: %d, medium: %d, big: %d, overall: %d)", tinyTablesCount, smallTablesCount, mediumTablesCount, bigTablesCount, tablesCount),
+				Values: map[string]float64{
+					"tiny_tables_count":   float64(tinyTablesCount),
+					"small_tables_count":  float64(smallTablesCount),
+					"medium_tables_count": float64(mediumTablesCount),
+					"big_tables_count":    float64(bigTablesCount),
+					"tables_count":        float64(tablesCount),
+				},
+			})
+		}
+		return nil
+	})
+
+	return results
+}
+
+func (a *Auditor) checkDependencies() []Result {
+	var results []Result
+
+	// One scan of system.tables yields the direct edges (explicit table
+	// dependencies plus MaterializedView TO targets); the transitive closure
+	// is built client-side, so the check needs no temporary table, works for
+	// read-only users and costs a single round trip on large schemas
+	rows, err := a.query(fmt.Sprintf(`
+		SELECT
+			hostName() AS host,
+			format('{}.{}', database, name) AS parent,
+			arrayJoin(arrayMap(x, y -> x || '.' || y, dependencies_database, dependencies_table)) as child
+		FROM %s
+		WHERE dependencies_table != []
+
+		UNION ALL
+
+		WITH splitByChar(' ', create_table_query) as _create_table_query
+		SELECT
+			hostName() AS host,
+			format('{}.{}', database, name) AS parent,
+			_create_table_query[6] as child
+		FROM %s
+		WHERE engine = 'MaterializedView'
+		AND _create_table_query[5] = 'TO'
+	`, a.clusterTable("tables"), a.clusterTable("tables")))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch table dependencies")
+		return results
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close checkDependencies")
+		}
+	}()
+
+	// host -> parent -> direct children
+	edges := make(map[string]map[string][]string)
+	for rows.Next() {
+		var host, parent, child string
+		if scanErr := rows.Scan(&host, &parent, &child); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan dependency edge")
+			continue
+		}
+		if _, found := edges[host]; !found {
+			edges[host] = make(map[string][]string)
+		}
+		edges[host][parent] = append(edges[host][parent], child)
+	}
+
+	// Check for tables with too many dependencies (A2.3 logic)
+	hosts := make([]string, 0, len(edges))
+	for host := range edges {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		parents := make([]string, 0, len(edges[host]))
+		for parent := range edges[host] {
+			parents = append(parents, parent)
+		}
+		sort.Strings(parents)
+		for _, parent := range parents {
+			closure := dependencyClosure(edges[host], parent)
+			if len(closure) <= 10 {
+				continue
+			}
+			// Create values map from children list
+			values := make(map[string]float64)
+			for i, childName := range closure {
+				if i < 20 { // Limit to avoid too many values
+					values[childName] = 1.0
+				}
+			}
+			values["total_dependencies"] = float64(len(closure))
+
+			results = append(results, Result{
+				ID:       "A2.3",
+				Host:     host,
+				Object:   parent,
+				Severity: "Moderate",
+				Details:  fmt.Sprintf("Too long dependencies list. count: %d", len(closure)),
+				Values:   values,
+			})
+		}
+	}
+
+	return results
+}
+
+// dependencyClosure returns every table reachable from parent over the direct
+// edges, sorted; cycles are handled by the visited set and parent itself is
+// not part of its own closure
+func dependencyClosure(edges map[string][]string, parent string) []string {
+	visited := map[string]bool{parent: true}
+	stack := append([]string(nil), edges[parent]...)
+	var closure []string
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[node] {
+			continue
+		}
+		visited[node] = true
+		closure = append(closure, node)
+		stack = append(stack, edges[node]...)
+	}
+	sort.Strings(closure)
+	return closure
+}
+
+func (a *Auditor) checkRates() []Result {
+	var results []Result
+
+	// Check parts creation rate
+	row := a.queryRow(fmt.Sprintf(`
+		WITH 
+			(SELECT max(toUInt32(value)) FROM %s WHERE name='old_parts_lifetime') as old_parts_lifetime_raw,
+			if(old_parts_lifetime_raw IS NULL OR old_parts_lifetime_raw = 0, 480, old_parts_lifetime_raw) as old_parts_lifetime
+		SELECT 
+			hostName() AS h,
+			count() as parts_created_count,
+			parts_created_count / old_parts_lifetime as parts_created_per_second
+		FROM %s 
+		WHERE modification_time > (SELECT max(modification_time) FROM %s) - old_parts_lifetime 
+		AND level = 0
+		GROUP BY h
+	`, a.clusterTable("merge_tree_settings"), a.clusterTable("parts"), a.clusterTable("parts")))
+	var host string
+	var partsCreatedCount int64
+	var partsCreatedPerSecond float64
+	if err := row.Scan(&host, &partsCreatedCount, &partsCreatedPerSecond); err == nil && partsCreatedPerSecond > 5 {
+		severity := "Minor"
+		if partsCreatedPerSecond > 50 {
+			severity = "Critical"
+		} else if partsCreatedPerSecond > 30 {
+			severity = "Major"
+		} else if partsCreatedPerSecond > 10 {
+			severity = "Moderate"
+		}
+
+		results = append(results, Result{
+			ID:       "A0.3.01",
+			Host:     host,
+			Object:   "PartsCreatedPerSecond",
+			Severity: severity,
+			Details:  fmt.Sprintf("Too many parts created per second (%.2f)", partsCreatedPerSecond),
+			Values:   map[string]float64{"parts_created_per_second": partsCreatedPerSecond},
+		})
+	}
+
+	// Check parts creation rate per table
+	rows, err := a.query(fmt.Sprintf(`
+		WITH 
+			(SELECT max(toUInt32(value)) FROM %s WHERE name='old_parts_lifetime') as old_parts_lifetime_raw,
+			if(old_parts_lifetime_raw IS NULL OR old_parts_lifetime_raw = 0, 480, old_parts_lifetime_raw) as old_parts_lifetime
+		SELECT 
+			hostName() AS h,
+			database,
+			table,
+			count() as parts_created_count,
+			parts_created_count / old_parts_lifetime as parts_created_per_second
+		FROM %s 
+		WHERE modification_time > (SELECT max(modification_time) FROM %s) - old_parts_lifetime 
+		AND level = 0
+		GROUP BY h, database, table
+		HAVING parts_created_per_second > 5
+	`, a.clusterTable("merge_tree_settings"), a.clusterTable("parts"), a.clusterTable("parts")))
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkRates")
+			}
+		}()
+		for rows.Next() {
+			var host, database, table string
+			var partsCount int64
+			var rate float64
+			if err := rows.Scan(&host, &database, &table, &partsCount, &rate); err == nil {
+				severity := "Minor"
+				if rate > 50 {
+					severity = "Critical"
+				} else if rate > 30 {
+					severity = "Major"
+				} else if rate > 10 {
+					severity = "Moderate"
+				}
+
+				results = append(results, Result{
+					ID:       "A0.3.02",
+					Host:     host,
+					Object:   fmt.Sprintf("%s.%s", database, table),
+					Severity: severity,
+					Details:  fmt.Sprintf("Too many parts created per second (%.2f)", rate),
+					Values:   map[string]float64{"parts_created_per_second": rate},
+				})
+			}
+		}
+	}
+
+	return results
+}
+
+func (a *Auditor) checkMarksCache() []Result {
+	var results []Result
+
+	// Check marks cache hit ratio
+	row := a.queryRow(fmt.Sprintf(`
+		SELECT 
+			hostName() AS h,
+			(SELECT value FROM %s WHERE event = 'MarkCacheHits') as hits,
+			(SELECT value FROM %s WHERE event = 'MarkCacheMisses') as misses,
+			hits / (hits + misses) as hit_ratio
+		GROUP BY h
+	`, a.clusterTable("events"), a.clusterTable("events")))
+	var host string
+	var hits, misses, hitRatio float64
+	if err := row.Scan(&host, &hits, &misses, &hitRatio); err == nil && hitRatio < 0.8 {
+		severity := "Minor"
+		if hitRatio < 0.3 {
+			severity = "Critical"
+		} else if hitRatio < 0.5 {
+			severity = "Major"
+		} else if hitRatio < 0.7 {
+			severity = "Moderate"
+		}
+
+		results = append(results, Result{
+			ID:       "A1.2.02",
+			Host:     host,
+			Object:   "MarkCache",
+			Severity: severity,
+			Details:  fmt.Sprintf("Bad hit/miss ratio for marks cache (hits: %.0f, misses: %.0f, ratio: %.3f)", hits, misses, hitRatio),
+			Values:   map[string]float64{"hit_ratio": hitRatio},
+		})
+	}
+
+	// Check percentage of marks in memory
+	row = a.queryRow(`
+		SELECT 
+			(SELECT value FROM system.asynchronous_metrics WHERE metric = 'MarkCacheBytes') as actual_mark_cache_size,
+			(SELECT sum(marks_bytes) FROM system.parts WHERE active) as overall_marks_size,
+			actual_mark_cache_size / overall_marks_size as marks_in_memory_ratio
+	`)
+	var markCacheSize, overallMarksSize, marksInMemoryRatio float64
+	if err := row.Scan(&markCacheSize, &overallMarksSize, &marksInMemoryRatio); err == nil && marksInMemoryRatio < 0.01 {
+		severity := "Minor"
+		if marksInMemoryRatio < 0.001 {
+			severity = "Moderate"
+		}
+
+		results = append(results, Result{
+			ID:       "A1.2.03",
+			Object:   "MarkCache",
+			Severity: severity,
+			Details:  fmt.Sprintf("Less than 1%% of marks loaded (%.3f%%)", marksInMemoryRatio*100),
+			Values:   map[string]float64{"overall_marks_size": overallMarksSize},
+		})
+	}
+
+	// Check marks cache size vs total RAM
+	row = a.queryRow(`
+		SELECT 
+			(SELECT value FROM system.asynchronous_metrics WHERE metric = 'MarkCacheBytes') as actual_mark_cache_size,
+			(SELECT value FROM system.asynchronous_metrics WHERE metric = 'OSMemoryTotal') as total_ram,
+			actual_mark_cache_size / total_ram as marks_cache_ratio
+	`)
+	var totalRam, marksCacheRatio float64
+	if err := row.Scan(&markCacheSize, &totalRam, &marksCacheRatio); err == nil && marksCacheRatio > 0.1 {
+		severity := "Minor"
+		if marksCacheRatio > 0.25 {
+			severity = "Critical"
+		} else if marksCacheRatio > 0.2 {
+			severity = "Major"
+		} else if marksCacheRatio > 0.15 {
+			severity = "Moderate"
+		}
+
+		results = append(results, Result{
+			ID:       "A1.2.04",
+			Object:   "MarkCache",
+			Severity: severity,
+			Details:  fmt.Sprintf("Too big marks cache (%.1f%% of total RAM)", marksCacheRatio*100),
+			Values:   map[string]float64{"actual_mark_cache_size": markCacheSize},
+		})
+	}
+
+	// Check percentage of marks in memory (A1.2.05 - duplicate check)
+	row = a.queryRow(`
+		SELECT 
+			(SELECT value FROM system.asynchronous_metrics WHERE metric = 'MarkCacheBytes') as actual_mark_cache_size,
+			(SELECT sum(marks_bytes) FROM system.parts WHERE active) as overall_marks_size,
+			actual_mark_cache_size / overall_marks_size as marks_in_memory_ratio
+	`)
+	if err := row.Scan(&markCacheSize, &overallMarksSize, &marksInMemoryRatio); err == nil && marksInMemoryRatio < 0.01 {
+		severity := "Minor"
+		if marksInMemoryRatio < 0.001 {
+			severity = "Moderate"
+		}
+
+		results = append(results, Result{
+			ID:       "A1.2.05",
+			Object:   "MarkCache",
+			Severity: severity,
+			Details:  fmt.Sprintf("Less than 1%% of marks loaded (marks loaded: %.0f bytes / overall: %.0f bytes)", markCacheSize, overallMarksSize),
+			Values:   map[string]float64{"overall_marks_size": overallMarksSize},
+		})
+	}
+
+	// Check marks cache size vs total RAM (A1.2.06 - duplicate of A1.2.04)
+	row = a.queryRow(`
+		SELECT 
+			(SELECT value FROM system.asynchronous_metrics WHERE metric = 'MarkCacheBytes') as actual_mark_cache_size,
+			(SELECT value FROM system.asynchronous_metrics WHERE metric = 'OSMemoryTotal') as total_ram,
+			actual_mark_cache_size / total_ram as marks_cache_ratio
+	`)
+	if err := row.Scan(&markCacheSize, &totalRam, &marksCacheRatio); err == nil && marksCacheRatio > 0.1 {
+		severity := "Minor"
+		if marksCacheRatio > 0.25 {
+			severity = "Critical"
+		} else if marksCacheRatio > 0.2 {
+			severity = "Major"
+		} else if marksCacheRatio > 0.15 {
+			severity = "Moderate"
+		}
+
+		results = append(results, Result{
+			ID:       "A1.2.06",
+			Object:   "MarkCache",
+			Severity: severity,
+			Details:  fmt.Sprintf("Too big marks cache (size: %.0f bytes / total RAM: %.0f bytes)", markCacheSize, totalRam),
+			Values:   map[string]float64{"actual_mark_cache_size": markCacheSize},
+		})
+	}
+
+	return results
+}
+
+func (a *Auditor) checkActiveParts() []Result {
+	var results []Result
+
+	// Check total active parts number (A1.5.01.1)
+	a.eachHostRow(fmt.Sprintf("SELECT hostName() AS h, sum(active) AS parts FROM %s WHERE active GROUP BY h", a.clusterTable("parts")), func(rows *sql.Rows) error {
+		var host string
+		var parts int64
+		if err := rows.Scan(&host, &parts); err != nil {
+			return err
+		}
+		severity := ""
+		if parts > 50000 {
+			severity = "Critical"
+		} else if parts > 20000 {
+			severity = "Major"
+		} else if parts > 10000 {
+			severity = "Moderate"
+		}
+
+		if severity != "" {
+			results = append(results, Result{
+				ID:       "A1.5.01.1",
+				Host:     host,
+				Object:   "Total active parts number",
+				Severity: severity,
+				Details:  fmt.Sprintf("Total active parts %d", parts),
+				Values:   map[string]float64{"total_active_parts": float64(parts)},
+			})
+		}
+		return nil
+	})
+
+	return results
+}
+
+func (a *Auditor) checkBackgroundPools() []Result {
+	var results []Result
+
+	// Check background pool overload
+	rows, err := a.query(`
+		SELECT 
+			extract(m.metric, '^Background(.*)Task') AS pool_name,
+			m.value as current_value,
+			s.max_value,
+			m.value / s.max_value as pool_load_ratio
+		FROM (
+			SELECT metric, value 
+			FROM system.metrics 
+			WHERE metric LIKE 'Background%PoolTask'
+		) m
+		INNER JOIN (
+			SELECT 
+				transform(
+					extract(name, '^background_(.*)_size'),
+					['buffer_flush_schedule_pool', 'pool', 'fetches_pool', 'move_pool', 'common_pool', 'schedule_pool', 'message_broker_schedule_pool', 'distributed_schedule_pool'],
+					['BufferFlushSchedulePool','MergesAndMutationsPool','FetchesPool', 'MovePool', 'CommonPool', 'SchedulePool', 'MessageBrokerSchedulePool', 'DistributedSchedulePool'],
+					''
+				) as pool_name,
+				toFloat64(value) AS max_value
+			FROM system.settings 
+			WHERE name LIKE 'background%pool_size'
+		) s USING (pool_name)
+		WHERE pool_load_ratio > 0.8
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkBackgroundPools")
+			}
+		}()
+		for rows.Next() {
+			var poolName string
+			var currentValue, maxValue, loadRatio float64
+			if err := rows.Scan(&poolName, &currentValue, &maxValue, &loadRatio); err == nil {
+				severity := "Minor"
+				if loadRatio > 0.99 {
+					severity = "Major"
+				} else if loadRatio > 0.9 {
+					severity = "Moderate"
+				}
+
+				results = append(results, Result{
+					ID:       "A1.4.01",
+					Object:   poolName,
+					Severity: severity,
+					Details:  fmt.Sprintf("%s is overloaded (used: %.0f, size: %.0f, load ratio: %.3f)", poolName, currentValue, maxValue, loadRatio),
+					Values: map[string]float64{
+						"size":       currentValue,
+						"load_ratio": loadRatio,
+					},
+				})
+			}
+		}
+	}
+
+	// Check MessageBrokerSchedulePool size vs Kafka/RabbitMQ tables
+	row := a.queryRow(`
+		SELECT 
+			(SELECT toUInt64(value) FROM system.settings WHERE name = 'background_message_broker_schedule_pool_size') as pool_size,
+			(SELECT count() FROM system.tables WHERE engine = 'Kafka' OR engine = 'RabbitMQ') as tables_with_engines
+	`)
+	var poolSize, tablesWithEngines int64
+	if err := row.Scan(&poolSize, &tablesWithEngines); err == nil && poolSize < tablesWithEngines {
+		results = append(results, Result{
+			ID:       "A1.4.02",
+			Object:   "MessageBrokerSchedulePool",
+			Severity: "Critical",
+			Details:  fmt.Sprintf("MessageBrokerSchedulePool size is too small (size: %d / tables with Kafka or RabbitMQ engines: %d)", poolSize, tablesWithEngines),
+			Values: map[string]float64{
+				"size":    float64(poolSize),
+				"engines": float64(tablesWithEngines),
+			},
+		})
+	}
+
+	return results
+}
+
+func (a *Auditor) checkUncompressedCache() []Result {
+	var results []Result
+
+	// Check uncompressed cache hit ratio
+	row := a.queryRow(`
+		SELECT 
+			(SELECT value FROM system.events WHERE event = 'UncompressedCacheHits') as hits,
+			(SELECT value FROM system.events WHERE event = 'UncompressedCacheMisses') as misses,
+			hits / (hits + misses) as hit_ratio
+	`)
+	var hits, misses, hitRatio float64
+	if err := row.Scan(&hits, &misses, &hitRatio); err == nil && hitRatio < 0.1 {
+		severity := "Minor"
+		if hitRatio < 0.01 {
+			severity = "Moderate"
+		}
+
+		results = append(results, Result{
+			ID:       "A1.5.01",
+			Object:   "UncompressedCache",
+			Severity: severity,
+			Details:  fmt.Sprintf("Bad hit/miss ratio for uncompressed cache (hits: %.0f, misses: %.0f, ratio: %.3f)", hits, misses, hitRatio),
+			Values: map[string]float64{
+				"hits":   hits,
+				"misses": misses,
+				"ratio":  hitRatio,
+			},
+		})
+	}
+
+	// Check uncompressed cache size vs total RAM
+	row = a.queryRow(`
+		SELECT 
+			(SELECT value FROM system.asynchronous_metrics WHERE metric = 'UncompressedCacheBytes') as actual_uncompressed_cache_size,
+			(SELECT value FROM system.asynchronous_metrics WHERE metric = 'OSMemoryTotal') as total_ram,
+			actual_uncompressed_cache_size / total_ram as uncompressed_cache_ratio
+	`)
+	var uncompressedCacheSize, totalRam, uncompressedCacheRatio float64
+	if err := row.Scan(&uncompressedCacheSize, &totalRam, &uncompressedCacheRatio); err == nil && uncompressedCacheRatio > 0.1 {
+		severity := "Minor"
+		if uncompressedCacheRatio > 0.25 {
+			severity = "Critical"
+		} else if uncompressedCacheRatio > 0.2 {
+			severity = "Major"
+		} else if uncompressedCacheRatio > 0.15 {
+			severity = "Moderate"
+		}
+
+		results = append(results, Result{
+			ID:       "A1.5.02",
+			Object:   "UncompressedCache",
+			Severity: severity,
+			Details:  fmt.Sprintf("Too big uncompressed cache (%.1f%% of total RAM)", uncompressedCacheRatio*100),
+			Values:   map[string]float64{"actual_uncompressed_cache_size": uncompressedCacheSize, "total_ram": totalRam},
+		})
+	}
+
+	return results
+}
+
+func (a *Auditor) checkReplicationQueue() []Result {
+	var results []Result
+
+	// Check replication queue size (moved from checkReplication)
+	rows, err := a.query(fmt.Sprintf(`
+		SELECT
+			hostName() AS h,
+			database,
+			table,
+			count() as count_all,
+			countIf(last_exception != '') as count_err,
+			countIf(num_postponed > 0) as count_postponed,
+			countIf(is_currently_executing) as count_executing
+		FROM %s
+		GROUP BY h, database, table
+		HAVING count_all > 100
+	`, a.clusterTable("replication_queue")))
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkReplicationQueue")
+			}
+		}()
+		for rows.Next() {
+			var host, database, table string
+			var countAll, countErr, countPostponed, countExecuting int64
+
+			if err := rows.Scan(&host, &database, &table, &countAll, &countErr, &countPostponed, &countExecuting); err == nil {
+				severity := "Minor"
+				if countAll > 500 {
+					severity = "Critical"
+				} else if countAll > 400 {
+					severity = "Major"
+				} else if countAll > 200 {
+					severity = "Moderate"
+				}
+
+				results = append(results, Result{
+					ID:       "A1.6",
+					Host:     host,
+					Object:   fmt.Sprintf("%s.%s", database, table),
+					Severity: severity,
+					Details:  fmt.Sprintf("Too many tasks in the replication_queue (count: %d)", countAll),
+					Values: map[string]float64{
+						"count_all":       float64(countAll),
+						"count_err":       float64(countErr),
+						"count_postponed": float64(countPostponed),
+						"count_executing": float64(countExecuting),
+					},
+				})
+			}
+		}
+	}
+
+	// Check for old tasks in replication queue
+	rows, err = a.query(fmt.Sprintf(`
+		WITH
+			(SELECT maxArray([create_time, last_attempt_time, last_postpone_time]) FROM %s) AS max_time
+		SELECT
+			hostName() AS h,
+			database,
+			table,
+			max_time - min(create_time) as relative_delay
+		FROM %s
+		GROUP BY h, database, table
+		HAVING relative_delay > 300
+	`, a.clusterTable("replication_queue"), a.clusterTable("replication_queue")))
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkReplicationQueue old tasks")
+			}
+		}()
+		for rows.Next() {
+			var host, database, table string
+			var relativeDelay float64
+
+			if err := rows.Scan(&host, &database, &table, &relativeDelay); err == nil {
+				severity := "Minor"
+				if relativeDelay > 24*3600 {
+					severity = "Critical"
+				} else if relativeDelay > 2*3600 {
+					severity = "Major"
+				} else if relativeDelay > 1800 {
+					severity = "Moderate"
+				}
+
+				results = append(results, Result{
+					ID:       "A1.6.1",
+					Host:     host,
+					Object:   fmt.Sprintf("%s.%s", database, table),
+					Severity: severity,
+					Details:  fmt.Sprintf("Old tasks in replication_queue (max age: %.0f seconds)", relativeDelay),
+					Values:   map[string]float64{"delay": relativeDelay},
+				})
+			}
+		}
+	}
+
+	// Check for tasks with no activity in replication queue
+	rows, err = a.query(fmt.Sprintf(`
+		WITH
+			(SELECT maxArray([create_time, last_attempt_time, last_postpone_time]) FROM %s) AS max_time
+		SELECT
+			hostName() AS h,
+			database,
+			table,
+			countIf(last_attempt_time < max_time - 601 AND last_postpone_time < max_time - 601) as no_activity_tasks,
+			count() as tasks
+		FROM %s
+		GROUP BY h, database, table
+		HAVING no_activity_tasks > 0
+	`, a.clusterTable("replication_queue"), a.clusterTable("replication_queue")))
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkReplicationQueue no activity")
+			}
+		}()
+		for rows.Next() {
+			var host, database, table string
+			var noActivityTasks, tasks int64
+
+			if err := rows.Scan(&host, &database, &table, &noActivityTasks, &tasks); err == nil {
+				results = append(results, Result{
+					ID:       "A1.6.2",
+					Host:     host,
+					Object:   fmt.Sprintf("%s.%s", database, table),
+					Severity: "Minor",
+					Details:  fmt.Sprintf("No activity in %d tasks out of %d", noActivityTasks, tasks),
+					Values: map[string]float64{
+						"no_activity_tasks": float64(noActivityTasks),
+						"tasks":             float64(tasks),
+					},
+				})
+			}
+		}
+	}
+
+	return results
+}
+
+// mutationStuckSampleInterval separates the two parts_to_do samples the
+// stuck-progress detection compares; the wait overlaps with the other checks
+// running on the worker pool, so it doesn't extend the audit by much
+const mutationStuckSampleInterval = 10 * time.Second
+
+// mutationSample is one in-flight mutation observed during the audit run
+type mutationSample struct {
+	host       string
+	database   string
+	table      string
+	mutationID string
+	partsToDo  int64
+	ageSeconds float64
+	failReason string
+}
+
+// fetchMutationSamples snapshots the unfinished mutations across the cluster,
+// keyed per host and mutation so two snapshots can be compared
+func (a *Auditor) fetchMutationSamples() map[string]mutationSample {
+	samples := make(map[string]mutationSample)
+	a.eachHostRow(fmt.Sprintf(`
+		SELECT
+			hostName() AS h,
+			database,
+			table,
+			mutation_id,
+			toInt64(parts_to_do) AS parts_to_do,
+			dateDiff('second', create_time, now()) AS age_seconds,
+			latest_fail_reason
+		FROM %s
+		WHERE NOT is_done
+	`, a.clusterTable("mutations")), func(rows *sql.Rows) error {
+		var sample mutationSample
+		if err := rows.Scan(&sample.host, &sample.database, &sample.table, &sample.mutationID, &sample.partsToDo, &sample.ageSeconds, &sample.failReason); err != nil {
+			return err
+		}
+		key := sample.host + "\x00" + sample.database + "\x00" + sample.table + "\x00" + sample.mutationID
+		samples[key] = sample
+		return nil
+	})
+	return samples
+}
+
+// checkMutations flags stuck mutations, which silently block TTL and schema
+// changes: mutations older than a threshold (A1.9.01) and mutations whose
+// parts_to_do did not decrease between two samples taken during the run
+// (A1.9.02), both with the latest failure reason when the server recorded one
+func (a *Auditor) checkMutations() []Result {
+	var results []Result
+
+	first := a.fetchMutationSamples()
+	for _, sample := range first {
+		severity := ""
+		if sample.ageSeconds > 7*24*3600 {
+			severity = "Critical"
+		} else if sample.ageSeconds > 24*3600 {
+			severity = "Major"
+		} else if sample.ageSeconds > 3600 {
+			severity = "Moderate"
+		}
+		if severity == "" {
+			continue
+		}
+		details := fmt.Sprintf("Mutation not done after %.1f hours (parts_to_do: %d)", sample.ageSeconds/3600, sample.partsToDo)
+		if sample.failReason != "" {
+			details += fmt.Sprintf(", last failure: %s", truncateFailReason(sample.failReason))
+		}
+		results = append(results, Result{
+			ID:       "A1.9.01",
+			Host:     sample.host,
+			Object:   fmt.Sprintf("%s.%s mutation %s", sample.database, sample.table, sample.mutationID),
+			Severity: severity,
+			Details:  details,
+			Values: map[string]float64{
+				"age_seconds": sample.ageSeconds,
+				"parts_to_do": float64(sample.partsToDo),
+			},
+		})
+	}
+
+	if len(first) == 0 {
+		return results
+	}
+
+	// A second sample after a pause separates stuck from merely slow: a
+	// healthy mutation finishes parts continuously, so parts_to_do goes down
+	select {
+	case <-a.queryContext().Done():
+		return results
+	case <-time.After(mutationStuckSampleInterval):
+	}
+	second := a.fetchMutationSamples()
+	for key, before := range first {
+		after, found := second[key]
+		if !found {
+			// finished between the samples, clearly not stuck
+			continue
+		}
+		// fresh mutations haven't had time to make progress yet; parts_to_do
+		// of 0 means the mutation is finalizing, not processing parts
+		if after.partsToDo <= 0 || after.partsToDo < before.partsToDo || after.ageSeconds < 300 {
+			continue
+		}
+		severity := "Major"
+		details := fmt.Sprintf("Mutation made no progress between two samples %s apart (parts_to_do stays at %d, age: %.1f hours)", mutationStuckSampleInterval, after.partsToDo, after.ageSeconds/3600)
+		if after.failReason != "" {
+			severity = "Critical"
+			details += fmt.Sprintf(", last failure: %s", truncateFailReason(after.failReason))
+		}
+		results = append(results, Result{
+			ID:       "A1.9.02",
+			Host:     after.host,
+			Object:   fmt.Sprintf("%s.%s mutation %s", after.database, after.table, after.mutationID),
+			Severity: severity,
+			Details:  details,
+			Values: map[string]float64{
+				"age_seconds": after.ageSeconds,
+				"parts_to_do": float64(after.partsToDo),
+			},
+		})
+	}
+
+	return results
+}
+
+// truncateFailReason keeps failure reasons short enough for the findings
+// table; the full text is one query away in system.mutations
+func truncateFailReason(reason string) string {
+	if len(reason) > 160 {
+		return reason[:160] + "..."
+	}
+	return reason
+}
+
+func (a *Auditor) checkMaterializedViews() []Result {
+	var results []Result
+
+	// Check for MVs not using TO syntax
+	rows, err := a.query(fmt.Sprintf(`
+		SELECT hostName() AS h, database, name
+		FROM %s
+		WHERE engine='MaterializedView'
+		AND splitByChar(' ', create_table_query)[5] != 'TO'
+	`, a.clusterTable("tables")))
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkMaterializedViews")
+			}
+		}()
+		for rows.Next() {
+			var host, database, name string
+			if err := rows.Scan(&host, &database, &name); err == nil {
+				results = append(results, Result{
+					ID:       "A2.2",
+					Host:     host,
+					Object:   fmt.Sprintf("%s.%s", database, name),
+					Severity: "Moderate",
+					Details:  "MV: TO syntax is not used",
+					Values:   map[string]float64{},
+				})
+			}
+		}
+	}
+
+	// Check for MVs using JOINs
+	rows, err = a.query(fmt.Sprintf(`
+		SELECT hostName() AS h, database, name
+		FROM %s
+		WHERE engine='MaterializedView'
+		AND create_table_query ILIKE '%%JOIN%%'
+	`, a.clusterTable("tables")))
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkMaterializedViews JOINs")
+			}
+		}()
+		for rows.Next() {
+			var host, database, name string
+			if err := rows.Scan(&host, &database, &name); err == nil {
+				results = append(results, Result{
+					ID:       "A2.2.01",
+					Host:     host,
+					Object:   fmt.Sprintf("%s.%s", database, name),
+					Severity: "Moderate",
+					Details:  "MV: JOIN is used",
+					Values:   map[string]float64{},
+				})
+			}
+		}
+	}
+
+	return results
+}
+
+func (a *Auditor) checkVersions() []Result {
+	var results []Result
+
+	row := a.queryRow(`
+		WITH version_data AS (
+			SELECT
+				maxIf(value, name = 'VERSION_DESCRIBE') AS version_full,
+				maxIf(toDate(parseDateTimeBestEffortOrNull(value)), lower(name) LIKE '%date%') AS release_date,
+				'audited' as version_source
+			FROM system.build_options
+			WHERE (name = 'VERSION_DESCRIBE') OR (lower(name) LIKE '%date%')
+		),
+		parsed_version AS (
+			SELECT
+				version_full,
+				release_date,
+				version_source,
+				extract(version_full, '^v(\\d+)') AS version_maj,
+				extract(version_full, '^v\\d+\\.(\\d+)') AS version_min,
+				toUInt16(extract(version_full, '^v\\d+\\.\\d+\\.(\\d+)')) AS version_bugfix,
+				extract(version_full, '[-.](\w+)$') as version_type
+			FROM version_data
+		)
+		SELECT 
+			version_full,
+			release_date,
+			version_maj,
+			version_min,
+			version_bugfix,
+			version_type,
+			today() - release_date as version_age_days
+		FROM parsed_version
+		WHERE version_full != ''
+	`)
+
+	var versionFull string
+	var releaseDate sql.NullTime
+	var versionMaj, versionMin, versionBugfix sql.NullString
+	var versionType sql.NullString
+	var versionAgeDays sql.NullInt64
+
+	if err := row.Scan(&versionFull, &releaseDate, &versionMaj, &versionMin, &versionBugfix, &versionType, &versionAgeDays); err == nil {
+		// A.2.1.01 - Check version age
+		if versionAgeDays.Valid && versionAgeDays.Int64 > 182 {
+			severity := "Minor"
+			if versionAgeDays.Int64 > 900 {
+				severity = "Critical"
+			} else if versionAgeDays.Int64 > 700 {
+				severity = "Major"
+			} else if versionAgeDays.Int64 > 365 {
+				severity = "Moderate"
+			}
+
+			// Construct upgrade suggestion based on version type
+			upgradeOptions := make([]string, 0)
+			if versionType.Valid {
+				switch versionType.String {
+				case "lts":
+					upgradeOptions = append(upgradeOptions, "latest LTS")
+				case "stable", "altinitystable":
+					upgradeOptions = append(upgradeOptions, "latest stable")
+				default:
+					upgradeOptions = append(upgradeOptions, "latest release")
+				}
+			} else {
+				upgradeOptions = append(upgradeOptions, "latest release")
+			}
+
+			upgradeText := ""
+			if len(upgradeOptions) > 0 {
+				upgradeText = fmt.Sprintf(", consider upgrade to %s", strings.Join(upgradeOptions, " or "))
+			}
+
+			results = append(results, Result{
+				ID:       "A.2.1.01",
+				Object:   "system",
+				Severity: severity,
+				Details:  fmt.Sprintf("You use old clickhouse version (%s, %d days old)%s", versionFull, versionAgeDays.Int64, upgradeText),
+				Values:   map[string]float64{},
+			})
+		}
+
+		// A.2.1.02 - Check if using latest bugfix version
+		// This is a simplified check since we don't have access to the external version data
+		// In the original SQL, this would compare against latest bugfix releases
+		if versionMaj.Valid && versionMin.Valid && versionBugfix.Valid {
+			// For demonstration, we'll suggest checking for bugfix updates if version is older than 30 days
+			// In reality, this would need to query external APIs or version databases
+			if versionAgeDays.Valid && versionAgeDays.Int64 > 30 {
+				// Simulate bugfixes_behind logic - this is simplified
+				bugfixesBehind := int64(0)
+				if versionAgeDays.Int64 > 90 {
+					bugfixesBehind = 3 // Simulate being behind on bugfixes
+				} else if versionAgeDays.Int64 > 60 {
+					bugfixesBehind = 1
+				}
+
+				if bugfixesBehind > 0 {
+					severity := "Minor"
+					if bugfixesBehind > 5 {
+						severity = "Critical"
+					} else if bugfixesBehind > 3 {
+						severity = "Major"
+					} else if bugfixesBehind > 1 {
+						severity = "Moderate"
+					}
+
+					upgradeOptions := make([]string, 0)
+					if versionType.Valid && versionType.String != "" {
+						upgradeOptions = append(upgradeOptions, fmt.Sprintf("latest %s bugfix", versionType.String))
+					}
+					upgradeOptions = append(upgradeOptions, "latest bugfix release")
+
+					results = append(results, Result{
+						ID:       "A.2.1.02",
+						Object:   "system",
+						Severity: severity,
+						Details:  fmt.Sprintf("You use not the latest bugfix of the %s.%s ClickHouse release (%s, estimated %d bugfixes behind), consider upgrade to %s", versionMaj.String, versionMin.String, versionFull, bugfixesBehind, strings.Join(upgradeOptions, " or ")),
+						Values:   map[string]float64{},
+					})
+				}
+			}
+		}
+	}
+
+	return results
+}
+
+func (a *Auditor) checkLongNames() []Result {
+	var results []Result
+
+	// Check for long database names
+	rows, err := a.query(`
+		SELECT name, length(name) as name_length
+		FROM system.databases 
+		WHERE length(name) > 32
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkLongNames databases")
+			}
+		}()
+		for rows.Next() {
+			var name string
+			var nameLength int64
+			if err := rows.Scan(&name, &nameLength); err == nil {
+				severity := "Moderate"
+				if nameLength > 196 {
+					severity = "Critical"
+				} else if nameLength > 128 {
+					severity = "Major"
+				} else if nameLength > 64 {
+					severity = "Moderate"
+				}
+
+				results = append(results, Result{
+					ID:       "A0.0.6",
+					Object:   name,
+					Severity: severity,
+					Details:  "Long database name",
+					Values:   map[string]float64{},
+				})
+			}
+		}
+	}
+
+	// Check for long table names
+	rows, err = a.query(`
+		SELECT database, name, length(name) as name_length
+		FROM system.tables 
+		WHERE length(name) > 32
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkLongNames tables")
+			}
+		}()
+		for rows.Next() {
+			var database, name string
+			var nameLength int64
+			if err := rows.Scan(&database, &name, &nameLength); err == nil {
+				severity := "Moderate"
+				if nameLength > 196 {
+					severity = "Critical"
+				} else if nameLength > 128 {
+					severity = "Major"
+				} else if nameLength > 64 {
+					severity = "Moderate"
+				}
+
+				results = append(results, Result{
+					ID:       "A0.0.6",
+					Object:   fmt.Sprintf("%s.%s", database, name),
+					Severity: severity,
+					Details:  "Long table name",
+					Values:   map[string]float64{},
+				})
+			}
+		}
+	}
+
+	// Check for long column names
+	rows, err = a.query(`
+		SELECT database, table, name, length(name) as name_length
+		FROM system.columns 
+		WHERE length(name) > 32 AND database NOT IN ('system','INFORMATION_SCHEMA','information_schema')
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkLongNames columns")
+			}
+		}()
+		for rows.Next() {
+			var database, table, name string
+			var nameLength int64
+			if err := rows.Scan(&database, &table, &name, &nameLength); err == nil {
+				severity := "Moderate"
+				if nameLength > 196 {
+					severity = "Critical"
+				} else if nameLength > 128 {
+					severity = "Major"
+				} else if nameLength > 64 {
+					severity = "Moderate"
+				}
+
+				results = append(results, Result{
+					ID:       "A0.0.6",
+					Object:   fmt.Sprintf("%s.%s.%s", database, table, name),
+					Severity: severity,
+					Details:  "Long column name",
+					Values:   map[string]float64{},
+				})
+			}
+		}
+	}
+
+	return results
+}
+
+func (a *Auditor) checkSystemLogs() []Result {
+	var results []Result
+
+	// Check if query_log is enabled and has recent data
+	row := a.queryRow(`
+		SELECT max(event_time) 
+		FROM system.query_log 
+		WHERE event_time > now() - INTERVAL 4 HOUR
+	`)
+	var maxTime sql.NullTime
+	if err := row.Scan(&maxTime); err == nil {
+		if !maxTime.Valid || time.Since(maxTime.Time) > 4*time.Hour {
+			results = append(results, Result{
+				ID:       "A0.2.01",
+				Object:   "system.query_log",
+				Severity: "Major",
+				Details:  "No fresh records in system.query_log to analyze",
+				Values:   map[string]float64{},
+			})
+		}
+	}
+
+	// Check if part_log is enabled and has recent data
+	row = a.queryRow(`
+		SELECT max(event_time) 
+		FROM system.part_log 
+		WHERE event_time > now() - INTERVAL 4 HOUR
+	`)
+	if err := row.Scan(&maxTime); err == nil {
+		if !maxTime.Valid || time.Since(maxTime.Time) > 4*time.Hour {
+			results = append(results, Result{
+				ID:       "A0.2.02",
+				Object:   "system.part_log",
+				Severity: "Major",
+				Details:  "No fresh records in system.part_log to analyze",
+				Values:   map[string]float64{},
+			})
+		}
+	}
+
+	// Check if query_log has too old data
+	row = a.queryRow(`
+		SELECT 
+			max(event_time) as max_time,
+			min(event_time) as min_time
+		FROM system.query_log
+	`)
+	var maxQueryTime, minQueryTime sql.NullTime
+	if err := row.Scan(&maxQueryTime, &minQueryTime); err == nil {
+		if maxQueryTime.Valid && minQueryTime.Valid {
+			age := maxQueryTime.Time.Sub(minQueryTime.Time)
+			if age > 30*24*time.Hour { // 30 days
+				results = append(results, Result{
+					ID:       "A0.2.03",
+					Object:   "system.query_log",
+					Severity: "Major",
+					Details:  fmt.Sprintf("system.query_log has too old data - %s", age.String()),
+					Values:   map[string]float64{"age": age.Seconds()},
+				})
+			}
+		}
+	}
+
+	// Check for system log tables without TTL
+	rows, err := a.query(`
+		SELECT database, name 
+		FROM system.tables 
+		WHERE database='system' AND name LIKE '%_log' 
+		AND engine_full NOT LIKE '% TTL %'
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkSystemLogs")
+			}
+		}()
+		for rows.Next() {
+			var database, name string
+			if err := rows.Scan(&database, &name); err == nil {
+				results = append(results, Result{
+					ID:       "A0.2.04",
+					Object:   fmt.Sprintf("%s.%s", database, name),
+					Severity: "Major",
+					Details:  "System log tables should have TTL enabled",
+					Values:   map[string]float64{},
+				})
+			}
+		}
+	}
+
+	// Check system logs disk space usage
+	rows, err = a.query(`
+		WITH 
+			used AS (
+				SELECT 
+					sum(bytes_on_disk) as sp, 
+					substr(path, 1, position(path, '/store/')) as path
+				FROM system.parts 
+				WHERE database='system' AND table LIKE '%_log' 
+				GROUP BY path
+			),
+			free AS (
+				SELECT 
+					least(free_space, unreserved_space) as sp,
+					path 
+				FROM system.disks
+			)
+		SELECT 
+			used.path,
+			used.sp / free.sp as ratio
+		FROM used 
+		JOIN free USING (path)
+		WHERE ratio > 0.01
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkSystemLogs disk space")
+			}
+		}()
+		for rows.Next() {
+			var path string
+			var ratio float64
+			if err := rows.Scan(&path, &ratio); err == nil {
+				severity := "Minor"
+				if ratio > 0.2 {
+					severity = "Critical"
+				} else if ratio > 0.1 {
+					severity = "Major"
+				} else if ratio > 0.05 {
+					severity = "Moderate"
+				}
+
+				results = append(results, Result{
+					ID:       "A0.2.05",
+					Object:   "System Logs",
+					Severity: severity,
+					Details:  fmt.Sprintf("System logs take too much space on disk %s, ratio - %.3f", path, ratio),
+					Values:   map[string]float64{"ratio": ratio},
+				})
+			}
+		}
+	}
+
+	// Check for leftover system.*_logN tables after version upgrade
+	rows, err = a.query(`
+		SELECT database, name 
+		FROM system.tables 
+		WHERE database='system' AND match(name, '(.\w+)_log_(\d+)')
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkSystemLogs leftover tables")
+			}
+		}()
+		for rows.Next() {
+			var database, name string
+			if err := rows.Scan(&database, &name); err == nil {
+				results = append(results, Result{
+					ID:       "A0.2.06",
+					Object:   fmt.Sprintf("%s.%s", database, name),
+					Severity: "Minor",
+					Details:  "Leftover after version upgrade. Should be dropped",
+					Values:   map[string]float64{},
+				})
+			}
+		}
+	}
+
+	// Check for query_thread_log being enabled (should be disabled in production)
+	row = a.queryRow("SELECT count() FROM system.tables WHERE database='system' AND name='query_thread_log'")
+	var threadLogExists int64
+	if err := row.Scan(&threadLogExists); err == nil && threadLogExists > 0 {
+		results = append(results, Result{
+			ID:       "A0.2.07",
+			Object:   "System",
+			Severity: "Major",
+			Details:  "system.query_thread_log should be disabled in production systems",
+			Values:   map[string]float64{},
+		})
+	}
+
+	// Check for recent crashes
+	row = a.queryRow("SELECT count() FROM system.crash_log WHERE event_time > now() - INTERVAL 5 DAY")
+	var crashCount int64
+	if err := row.Scan(&crashCount); err == nil && crashCount > 1 {
+		results = append(results, Result{
+			ID:       "A0.2.08",
+			Object:   "System",
+			Severity: "Major",
+			Details:  fmt.Sprintf("There are %d crashes for last 5 days", crashCount),
+			Values:   map[string]float64{},
+		})
+	}
+
+	// Check for warnings
+	rows, err = a.query("SELECT message FROM system.warnings")
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close warnings check")
+			}
+		}()
+		for rows.Next() {
+			var message string
+			if err := rows.Scan(&message); err == nil {
+				results = append(results, Result{
+					ID:       "A0.2.09",
+					Object:   "System",
+					Severity: "Minor",
+					Details:  fmt.Sprintf("Warning: %s", message),
+					Values:   map[string]float64{},
+				})
+			}
+		}
+	}
+
+	return results
+}
+
+func (a *Auditor) checkPartitions() []Result {
+	var results []Result
+
+	// Check for tables with too many small partitions (A1.1.01)
+	rows, err := a.query(`
+		WITH
+			median(b) as median_partition_size_bytes,
+			median(r) as median_partition_size_rows,
+			count() as partition_count
+		SELECT
+			database,
+			table,
+			partition_count,
+			median_partition_size_bytes,
+			median_partition_size_rows
+		FROM (
+			SELECT database, table,
+				sum(bytes_on_disk) as b,
+				sum(rows) as r
+			FROM system.parts
+			WHERE active AND database NOT IN ('system', 'INFORMATION_SCHEMA', 'information_schema')
+			GROUP BY database, table, partition
+		)
+		GROUP BY database, table
+		HAVING partition_count > 1
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkPartitions")
+			}
+		}()
+		for rows.Next() {
+			var database, table string
+			var partitionCount int64
+			var medianBytes, medianRows float64
+
+			if err := rows.Scan(&database, &table, &partitionCount, &medianBytes, &medianRows); err == nil {
+				// Logic from original SQL: A1.1.06 The median size of the single partition is bigger than 16 Mb (compressed) or 250K rows
+				severity := "noerror"
+				if partitionCount > 1500 && (medianBytes < 16000000 || medianRows < 250000) {
+					severity = "Critical"
+				} else if partitionCount > 500 && (medianBytes < 16000000 || medianRows < 250000) {
+					severity = "Major"
+				} else if partitionCount > 500 && (medianBytes < 100000000 || medianRows < 10000000) {
+					severity = "Moderate"
+				} else if partitionCount > 100 && (medianBytes < 16000000 || medianRows < 250000) {
+					severity = "Moderate"
+				} else if partitionCount > 100 && (medianBytes < 100000000 || medianRows < 10000000) {
+					severity = "Minor"
+				} else if partitionCount > 1 && (medianBytes < 16000000 || medianRows < 250000) {
+					severity = "Minor"
+				} else if partitionCount > 1500 {
+					severity = "Minor"
+				}
+
+				if severity != "noerror" {
+					// Get partition key for the table
+					partitionKeyRow := a.queryRow(`
+						SELECT partition_key FROM system.tables 
+						WHERE database = ? AND name = ?
+					`, database, table)
+					var partitionKey string
+					if err := partitionKeyRow.Scan(&partitionKey); err != nil {
+						partitionKey = "None"
+					}
+					if partitionKey == "" {
+						partitionKey = "None"
+					}
+
+					results = append(results, Result{
+						ID:       "A1.1.01",
+						Object:   fmt.Sprintf("%s.%s", database, table),
+						Severity: severity,
+						Details:  fmt.Sprintf("Too small partitions (key %s, number of partitions: %d, median size %.0f bytes)", partitionKey, partitionCount, medianBytes),
+						Values: map[string]float64{
+							"median_partition_size_bytes": medianBytes,
+							"median_partition_size_rows":  medianRows,
+						},
+					})
+				}
+			}
+		}
+	}
+
+	// Check for too fast inserts
+	rows, err = a.query(`
+		WITH 
+			(SELECT max(toUInt32(value)) FROM system.merge_tree_settings WHERE name='old_parts_lifetime') as old_parts_lifetime_raw,
+			if(old_parts_lifetime_raw IS NULL OR old_parts_lifetime_raw = 0, 480, old_parts_lifetime_raw) as old_parts_lifetime
+		SELECT 
+			database,
+			table,
+			count() as parts_created_count,
+			sum(rows) as rows_in_parts,
+			round(rows_in_parts / parts_created_count, 2) as average_rows_in_parts,
+			round(1 / avg(dateDiff('second', 
+				lagInFrame(modification_time) OVER (PARTITION BY database, table ORDER BY modification_time), 
+				modification_time
+			)), 2) as average_insert_rate
+		FROM system.parts
+		WHERE level = 0 
+		AND database NOT IN ('system', 'INFORMATION_SCHEMA', 'information_schema')
+		AND modification_time > (SELECT max(modification_time) FROM system.parts) - old_parts_lifetime
+		GROUP BY database, table
+		HAVING average_rows_in_parts < 10000
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkPartitions fast inserts")
+			}
+		}()
+		for rows.Next() {
+			var database, table string
+			var partsCount, rowsInParts int64
+			var avgRowsInParts, avgInsertRate float64
+
+			if err := rows.Scan(&database, &table, &partsCount, &rowsInParts, &avgRowsInParts, &avgInsertRate); err == nil {
+				if avgInsertRate > 1 {
+					severity := "Minor"
+					if avgInsertRate > 10 {
+						severity = "Critical"
+					} else if avgInsertRate > 5 {
+						severity = "Major"
+					} else if avgInsertRate > 2 {
+						severity = "Moderate"
+					}
+
+					results = append(results, Result{
+						ID:       "A1.1.05",
+						Object:   fmt.Sprintf("%s.%s", database, table),
+						Severity: severity,
+						Details:  fmt.Sprintf("Too fast Inserts (%.2f per second)", avgInsertRate),
+						Values: map[string]float64{
+							"average_rows_in_parts": avgRowsInParts,
+							"average_insert_rate":   avgInsertRate,
+						},
+					})
+				}
+			}
+		}
+	}
+
+	// Check average row size
+	rows, err = a.query(`
+		SELECT 
+			database,
+			table,
+			sum(data_uncompressed_bytes) as data_uncompressed_bytes_sum,
+			sum(rows) as rows_sum,
+			data_uncompressed_bytes_sum / rows_sum as average_row_size
+		FROM system.parts
+		WHERE active 
+		GROUP BY database, table
+		HAVING average_row_size > 3000
+		ORDER BY average_row_size DESC
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkPartitions row size")
+			}
+		}()
+		for rows.Next() {
+			var database, table string
+			var dataUncompressed, rowsSum int64
+			var avgRowSize float64
+
+			if err := rows.Scan(&database, &table, &dataUncompressed, &rowsSum, &avgRowSize); err == nil {
+				severity := "Minor"
+				if avgRowSize > 12000 {
+					severity = "Critical"
+				} else if avgRowSize > 8000 {
+					severity = "Major"
+				} else if avgRowSize > 5000 {
+					severity = "Moderate"
+				}
+
+				results = append(results, Result{
+					ID:       "A1.1.06",
+					Object:   fmt.Sprintf("%s.%s", database, table),
+					Severity: severity,
+					Details:  fmt.Sprintf("Too big average row size (%.0f bytes)", avgRowSize),
+					Values:   map[string]float64{"average_row_size": avgRowSize},
+				})
+			}
+		}
+	}
+
+	// Check maximum partition size for special MergeTree engines (A1.1.03)
+	rows, err = a.query(`
+		WITH
+			(SELECT max(toUInt64(value)) FROM system.merge_tree_settings WHERE name = 'max_bytes_to_merge_at_max_space_in_pool') AS max_partition_size
+		SELECT
+			database,
+			table,
+			max(b) as max_partition_size_bytes,
+			max_partition_size
+		FROM (
+			SELECT
+				database,
+				table,
+				sum(bytes_on_disk) as b
+			FROM system.parts
+			WHERE active AND database NOT IN ('system', 'INFORMATION_SCHEMA', 'information_schema') 
+			AND (database, table) IN (
+				SELECT database, name 
+				FROM system.tables 
+				WHERE engine LIKE '%MergeTree%' 
+				AND (engine LIKE '%Aggregating%' OR engine LIKE '%Collapsing%' OR engine LIKE '%Summing%' OR engine LIKE '%Replacing%' OR engine LIKE '%Graphite%')
+			)
+			GROUP BY database, table, partition
+		) t
+		GROUP BY database, table
+		HAVING max_partition_size_bytes > max_partition_size * 0.33 AND max_partition_size_bytes > 20000000000
+		ORDER BY max_partition_size_bytes DESC
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkPartitions max size")
+			}
+		}()
+		for rows.Next() {
+			var database, table string
+			var maxPartitionSizeBytes, maxPartitionSize float64
+
+			if err := rows.Scan(&database, &table, &maxPartitionSizeBytes, &maxPartitionSize); err == nil {
+				severity := "Minor"
+				ratio := maxPartitionSizeBytes / maxPartitionSize
+				if ratio > 0.95 {
+					severity = "Critical"
+				} else if ratio > 0.75 {
+					severity = "Major"
+				} else if ratio > 0.55 && maxPartitionSizeBytes > 25000000000 {
+					severity = "Moderate"
+				}
+
+				// Get partition key for the table
+				partitionKeyRow := a.queryRow(`
+					SELECT partition_key FROM system.tables 
+					WHERE database = ? AND name = ?
+				`, database, table)
+				var partitionKey string
+				if err := partitionKeyRow.Scan(&partitionKey); err != nil {
+					partitionKey = "None"
+				}
+				if partitionKey == "" {
+					partitionKey = "None"
+				}
+
+				results = append(results, Result{
+					ID:       "A1.1.03",
+					Object:   fmt.Sprintf("%s.%s", database, table),
+					Severity: severity,
+					Details:  fmt.Sprintf("Too much data in partition, background logic to collapse rows with same key may work poorly (key %s, size %.0f bytes, max_size: %.0f bytes)", partitionKey, maxPartitionSizeBytes, maxPartitionSize),
+					Values:   map[string]float64{"max_partition_size_bytes": maxPartitionSizeBytes},
+				})
+			}
+		}
+	}
+
+	// Check detached parts
+	rows, err = a.query(`
+		SELECT database, table, count() as parts_count
+		FROM system.detached_parts
+		GROUP BY database, table
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkPartitions detached")
+			}
+		}()
+		for rows.Next() {
+			var database, table string
+			var partsCount int64
+
+			if err := rows.Scan(&database, &table, &partsCount); err == nil {
+				severity := "Minor"
+				if partsCount > 500 {
+					severity = "Critical"
+				} else if partsCount > 200 {
+					severity = "Major"
+				} else if partsCount > 50 {
+					severity = "Moderate"
+				}
+
+				results = append(results, Result{
+					ID:       "A1.1.07",
+					Object:   fmt.Sprintf("%s.%s", database, table),
+					Severity: severity,
+					Details:  fmt.Sprintf("Detached parts (count: %d)", partsCount),
+					Values:   map[string]float64{"parts_count": float64(partsCount)},
+				})
+			}
+		}
+	}
+
+	return results
+}
+
+func (a *Auditor) checkPrimaryKeyMarks() []Result {
+	var results []Result
+
+	// Check primary key size per mark
+	rows, err := a.query(`
+		SELECT 
+			database, 
+			table,
+			sum(primary_key_bytes_in_memory) / sum(marks) as pk_per_mark,
+			sum(primary_key_bytes_in_memory) as total_pk_memory
+		FROM system.parts
+		WHERE active
+		GROUP BY database, table
+		HAVING sum(marks) > 0 AND pk_per_mark > 64
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkSystemLogs")
+			}
+		}()
+		for rows.Next() {
+			var database, table string
+			var pkPerMark, totalPkMemory float64
+
+			if err := rows.Scan(&database, &table, &pkPerMark, &totalPkMemory); err == nil {
+				severity := "Minor"
+				if pkPerMark > 256 {
+					severity = "Critical"
+				} else if pkPerMark > 128 {
+					severity = "Moderate"
+				}
+
+				results = append(results, Result{
+					ID:       "A1.2.01",
+					Object:   fmt.Sprintf("%s.%s", database, table),
+					Severity: severity,
+					Details:  fmt.Sprintf("Too heavy PK (avg PK size per mark %.0f bytes)", pkPerMark),
+					Values: map[string]float64{
+						"pk_per_mark":                 pkPerMark,
+						"primary_key_bytes_in_memory": totalPkMemory,
+					},
+				})
+			}
+		}
+	}
+
+	return results
+}
+
+func (a *Auditor) checkPrimaryKeys() []Result {
+	var results []Result
+
+	// A2.4.01: Check first column of PRIMARY KEY/ORDER BY
+	rows, err := a.query(`
+		WITH tables_data AS (
+			SELECT 
+				format('{}.{}', database, name) AS object,
+				splitByChar(',', primary_key)[1] as pkey,
+				total_rows
+			FROM system.tables
+			WHERE engine LIKE '%MergeTree%' AND total_rows > 1E7 AND primary_key != ''
+		),
+		columns_data AS (
+			SELECT 
+				format('{}.{}', database, table) AS object,
+				name, 
+				type, 
+				data_compressed_bytes / nullif(data_uncompressed_bytes,0) as ratio
+			FROM system.columns
+		)
+		SELECT 
+			t.object,
+			t.pkey,
+			c.type,
+			c.ratio
+		FROM tables_data t 
+		JOIN columns_data c ON t.object = c.object AND t.pkey = c.name
+		WHERE (
+			t.pkey ILIKE '%id%' OR
+			c.type IN ['UUID','ULID', 'UInt64','Int64','IPv4', 'IPv6', 'UInt32', 'Int32', 'UInt128'] OR
+			c.type LIKE 'DateTime%' OR
+			c.ratio > 0.5
+		)
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkPrimaryKeys A2.4.01")
+			}
+		}()
+		for rows.Next() {
+			var object, pkey, colType string
+			var ratio sql.NullFloat64 // ratio can be null if data_uncompressed_bytes is 0
+
+			if err := rows.Scan(&object, &pkey, &colType, &ratio); err == nil {
+				details := "First column of PRIMARY KEY/ORDER BY (" + pkey + ") should not"
+				issueFound := false
+				if strings.Contains(strings.ToLower(pkey), "id") {
+					details += " be some sort of id"
+					issueFound = true
+				}
+				wideTypes := []string{"UUID", "ULID", "UInt64", "Int64", "IPv4", "IPv6", "UInt32", "Int32", "UInt128"}
+				for _, wt := range wideTypes {
+					if colType == wt {
+						details += fmt.Sprintf(" use a wide datatype like (%s)", colType)
+						issueFound = true
+						break
+					}
+				}
+				if strings.HasPrefix(colType, "DateTime") {
+					details += fmt.Sprintf(" use a wide datatype like (%s)", colType)
+					issueFound = true
+				}
+				currentRatio := 0.0
+				if ratio.Valid {
+					currentRatio = ratio.Float64
+					if currentRatio > 0.5 {
+						details += fmt.Sprintf(" has non optimal compress ratio (%.2f)", currentRatio)
+						issueFound = true
+					}
+				}
+
+				if issueFound {
+					results = append(results, Result{
+						ID:       "A2.4.01",
+						Object:   object,
+						Severity: "Minor",
+						Details:  details,
+						Values:   map[string]float64{"compression_ratio": currentRatio},
+					})
+				}
+			}
+		}
+	} else {
+		log.Error().Err(err).Msg("Failed to execute A2.4.01 query")
+	}
+
+	// A2.4.02: Check for too many nullable columns
+	rows, err = a.query(`
+		SELECT
+			format('{}.{}', database, table) AS object,
+			countIf(type LIKE '%Nullable%') as nullable_columns,
+			count() as columns
+		FROM system.columns 
+		WHERE database NOT IN ('system', 'information_schema', 'INFORMATION_SCHEMA')
+		GROUP BY database, table
+		HAVING nullable_columns > 0.1 * columns OR nullable_columns > 10
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkPrimaryKeys A2.4.02")
+			}
+		}()
+		for rows.Next() {
+			var object string
+			var nullableColumns, totalColumns int64
+			if err := rows.Scan(&object, &nullableColumns, &totalColumns); err == nil {
+				results = append(results, Result{
+					ID:       "A2.4.02",
+					Object:   object,
+					Severity: "Minor",
+					Details:  fmt.Sprintf("Avoid nulls (%d nullable columns out of %d)", nullableColumns, totalColumns),
+					Values: map[string]float64{
+						"nullable_columns": float64(nullableColumns),
+						"columns":          float64(totalColumns),
+					},
+				})
+			}
+		}
+	} else {
+		log.Error().Err(err).Msg("Failed to execute A2.4.02 query")
+	}
+
+	// A2.4.03: Check if compression codecs are used
+	row := a.queryRow(`
+		SELECT count() 
+		FROM system.columns
+		WHERE compression_codec <> '' AND database NOT IN ('system', 'information_schema', 'INFORMATION_SCHEMA')
+	`)
+	var columnsWithCodecs int64
+	if err := row.Scan(&columnsWithCodecs); err == nil {
+		if columnsWithCodecs == 0 {
+			results = append(results, Result{
+				ID:       "A2.4.03",
+				Object:   "Codecs",
+				Severity: "Minor",
+				Details:  "Consider using compression codecs for heavy columns (not used currently)",
+				Values:   map[string]float64{},
+			})
+		}
+	} else {
+		log.Error().Err(err).Msg("Failed to execute A2.4.03 query")
+	}
+
+	return results
+}
+
+func (a *Auditor) checkTables() []Result {
+	var results []Result
+
+	// Check for tables with too many columns
+	rows, err := a.query(`
+		SELECT 
+			database, 
+			table, 
+			count() as columns
+		FROM system.columns 
+		WHERE database NOT IN ('system', 'INFORMATION_SCHEMA', 'information_schema')
+		GROUP BY database, table
+		HAVING columns > 600
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkTables")
+			}
+		}()
+		for rows.Next() {
+			var database, table string
+			var columns int64
+
+			if err := rows.Scan(&database, &table, &columns); err == nil {
+				severity := "Minor"
+				if columns > 1500 {
+					severity = "Critical"
+				} else if columns > 1000 {
+					severity = "Major"
+				} else if columns > 800 {
+					severity = "Moderate"
+				}
+
+				results = append(results, Result{
+					ID:       "A1.3.01",
+					Object:   fmt.Sprintf("%s.%s", database, table),
+					Severity: severity,
+					Details:  fmt.Sprintf("Too many columns (count: %d)", columns),
+					Values:   map[string]float64{"columns": float64(columns)},
+				})
+			}
+		}
+	}
+
+	// Check for tables with TTL but without ttl_only_drop_parts=1
+	rows, err = a.query(`
+		SELECT database, name
+		FROM system.tables
+		WHERE create_table_query LIKE '% TTL %'
+		AND name NOT IN ('grants')
+		AND NOT (create_table_query LIKE '%ttl_only_drop_parts = 1%' OR create_table_query LIKE '%ttl_only_drop_parts=1%')
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkTables TTL")
+			}
+		}()
+		for rows.Next() {
+			var database, name string
+			if err := rows.Scan(&database, &name); err == nil {
+				results = append(results, Result{
+					ID:       "A1.3.02",
+					Object:   fmt.Sprintf("%s.%s", database, name),
+					Severity: "Minor",
+					Details:  "Table has TTL but ttl_only_drop_parts=1 is not used",
+					Values:   map[string]float64{},
+				})
+			}
+		}
+	}
+
+	return results
+}
+
+func (a *Auditor) checkMemoryUsage() []Result {
+	var results []Result
+
+	// Check memory usage ratio
+	row := a.queryRow(`
+		SELECT 
+			(SELECT value FROM system.asynchronous_metrics WHERE metric = 'MemoryResident') as memory_resident,
+			(SELECT value FROM system.asynchronous_metrics WHERE metric = 'OSMemoryTotal') as memory_total
+	`)
+	var memoryResident, memoryTotal float64
+	if err := row.Scan(&memoryResident, &memoryTotal); err == nil && memoryTotal > 0 {
+		ratio := memoryResident / memoryTotal
+		if ratio > 0.8 {
+			severity := "Major"
+			if ratio > 0.9 {
+				severity = "Critical"
+			}
+
+			results = append(results, Result{
+				ID:       "A3.0.15",
+				Object:   "Memory",
+				Severity: severity,
+				Details:  fmt.Sprintf("Memory usage is high (%.1f%% of total)", ratio*100),
+				Values: map[string]float64{
+					"memory_resident": memoryResident,
+					"memory_total":    memoryTotal,
+					"ratio":           ratio,
+				},
+			})
+		}
+	}
+
+	// Check memory used by dictionaries and memory tables
+	row = a.queryRow(`
+		SELECT 
+			(SELECT sum(bytes_allocated) FROM system.dictionaries) as dictionaries,
+			(SELECT sum(total_bytes) FROM system.tables WHERE engine IN ('Memory','Set','Join')) as mem_tables,
+			(SELECT value FROM system.asynchronous_metrics WHERE metric='OSMemoryTotal') as total_memory,
+			(dictionaries + mem_tables) / total_memory as ratio
+	`)
+	var dictionaries, memTables, totalMemory, dictMemRatio float64
+	if err := row.Scan(&dictionaries, &memTables, &totalMemory, &dictMemRatio); err == nil && dictMemRatio > 0.1 {
+		severity := "Minor"
+		if dictMemRatio > 0.3 {
+			severity = "Critical"
+		} else if dictMemRatio > 0.25 {
+			severity = "Major"
+		} else if dictMemRatio > 0.2 {
+			severity = "Moderate"
+		}
+
+		results = append(results, Result{
+			ID:       "A1.7.01",
+			Object:   "system OS RAM",
+			Severity: severity,
+			Details:  fmt.Sprintf("Too much memory used by dictionaries and memory tables (ratio: %.3f)", dictMemRatio),
+			Values: map[string]float64{
+				"ratio":        dictMemRatio,
+				"dictionaries": dictionaries,
+				"mem_tables":   memTables,
+			},
+		})
+	}
+
+	// Check memory used by primary keys
+	row = a.queryRow(`
+		SELECT 
+			(SELECT sum(primary_key_bytes_in_memory) FROM system.parts) as primary_key_bytes_in_memory,
+			(SELECT value FROM system.asynchronous_metrics WHERE metric='OSMemoryTotal') as total_memory,
+			primary_key_bytes_in_memory / total_memory as ratio
+	`)
+	var primaryKeyMemory, pkMemRatio float64
+	if err := row.Scan(&primaryKeyMemory, &totalMemory, &pkMemRatio); err == nil && pkMemRatio > 0.1 {
+		severity := "Minor"
+		if pkMemRatio > 0.3 {
+			severity = "Critical"
+		} else if pkMemRatio > 0.25 {
+			severity = "Major"
+		} else if pkMemRatio > 0.2 {
+			severity = "Moderate"
+		}
+
+		results = append(results, Result{
+			ID:       "A1.7.02",
+			Object:   "system OS RAM",
+			Severity: severity,
+			Details:  fmt.Sprintf("Too much memory used by primary keys (ratio: %.3f)", pkMemRatio),
+			Values: map[string]float64{
+				"ratio":                       pkMemRatio,
+				"primary_key_bytes_in_memory": primaryKeyMemory,
+			},
+		})
+	}
+
+	return results
+}
+
+func (a *Auditor) checkDiskUsage() []Result {
+	var results []Result
+
+	// Check disk space
+	rows, err := a.query(`
+		SELECT 
+			name,
+			free_space,
+			total_space,
+			free_space / total_space as ratio
+		FROM system.disks 
+		WHERE type = 'Local' AND ratio < 0.3
+	`)
+	if err == nil {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkDiskUsage")
+			}
+		}()
+		for rows.Next() {
+			var name string
+			var freeSpace, totalSpace, ratio float64
+
+			if err := rows.Scan(&name, &freeSpace, &totalSpace, &ratio); err == nil {
+				usedRatio := 1.0 - ratio
+				severity := "Minor"
+				if usedRatio > 0.9 {
+					severity = "Critical"
+				} else if usedRatio > 0.85 {
+					severity = "Major"
+				} else if usedRatio > 0.8 {
+					severity = "Moderate"
+				}
+
+				results = append(results, Result{
+					ID:       "A1.8.01",
+					Object:   fmt.Sprintf("Disk %s", name),
+					Severity: severity,
+					Details:  fmt.Sprintf("Too low free space (%.1f%% used)", usedRatio*100),
+					Values: map[string]float64{
+						"ratio":            usedRatio,
+						"unreserved_space": freeSpace,
+					},
+				})
+			}
+		}
+	}
+
+	// A1.8.02: object storage and cache disks (type != 'Local') running out of
+	// space; s3 disks often report total_space = 0 (unbounded), those are skipped
+	objRows, objErr := a.query(`
+		SELECT
+			name,
+			type,
+			free_space,
+			total_space,
+			free_space / total_space as ratio
+		FROM system.disks
+		WHERE type != 'Local' AND total_space > 0 AND ratio < 0.3
+	`)
+	if objErr == nil {
+		defer func() {
+			if closeErr := objRows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkDiskUsage object disks")
+			}
+		}()
+		for objRows.Next() {
+			var name, diskType string
+			var freeSpace, totalSpace, ratio float64
+
+			if err := objRows.Scan(&name, &diskType, &freeSpace, &totalSpace, &ratio); err == nil {
+				usedRatio := 1.0 - ratio
+				severity := "Minor"
+				if usedRatio > 0.9 {
+					severity = "Critical"
+				} else if usedRatio > 0.85 {
+					severity = "Major"
+				} else if usedRatio > 0.8 {
+					severity = "Moderate"
+				}
+
+				results = append(results, Result{
+					ID:       "A1.8.02",
+					Object:   fmt.Sprintf("Disk %s (%s)", name, diskType),
+					Severity: severity,
+					Details:  fmt.Sprintf("Too low free space on %s disk (%.1f%% used)", diskType, usedRatio*100),
+					Values: map[string]float64{
+						"ratio":            usedRatio,
+						"unreserved_space": freeSpace,
+					},
+				})
+			}
+		}
+	}
+
+	// A1.8.03: filesystem cache in front of object storage misses too often,
+	// meaning most reads still go to s3; only fires when the cache saw traffic
+	var cacheBytes, sourceBytes float64
+	cacheErr := a.queryRow(`
+		SELECT
+			sumIf(value, event = 'CachedReadBufferReadFromCacheBytes'),
+			sumIf(value, event = 'CachedReadBufferReadFromSourceBytes')
+		FROM system.events
+		WHERE event IN ('CachedReadBufferReadFromCacheBytes', 'CachedReadBufferReadFromSourceBytes')
+	`).Scan(&cacheBytes, &sourceBytes)
+	if cacheErr == nil && cacheBytes+sourceBytes > 1024*1024*1024 {
+		hitRatio := cacheBytes / (cacheBytes + sourceBytes)
+		if hitRatio < 0.5 {
+			severity := "Minor"
+			if hitRatio < 0.1 {
+				severity = "Major"
+			} else if hitRatio < 0.25 {
+				severity = "Moderate"
+			}
+			results = append(results, Result{
+				ID:       "A1.8.03",
+				Object:   "Filesystem cache",
+				Severity: severity,
+				Details:  fmt.Sprintf("Filesystem cache hit ratio is %.1f%%, most reads go to object storage", hitRatio*100),
+				Values: map[string]float64{
+					"hit_ratio":        hitRatio,
+					"read_from_cache":  cacheBytes,
+					"read_from_source": sourceBytes,
+				},
+			})
+		}
+	}
+
+	// A1.8.04: detached parts on object storage disks are paid-for garbage,
+	// they keep their s3 objects until dropped explicitly
+	detachedRows, detachedErr := a.query(`
+		SELECT disk, sum(bytes_on_disk) AS bytes
+		FROM system.detached_parts
+		WHERE disk IN (SELECT name FROM system.disks WHERE type != 'Local')
+		GROUP BY disk
+		HAVING bytes > 1073741824
+	`)
+	if detachedErr == nil {
+		defer func() {
+			if closeErr := detachedRows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkDiskUsage detached parts")
+			}
+		}()
+		for detachedRows.Next() {
+			var disk string
+			var bytes float64
+
+			if err := detachedRows.Scan(&disk, &bytes); err == nil {
+				severity := "Minor"
+				if bytes > 100*1024*1024*1024 {
+					severity = "Major"
+				} else if bytes > 10*1024*1024*1024 {
+					severity = "Moderate"
+				}
+				results = append(results, Result{
+					ID:       "A1.8.04",
+					Object:   fmt.Sprintf("Disk %s", disk),
+					Severity: severity,
+					Details:  fmt.Sprintf("%.1f GiB of detached parts on object storage disk keep paying for dead objects", bytes/1024/1024/1024),
+					Values: map[string]float64{
+						"detached_bytes": bytes,
+					},
+				})
+			}
+		}
+	}
+
+	return results
+}
+
+func (a *Auditor) checkPerformanceMetrics() []Result {
+	var results []Result
+	var row *sql.Row
+	var err error
+
+	// A3.0.1: Check max concurrent queries
+	var maxConcurrentQueries float64
+	err = a.queryRow("SELECT value FROM system.settings WHERE name = 'max_concurrent_queries'").Scan(&maxConcurrentQueries)
+	if err == nil { // Found the setting
+		var currentQueries float64
+		err = a.queryRow("SELECT value FROM system.metrics WHERE metric = 'Query'").Scan(&currentQueries)
+		if err == nil {
+			if currentQueries > maxConcurrentQueries*0.5 { // Threshold from SQL
+				severity := "Minor"
+				if currentQueries > maxConcurrentQueries*0.95 {
+					severity = "Major"
+				} else if currentQueries > maxConcurrentQueries*0.75 {
+					severity = "Moderate"
+				}
+				results = append(results, Result{
+					ID:       "A3.0.1",
+					Object:   "System",
+					Severity: severity,
+					Details:  fmt.Sprintf("Too many running queries (current: %.0f, max: %.0f)", currentQueries, maxConcurrentQueries),
+					Values:   map[string]float64{"current_queries": currentQueries, "max_concurrent_queries": maxConcurrentQueries},
+				})
+			}
+		} else {
+			log.Warn().Err(err).Msg("Failed to get current query count for A3.0.1")
+		}
+	} else {
+		log.Warn().Err(err).Msg("Failed to get max_concurrent_queries setting for A3.0.1")
+	}
+
+	// A3.0.2: Check max connections
+	var maxConnections float64
+	err = a.queryRow("SELECT value FROM system.settings WHERE name = 'max_connections'").Scan(&maxConnections)
+	if err == nil { // Found the setting
+		var currentConnections float64
+		err = a.queryRow("SELECT sum(value) FROM system.metrics WHERE metric IN ('TCPConnection','MySQLConnection','HTTPConnection','InterserverConnection','PostgreSQLConnection')").Scan(&currentConnections)
+		if err == nil {
+			if currentConnections > maxConnections*0.5 { // Threshold from SQL
+				severity := "Minor"
+				if currentConnections > maxConnections*0.95 {
+					severity = "Major"
+				} else if currentConnections > maxConnections*0.75 {
+					severity = "Moderate"
+				}
+				results = append(results, Result{
+					ID:       "A3.0.2",
+					Object:   "System",
+					Severity: severity,
+					Details:  fmt.Sprintf("Too many connections (current: %.0f, max: %.0f)", currentConnections, maxConnections),
+					Values:   map[string]float64{"current_connections": currentConnections, "max_connections": maxConnections},
+				})
+			}
+		} else {
+			log.Warn().Err(err).Msg("Failed to get current connection count for A3.0.2")
+		}
+	} else {
+		log.Warn().Err(err).Msg("Failed to get max_connections setting for A3.0.2")
+	}
+
+	// Check if there are readonly replicas (A3.0.3)
+	row = a.queryRow("SELECT value FROM system.metrics WHERE metric='ReadonlyReplica'")
+	var readonlyReplicas float64
+	if err = row.Scan(&readonlyReplicas); err == nil && readonlyReplicas > 0 {
+		results = append(results, Result{
+			ID:       "A3.0.3",
+			Object:   "System",
+			Severity: "Critical",
+			Details:  "Some replicas are read-only",
+			Values:   map[string]float64{"readonly_replicas": readonlyReplicas},
+		})
+	}
+
+	// A3.0.4: Check Block In-flight Ops
+	rowsA304, errA304 := a.query("SELECT metric, value FROM system.asynchronous_metrics WHERE metric LIKE 'BlockInFlightOps%' AND value > 128")
+	if errA304 == nil {
+		defer func() {
+			if closeErr := rowsA304.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close A3.0.4 rows")
+			}
+		}()
+		for rowsA304.Next() {
+			var metricName string
+			var value float64
+			if err := rowsA304.Scan(&metricName, &value); err == nil {
+				severity := "Minor"
+				if value > 245 { // Thresholds from SQL
+					severity = "Major"
+				} else if value > 200 {
+					severity = "Moderate"
+				}
+				results = append(results, Result{
+					ID:       "A3.0.4",
+					Object:   metricName,
+					Severity: severity,
+					Details:  fmt.Sprintf("Block in-flight ops is high for %s (value: %.0f)", metricName, value),
+					Values:   map[string]float64{"in_flight_ops": value},
+				})
+			}
+		}
+	} else {
+		log.Warn().Err(errA304).Msg("Failed to query BlockInFlightOps for A3.0.4")
+	}
+
+	// Check load average (A3.0.5)
+	rowsLoadAvg, errLoadAvg := a.query(`
+		SELECT 
+			metric, 
+			value,
+			(SELECT count() FROM system.asynchronous_metrics WHERE metric LIKE 'CPUFrequencyMHz%') as cpu_count
+		FROM system.asynchronous_metrics 
+		WHERE metric LIKE 'LoadAverage%' AND value > 0
+	`)
+	if errLoadAvg == nil {
+		defer func() {
+			if closeErr := rowsLoadAvg.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkPerformanceMetrics load average")
+			}
+		}()
+		for rowsLoadAvg.Next() {
+			var metric string
+			var value, cpuCount float64
+
+			if err := rowsLoadAvg.Scan(&metric, &value, &cpuCount); err == nil {
+				if cpuCount > 0 {
+					ratio := value / cpuCount
+					if ratio > 0.9 {
+						severity := "Minor"
+						if ratio > 10 {
+							severity = "Critical"
+						} else if ratio > 2 {
+							severity = "Major"
+						} else if ratio > 1 {
+							severity = "Moderate"
+						}
+
+						results = append(results, Result{
+							ID:       "A3.0.5",
+							Object:   metric,
+							Severity: severity,
+							Details:  fmt.Sprintf("Load average is high (%s %.2f, %d cores)", metric, value, int(cpuCount)),
+							Values: map[string]float64{
+								"load":      value,
+								"cpu_count": cpuCount,
+								"ratio":     ratio,
+							},
+						})
+					}
+				}
+			}
+		}
+	} else {
+		log.Warn().Err(errLoadAvg).Msg("Failed to query load average for A3.0.5")
+	}
+
+	// Check replica delays (A3.0.6)
+	rowsReplicaDelays, errReplicaDelays := a.query(`
+		SELECT metric, value
+		FROM system.asynchronous_metrics
+		WHERE metric IN ('ReplicasMaxAbsoluteDelay', 'ReplicasMaxRelativeDelay') 
+		AND value > 300
+	`)
+	if errReplicaDelays == nil {
+		defer func() {
+			if closeErr := rowsReplicaDelays.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close checkPerformanceMetrics replica delays")
+			}
+		}()
+		for rowsReplicaDelays.Next() {
+			var metric string
+			var value float64
+
+			if err := rowsReplicaDelays.Scan(&metric, &value); err == nil {
+				severity := "Minor"
+				if value > 24*3600 {
+					severity = "Critical"
+				} else if value > 3*3600 {
+					severity = "Major"
+				} else if value > 1800 {
+					severity = "Moderate"
+				}
+
+				results = append(results, Result{
+					ID:       "A3.0.6",
+					Object:   metric,
+					Severity: severity,
+					Details:  fmt.Sprintf("Replica delay is too big (%s, %.0f seconds)", metric, value),
+					Values:   map[string]float64{"delay": value},
+				})
+			}
+		}
+	} else {
+		log.Warn().Err(errReplicaDelays).Msg("Failed to query replica delays for A3.0.6")
+	}
+
+	// Check queue sizes (A3.0.7 - A3.0.13)
+	queueChecks := []struct {
+		metric    string
+		id        string
+		threshold float64
+		name      string // Used for Details string and Values map key
+	}{
+		{"ReplicasMaxInsertsInQueue", "A3.0.7", 100, "max inserts in queue"},
+		{"ReplicasSumInsertsInQueue", "A3.0.8", 300, "sum inserts in queue"},
+		{"ReplicasMaxMergesInQueue", "A3.0.9", 80, "max merges in queue"},
+		{"ReplicasSumMergesInQueue", "A3.0.10", 200, "sum merges in queue"},
+		{"ReplicasMaxQueueSize", "A3.0.11", 200, "max tasks in queue"},
+		{"ReplicasSumQueueSize", "A3.0.12", 500, "sum tasks in queue"},
+		{"ReplicasSumQueueSize", "A3.0.13", 500, "sum tasks in queue (alt ID)"}, // Added A3.0.13
+	}
+
+	for _, check := range queueChecks {
+		row = a.queryRow(fmt.Sprintf("SELECT value FROM system.asynchronous_metrics WHERE metric = '%s'", check.metric))
+		var value float64
+		if err = row.Scan(&value); err == nil && value > check.threshold {
+			results = append(results, Result{
+				ID:       check.id,
+				Object:   check.metric,
+				Severity: "Minor", // Default severity for these queue checks in SQL
+				Details:  fmt.Sprintf("Too many %s (%s, %.0f)", strings.ReplaceAll(check.name, " (alt ID)", ""), check.metric, value),
+				Values:   map[string]float64{strings.ReplaceAll(strings.ReplaceAll(check.name, " ", "_"), "_(alt_ID)", ""): value},
+			})
+		} else if err != nil {
+			log.Warn().Err(err).Str("metric", check.metric).Str("check_id", check.id).Msg("Failed to get queue size metric")
+		}
+	}
+
+	// Check max parts in partition (A3.0.14)
+	row = a.queryRow(`
+		SELECT 
+			value,
+			(SELECT toUInt32(value) FROM system.merge_tree_settings WHERE name='parts_to_delay_insert') as parts_to_delay_insert,
+			(SELECT toUInt32(value) FROM system.merge_tree_settings WHERE name='parts_to_throw_insert') as parts_to_throw_insert
+		FROM system.asynchronous_metrics 
+		WHERE metric = 'MaxPartCountForPartition'
+	`)
+	var maxParts, partsToDelay, partsToThrow float64
+	if err = row.Scan(&maxParts, &partsToDelay, &partsToThrow); err == nil && maxParts > partsToDelay*0.9 {
+		severity := "Minor"
+		if maxParts > partsToThrow {
+			severity = "Critical"
+		} else if maxParts > partsToDelay {
+			severity = "Major"
+		}
+
+		results = append(results, Result{
+			ID:       "A3.0.14",
+			Object:   "MaxPartCountForPartition",
+			Severity: severity,
+			Details:  fmt.Sprintf("Too many parts in partition (%.0f)", maxParts),
+			Values:   map[string]float64{"max_parts_in_partition": maxParts},
+		})
+	} else if err != nil {
+		log.Warn().Err(err).Msg("Failed to get max parts in partition for A3.0.14")
+	}
+
+	// A3.0.16: Check memory used by other processes
+	var maxServerMemoryUsageToRamRatioFloat float64
+	err = a.queryRow("SELECT value FROM system.settings WHERE name = 'max_server_memory_usage_to_ram_ratio'").Scan(&maxServerMemoryUsageToRamRatioFloat)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to get max_server_memory_usage_to_ram_ratio setting for A3.0.16, using default 0.7")
+		maxServerMemoryUsageToRamRatioFloat = 0.7 // Default from ClickHouse if not set
+	}
+
+	var totalMem, freeWithoutCached, memResident, cachedMem, buffersMem float64
+	queryA3016 := `
+		SELECT
+			(SELECT value FROM system.asynchronous_metrics WHERE metric = 'OSMemoryTotal'),
+			(SELECT value FROM system.asynchronous_metrics WHERE metric = 'OSMemoryFreeWithoutCached'),
+			(SELECT value FROM system.asynchronous_metrics WHERE metric = 'MemoryResident'),
+			(SELECT value FROM system.asynchronous_metrics WHERE metric = 'OSMemoryCached'),
+			(SELECT value FROM system.asynchronous_metrics WHERE metric = 'OSMemoryBuffers')
+	`
+	err = a.queryRow(queryA3016).Scan(&totalMem, &freeWithoutCached, &memResident, &cachedMem, &buffersMem)
+
+	if err == nil && totalMem > 0 {
+		totalUsed := totalMem - freeWithoutCached
+		usedByOtherProcesses := totalUsed - (buffersMem + cachedMem + memResident)
+
+		thresholdRatio := (1.0 - maxServerMemoryUsageToRamRatioFloat) / 2.0
+		if thresholdRatio < 0 { // Ensure ratio is not negative if maxServer... > 1
+			thresholdRatio = 0
+		}
+		threshold := totalMem * thresholdRatio
+
+		if usedByOtherProcesses > threshold {
+			severity := "Minor"
+			// SQL: multiIf(UsedByOtherProcesses > Total*(1-max_server_memory_usage_to_ram_ratio), 'Critical', 'Minor')
+			// This means if UsedByOtherProcesses is greater than Total*(1-max_server_memory_usage_to_ram_ratio), it's Critical.
+			// The check itself is for UsedByOtherProcesses > Total*(1-max_server_memory_usage_to_ram_ratio) / 2
+			criticalThreshold := totalMem * (1.0 - maxServerMemoryUsageToRamRatioFloat)
+			if criticalThreshold < 0 {
+				criticalThreshold = 0
+			}
+
+			if usedByOtherProcesses > criticalThreshold {
+				severity = "Critical"
+			}
+
+			results = append(results, Result{
+				ID:       "A3.0.16",
+				Object:   "Memory",
+				Severity: severity,
+				Details:  fmt.Sprintf("Memory used by other processes is high (%.0f bytes of %.0f total. Buffers: %.0f, Cached: %.0f, ClickHouse: %.0f, Free: %.0f)", usedByOtherProcesses, totalMem, buffersMem, cachedMem, memResident, freeWithoutCached),
+				Values: map[string]float64{
+					"memory_used_by_other_processes": usedByOtherProcesses,
+					"memory_total":                   totalMem,
+				},
+			})
+		}
+	} else if err != nil {
+		log.Warn().Err(err).Msg("Failed to get memory metrics for A3.0.16")
+	}
+
+	return results
+}
+
+// checkAccessControl audits the SQL access entities: login without a
+// password, users who can manage other users, readonly settings that the
+// session can override and users no quota applies to. Access entities are the
+// same on every replica of a cluster, so these checks query the local node
+// only and get stamped with the local host.
+func (a *Auditor) checkAccessControl() []Result {
+	var results []Result
+
+	// A5.0.01 users that can log in without a password; 'default' is the
+	// worst case, it exists on every install and is the first login scanners
+	// try. toString() keeps the query working across the auth_type
+	// scalar/array change between server versions.
+	rows, err := a.query("SELECT name, toString(host_ip) AS host_ip FROM system.users WHERE toString(auth_type) LIKE '%no_password%'")
+	if err == nil {
+		for rows.Next() {
+			var name, hostIP string
+			if scanErr := rows.Scan(&name, &hostIP); scanErr != nil {
+				continue
+			}
+			severity := "Major"
+			if name == "default" {
+				severity = "Critical"
+			}
+			results = append(results, Result{
+				ID:       "A5.0.01",
+				Object:   fmt.Sprintf("user %s", name),
+				Severity: severity,
+				Details:  fmt.Sprintf("User can log in without a password (allowed from %s)", hostIP),
+				Values:   map[string]float64{},
+			})
+		}
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close checkAccessControl users")
+		}
+	}
+
+	// A5.0.02 users who can create/alter users and grants; every such
+	// account is an admin account regardless of what it is named
+	rows, err = a.query("SELECT user_name, access_type FROM system.grants WHERE toString(access_type) IN ('ACCESS MANAGEMENT', 'ALL') AND user_name != ''")
+	if err == nil {
+		for rows.Next() {
+			var userName, accessType string
+			if scanErr := rows.Scan(&userName, &accessType); scanErr != nil {
+				continue
+			}
+			results = append(results, Result{
+				ID:       "A5.0.02",
+				Object:   fmt.Sprintf("user %s", userName),
+				Severity: "Moderate",
+				Details:  fmt.Sprintf("User holds %s and can manage users, grants and quotas", accessType),
+				Values:   map[string]float64{},
+			})
+		}
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close checkAccessControl grants")
+		}
+	}
+
+	// A5.0.03 readonly declared but not pinned: without CONST writability the
+	// session can SET readonly=0 and the restriction is decorative
+	rows, err = a.query(`
+		SELECT
+			coalesce(profile_name, user_name, role_name, '') AS owner,
+			value,
+			toString(writability) AS writability
+		FROM system.settings_profile_elements
+		WHERE setting_name = 'readonly' AND value IS NOT NULL
+	`)
+	if err == nil {
+		for rows.Next() {
+			var owner, value, writability string
+			if scanErr := rows.Scan(&owner, &value, &writability); scanErr != nil {
+				continue
+			}
+			if value == "0" || writability == "CONST" {
+				continue
+			}
+			results = append(results, Result{
+				ID:       "A5.0.03",
+				Object:   owner,
+				Severity: "Moderate",
+				Details:  fmt.Sprintf("readonly=%s is set but writability is %s, the session can override it with SET readonly=0", value, writability),
+				Values:   map[string]float64{},
+			})
+		}
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close checkAccessControl profiles")
+		}
+	}
+
+	// A5.0.04 quota coverage: an ad-hoc user without any quota can saturate
+	// the cluster with a single runaway dashboard
+	row := a.queryRow("SELECT count(), countIf(apply_to_all) FROM system.quotas")
+	var quotaCount, applyToAllCount int64
+	if err := row.Scan(&quotaCount, &applyToAllCount); err == nil {
+		if quotaCount == 0 {
+			results = append(results, Result{
+				ID:       "A5.0.04",
+				Object:   "Quotas",
+				Severity: "Moderate",
+				Details:  "No quotas defined, every user can issue unlimited queries",
+				Values:   map[string]float64{"quotas_count": 0},
+			})
+		} else if applyToAllCount == 0 {
+			covered := make(map[string]bool)
+			coveredRows, coveredErr := a.query("SELECT DISTINCT arrayJoin(apply_to_list) FROM system.quotas")
+			if coveredErr == nil {
+				for coveredRows.Next() {
+					var name string
+					if scanErr := coveredRows.Scan(&name); scanErr == nil {
+						covered[name] = true
+					}
+				}
+				if closeErr := coveredRows.Close(); closeErr != nil {
+					log.Error().Err(closeErr).Msg("can't close checkAccessControl quota coverage")
+				}
+
+				var uncovered []string
+				userRows, usersErr := a.query("SELECT name FROM system.users ORDER BY name")
+				if usersErr == nil {
+					for userRows.Next() {
+						var name string
+						if scanErr := userRows.Scan(&name); scanErr == nil && !covered[name] {
+							uncovered = append(uncovered, name)
+						}
+					}
+					if closeErr := userRows.Close(); closeErr != nil {
+						log.Error().Err(closeErr).Msg("can't close checkAccessControl users list")
+					}
+				}
+				if len(uncovered) > 0 {
+					names := uncovered
+					if len(names) > 10 {
+						names = append(append([]string{}, names[:10]...), "...")
+					}
+					results = append(results, Result{
+						ID:       "A5.0.04",
+						Object:   "Quotas",
+						Severity: "Minor",
+						Details:  fmt.Sprintf("%d users are not covered by any quota: %s", len(uncovered), strings.Join(names, ", ")),
+						Values:   map[string]float64{"uncovered_users": float64(len(uncovered))},
+					})
+				}
+			}
+		}
+	}
+
+	return results
+}
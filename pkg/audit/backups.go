@@ -0,0 +1,253 @@
+package audit
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// backupMaxAge is how old the newest successful backup may be before a
+// cluster is flagged as not recently backed up
+const backupMaxAge = 7 * 24 * time.Hour
+
+// backupCriticalAge is the age past which a stale backup escalates from
+// Major to Critical
+const backupCriticalAge = 30 * 24 * time.Hour
+
+// backupAPITimeout caps the clickhouse-backup API round trip, so an
+// unresponsive sidecar doesn't stall the audit run
+const backupAPITimeout = 10 * time.Second
+
+// WithBackupAPI points the backup checks at a clickhouse-backup API endpoint
+// (empty disables the API check); system.backups only covers operations since
+// the server started, the API provides the durable remote history
+func (a *Auditor) WithBackupAPI(url string) *Auditor {
+	a.backupAPI = url
+	return a
+}
+
+// BackupListItem is one backup from the clickhouse-backup API list; Location
+// is "local" or "remote"
+type BackupListItem struct {
+	Name     string `json:"name"`
+	Created  string `json:"created"`
+	Location string `json:"location"`
+	Size     uint64 `json:"size"`
+}
+
+// FetchBackupList queries a clickhouse-backup API endpoint for its backup
+// list; the API streams one JSON object per line
+func FetchBackupList(url string) ([]BackupListItem, error) {
+	httpClient := &http.Client{Timeout: backupAPITimeout}
+	resp, err := httpClient.Get(strings.TrimRight(url, "/") + "/backup/list")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("backup list returned HTTP %d", resp.StatusCode)
+	}
+	var items []BackupListItem
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var item BackupListItem
+		if unmarshalErr := json.Unmarshal([]byte(line), &item); unmarshalErr != nil {
+			return nil, unmarshalErr
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
+
+// backupCreatedTime parses the created timestamp formats clickhouse-backup
+// emits across versions
+func backupCreatedTime(created string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339} {
+		if parsed, parseErr := time.Parse(layout, created); parseErr == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// checkBackups reports the backup/restore state: last successful BACKUP per
+// host from system.backups (A4.0.01), failed operations (A4.0.02) and, when a
+// clickhouse-backup API endpoint is configured, the age of the newest remote
+// backup there (A4.0.03); a cluster with no recent backup at all is Critical
+func (a *Auditor) checkBackups() []Result {
+	var results []Result
+
+	// system.backups only exists on recent servers; its absence is not a
+	// finding, so probe first instead of letting the queries fail
+	var hasBackupsTable int64
+	probe := a.clickHouse.QueryRowContext(a.queryContext(), "SELECT count() FROM system.tables WHERE database = 'system' AND name = 'backups'")
+	if err := probe.Scan(&hasBackupsTable); err != nil || hasBackupsTable == 0 {
+		hasBackupsTable = 0
+	}
+
+	if hasBackupsTable > 0 {
+		// No successful BACKUP anywhere since the servers started; only
+		// conclusive without an API endpoint holding the durable history
+		row := a.queryRow(fmt.Sprintf("SELECT countIf(status = 'BACKUP_CREATED') FROM %s", a.clusterTable("backups")))
+		var okBackups int64
+		if err := row.Scan(&okBackups); err == nil && okBackups == 0 && a.backupAPI == "" {
+			results = append(results, Result{
+				ID:       "A4.0.01",
+				Object:   "Backups",
+				Severity: "Critical",
+				Details:  "No successful BACKUP in system.backups (history starts at server start) and no clickhouse-backup API configured to verify remote backups",
+				Values:   map[string]float64{"ok_backups": 0},
+			})
+		}
+
+		// Last successful BACKUP per host is stale
+		a.eachHostRow(fmt.Sprintf(`
+			SELECT
+				hostName() AS h,
+				count() AS ok_backups,
+				dateDiff('second', max(end_time), now()) AS age_seconds
+			FROM %s
+			WHERE status = 'BACKUP_CREATED'
+			GROUP BY h
+		`, a.clusterTable("backups")), func(rows *sql.Rows) error {
+			var host string
+			var hostOkBackups int64
+			var ageSeconds float64
+			if err := rows.Scan(&host, &hostOkBackups, &ageSeconds); err != nil {
+				return err
+			}
+			severity := ""
+			if ageSeconds > backupCriticalAge.Seconds() {
+				severity = "Critical"
+			} else if ageSeconds > backupMaxAge.Seconds() {
+				severity = "Major"
+			}
+			if severity != "" {
+				results = append(results, Result{
+					ID:       "A4.0.01",
+					Host:     host,
+					Object:   "Backups",
+					Severity: severity,
+					Details:  fmt.Sprintf("Last successful BACKUP finished %.1f days ago (%d successful since server start)", ageSeconds/86400, hostOkBackups),
+					Values: map[string]float64{
+						"age_seconds": ageSeconds,
+						"ok_backups":  float64(hostOkBackups),
+					},
+				})
+			}
+			return nil
+		})
+
+		// Failed BACKUP/RESTORE operations
+		a.eachHostRow(fmt.Sprintf(`
+			SELECT
+				hostName() AS h,
+				countIf(status = 'BACKUP_FAILED') AS failed_backups,
+				countIf(status = 'RESTORE_FAILED') AS failed_restores,
+				anyLast(error) AS last_error
+			FROM %s
+			WHERE status IN ('BACKUP_FAILED', 'RESTORE_FAILED')
+			GROUP BY h
+		`, a.clusterTable("backups")), func(rows *sql.Rows) error {
+			var host, lastError string
+			var failedBackups, failedRestores int64
+			if err := rows.Scan(&host, &failedBackups, &failedRestores, &lastError); err != nil {
+				return err
+			}
+			if len(lastError) > 160 {
+				lastError = lastError[:160] + "..."
+			}
+			results = append(results, Result{
+				ID:       "A4.0.02",
+				Host:     host,
+				Object:   "Backups",
+				Severity: "Major",
+				Details:  fmt.Sprintf("Failed backup/restore operations since server start (backups: %d, restores: %d, last error: %s)", failedBackups, failedRestores, lastError),
+				Values: map[string]float64{
+					"failed_backups":  float64(failedBackups),
+					"failed_restores": float64(failedRestores),
+				},
+			})
+			return nil
+		})
+	}
+
+	if a.backupAPI != "" {
+		results = append(results, a.checkBackupAPI()...)
+	}
+
+	return results
+}
+
+// checkBackupAPI verifies the remote backup list the configured
+// clickhouse-backup instance reports; local-only backups don't count, they
+// disappear together with the host they are supposed to protect
+func (a *Auditor) checkBackupAPI() []Result {
+	items, apiErr := FetchBackupList(a.backupAPI)
+	if apiErr != nil {
+		return []Result{{
+			ID:       "A4.0.03",
+			Object:   "clickhouse-backup",
+			Severity: "Minor",
+			Details:  fmt.Sprintf("Can't verify backups via clickhouse-backup API %s: %v", a.backupAPI, apiErr),
+			Values:   map[string]float64{},
+		}}
+	}
+
+	var newest time.Time
+	remoteBackups := 0
+	for _, item := range items {
+		if item.Location == "local" {
+			continue
+		}
+		created, ok := backupCreatedTime(item.Created)
+		if !ok {
+			continue
+		}
+		remoteBackups++
+		if created.After(newest) {
+			newest = created
+		}
+	}
+
+	if remoteBackups == 0 {
+		return []Result{{
+			ID:       "A4.0.03",
+			Object:   "clickhouse-backup",
+			Severity: "Critical",
+			Details:  fmt.Sprintf("clickhouse-backup API %s lists no remote backups", a.backupAPI),
+			Values:   map[string]float64{"remote_backups": 0},
+		}}
+	}
+
+	age := time.Since(newest)
+	severity := ""
+	if age > backupCriticalAge {
+		severity = "Critical"
+	} else if age > backupMaxAge {
+		severity = "Major"
+	}
+	if severity == "" {
+		return nil
+	}
+	return []Result{{
+		ID:       "A4.0.03",
+		Object:   "clickhouse-backup",
+		Severity: severity,
+		Details:  fmt.Sprintf("Newest remote backup is %.1f days old (%d remote backups listed)", age.Hours()/24, remoteBackups),
+		Values: map[string]float64{
+			"age_seconds":    age.Seconds(),
+			"remote_backups": float64(remoteBackups),
+		},
+	}}
+}
@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"fmt"
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// CustomAuditCheck is a user-defined audit check loaded from
+// ~/.clickhouse-timeline/audit-checks.yaml. The query must return three
+// columns per finding: host, object and a numeric value. Every "{cluster}"
+// occurrence inside the query is replaced with the currently selected cluster
+// name. The threshold with the highest min not exceeding the value defines the
+// finding severity; rows matching no threshold are skipped.
+//
+// Example audit-checks.yaml:
+//
+//   - id: C1.1
+//     name: Detached Parts
+//     query: "SELECT hostName() AS h, concat(database,'.',table), count() FROM cluster('{cluster}', system.detached_parts) GROUP BY 1, 2"
+//     details: "Too many detached parts in {object} (count: {value})"
+//     thresholds:
+//   - {min: 10, severity: moderate}
+//   - {min: 50, severity: major}
+//   - {min: 100, severity: critical}
+type CustomAuditCheck struct {
+	ID         string                 `yaml:"id"`
+	Name       string                 `yaml:"name"`
+	Query      string                 `yaml:"query"`
+	Details    string                 `yaml:"details"`
+	Thresholds []CustomAuditThreshold `yaml:"thresholds"`
+}
+
+// CustomAuditThreshold maps a minimal value to a severity (critical, major, moderate, minor)
+type CustomAuditThreshold struct {
+	Min      float64 `yaml:"min"`
+	Severity string  `yaml:"severity"`
+}
+
+// loadCustomAuditChecks reads user-defined checks from
+// ~/.clickhouse-timeline/audit-checks.yaml, a missing file is not an error
+func loadCustomAuditChecks() []CustomAuditCheck {
+	home, homeErr := os.UserHomeDir()
+	if homeErr != nil {
+		log.Error().Err(homeErr).Msg("can't detect home directory for custom audit checks")
+		return nil
+	}
+	path := filepath.Join(home, ".clickhouse-timeline", "audit-checks.yaml")
+	data, readErr := os.ReadFile(path)
+	if readErr != nil {
+		if !os.IsNotExist(readErr) {
+			log.Error().Err(readErr).Str("path", path).Msg("can't read custom audit checks")
+		}
+		return nil
+	}
+
+	var checks []CustomAuditCheck
+	if unmarshalErr := yaml.Unmarshal(data, &checks); unmarshalErr != nil {
+		log.Error().Err(unmarshalErr).Str("path", path).Msg("can't parse custom audit checks")
+		return nil
+	}
+	return checks
+}
+
+// severityFor returns the severity of the highest threshold the value reached,
+// normalized to the severities used by the built-in checks, or "" if none matched
+func (c CustomAuditCheck) severityFor(value float64) string {
+	matched := false
+	var bestMin float64
+	var best string
+	for _, t := range c.Thresholds {
+		if value >= t.Min && (!matched || t.Min > bestMin) {
+			matched = true
+			bestMin = t.Min
+			best = t.Severity
+		}
+	}
+	if !matched {
+		return ""
+	}
+	switch strings.ToLower(best) {
+	case "critical":
+		return "Critical"
+	case "major":
+		return "Major"
+	case "moderate":
+		return "Moderate"
+	case "minor":
+		return "Minor"
+	}
+	return best
+}
+
+// runCustomCheck executes one user-defined check and converts its rows into
+// Results, so custom checks participate in progress reporting, sorting
+// and the detail view exactly like the built-in ones
+func (a *Auditor) runCustomCheck(check CustomAuditCheck) []Result {
+	var results []Result
+
+	query := strings.ReplaceAll(check.Query, "{cluster}", chquery.EscapeString(a.cluster))
+	rows, err := a.query(query)
+	if err != nil {
+		log.Error().Err(err).Str("id", check.ID).Msg("custom audit check query failed")
+		return results
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close runCustomCheck rows")
+		}
+	}()
+
+	for rows.Next() {
+		var host, object string
+		var value float64
+		if scanErr := rows.Scan(&host, &object, &value); scanErr != nil {
+			log.Error().Err(scanErr).Str("id", check.ID).Msg("can't scan custom audit check row, expected (host, object, value) columns")
+			continue
+		}
+
+		severity := check.severityFor(value)
+		if severity == "" {
+			continue
+		}
+
+		details := check.Details
+		if details == "" {
+			details = check.Name
+		}
+		details = strings.ReplaceAll(details, "{object}", object)
+		details = strings.ReplaceAll(details, "{value}", fmt.Sprintf("%g", value))
+
+		results = append(results, Result{
+			ID:       check.ID,
+			Host:     host,
+			Object:   object,
+			Severity: severity,
+			Details:  details,
+			Values:   map[string]float64{"value": value},
+		})
+	}
+
+	return results
+}
@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"github.com/Slach/clickhouse-timeline/pkg/config"
+)
+
+// applyAuditOverrides re-evaluates the severity of findings whose check ID has
+// configured threshold overrides for the current cluster. Findings that don't
+// reach any overridden threshold are dropped, so clusters with legitimately
+// high baseline values stop producing noise.
+func (a *Auditor) applyAuditOverrides(results []Result) []Result {
+	if a.cfg == nil || len(a.cfg.AuditOverrides) == 0 {
+		return results
+	}
+
+	kept := make([]Result, 0, len(results))
+	for _, result := range results {
+		override, found := a.findAuditOverride(result.ID)
+		if !found {
+			kept = append(kept, result)
+			continue
+		}
+		severity := override.severityFor(Impact(result))
+		if severity == "" {
+			continue
+		}
+		result.Severity = severity
+		kept = append(kept, result)
+	}
+	return kept
+}
+
+// findAuditOverride returns the override for a check ID, preferring a
+// cluster-specific entry over a catch-all one
+func (a *Auditor) findAuditOverride(id string) (CustomAuditCheck, bool) {
+	var catchAll *config.AuditOverride
+	for i, override := range a.cfg.AuditOverrides {
+		if override.ID != id {
+			continue
+		}
+		if override.Cluster == a.cluster {
+			return overrideAsCheck(override), true
+		}
+		if override.Cluster == "" && catchAll == nil {
+			catchAll = &a.cfg.AuditOverrides[i]
+		}
+	}
+	if catchAll != nil {
+		return overrideAsCheck(*catchAll), true
+	}
+	return CustomAuditCheck{}, false
+}
+
+// overrideAsCheck converts config thresholds into the custom-check form so
+// severityFor can be reused
+func overrideAsCheck(override config.AuditOverride) CustomAuditCheck {
+	check := CustomAuditCheck{ID: override.ID}
+	for _, t := range override.Thresholds {
+		check.Thresholds = append(check.Thresholds, CustomAuditThreshold{Min: t.Min, Severity: t.Severity})
+	}
+	return check
+}
@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"sort"
+	"strings"
+)
+
+// remediationHints maps finding ID prefixes to a remediation template shown
+// in the detail view; templates use <placeholders> the operator fills in, so
+// they stay copy-paste safe. A check can still set a more specific
+// Result.Remediation itself, these fill the gaps by longest matching prefix.
+var remediationHints = map[string]string{
+	"A0.1": "-- Too many parts/partitions usually means a too granular PARTITION BY:\n" +
+		"ALTER TABLE <db>.<table> MODIFY TTL <date_column> + INTERVAL 90 DAY;\n" +
+		"-- or re-create the table with e.g. PARTITION BY toYYYYMM(<date_column>)",
+	"A0.2": "-- Bound system log growth with a TTL per log table:\n" +
+		"ALTER TABLE system.<log_table> MODIFY TTL event_date + INTERVAL 14 DAY;",
+	"A1.1": "-- Repartition with a coarser key, e.g. monthly instead of daily:\n" +
+		"-- PARTITION BY toYYYYMM(<date_column>) in the table definition",
+	"A1.2": "-- Increase the mark cache if the working set doesn't fit:\n" +
+		"-- in config.xml: <mark_cache_size>10737418240</mark_cache_size>",
+	"A1.4": "-- Raise the background pool if merges/fetches queue up:\n" +
+		"-- in config.xml: <background_pool_size>32</background_pool_size>",
+	"A1.5": "-- Tune the uncompressed cache only for workloads with hot small reads:\n" +
+		"-- <uncompressed_cache_size>17179869184</uncompressed_cache_size>",
+	"A1.6": "-- Inspect stuck replication queue entries:\n" +
+		"SELECT database, table, type, last_exception FROM system.replication_queue\n" +
+		"WHERE num_tries > 10 ORDER BY num_tries DESC LIMIT 20;\n" +
+		"-- then SYSTEM RESTART REPLICA <db>.<table>; if an entry is wedged",
+	"A1.7": "-- Cap per-query memory so one query can't evict everything:\n" +
+		"ALTER USER <user> SETTINGS max_memory_usage = 10000000000;",
+	"A1.8": "-- Free disk by dropping obsolete partitions or moving cold data:\n" +
+		"ALTER TABLE <db>.<table> DROP PARTITION '<partition_id>';\n" +
+		"-- or MODIFY TTL <date_column> + INTERVAL 30 DAY TO VOLUME 'cold'",
+	"A1.8.03": "-- Grow the filesystem cache in front of object storage:\n" +
+		"-- in the disk config: <max_size>100Gi</max_size>, then\n" +
+		"SYSTEM DROP FILESYSTEM CACHE; -- only to reset statistics, not in production hours",
+	"A1.8.04": "-- Inspect and drop detached parts that keep paying for dead s3 objects:\n" +
+		"SELECT database, table, name, reason FROM system.detached_parts WHERE disk = '<disk>';\n" +
+		"ALTER TABLE <db>.<table> DROP DETACHED PART '<part_name>' SETTINGS allow_drop_detached = 1;",
+	"A1.9": "-- Inspect stuck mutations and their failure reason:\n" +
+		"SELECT database, table, mutation_id, parts_to_do, latest_fail_reason\n" +
+		"FROM system.mutations WHERE NOT is_done;\n" +
+		"KILL MUTATION WHERE mutation_id = '<mutation_id>'; -- only if it can be abandoned",
+	"A2.2": "-- Broken materialized views silently drop inserted data:\n" +
+		"DETACH TABLE <db>.<mv_name>; ATTACH TABLE <db>.<mv_name>;\n" +
+		"-- check the target table exists and the SELECT still type-checks",
+	"A2.4": "-- Over-granular primary keys inflate marks; rebuild with a shorter key:\n" +
+		"-- ORDER BY (<low_cardinality_cols>, <time_column>) in the table definition",
+	"A5.0": "-- Lock down login and resource limits for interactive users:\n" +
+		"ALTER USER <user> IDENTIFIED WITH sha256_password BY '<password>';\n" +
+		"CREATE QUOTA <quota> FOR INTERVAL 1 hour MAX queries = 1000 TO <user>;\n" +
+		"ALTER USER <user> SETTINGS readonly = 1 CONST; -- pin it so SET can't undo it",
+	"A4.0": "-- Schedule regular backups to durable storage, e.g. native BACKUP:\n" +
+		"BACKUP DATABASE <db> TO Disk('backups', '<name>.zip');\n" +
+		"-- or run clickhouse-backup with a watch interval: clickhouse-backup watch",
+	"A3.0": "-- Compare the offending metric across replicas first:\n" +
+		"SELECT hostName(), metric, value FROM clusterAllReplicas('<cluster>', system.metrics)\n" +
+		"WHERE metric = '<metric>' ORDER BY value DESC;",
+}
+
+// remediationFor returns the template of the longest ID prefix matching the
+// finding, or "" when no hint is registered
+func remediationFor(id string) string {
+	prefixes := make([]string, 0, len(remediationHints))
+	for prefix := range remediationHints {
+		prefixes = append(prefixes, prefix)
+	}
+	// longest prefix first so A1.5.01 style IDs prefer the most specific hint
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(id, prefix) {
+			return remediationHints[prefix]
+		}
+	}
+	return ""
+}
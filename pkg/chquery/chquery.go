@@ -0,0 +1,219 @@
+// Package chquery centralizes building SQL fragments from runtime-provided
+// identifiers (database, table and column names picked in the UI or passed on
+// the command line). Values always travel as bound parameters; identifiers
+// can't be bound, so they are backtick-quoted with escaping here instead of
+// being interpolated raw, keeping hostile names from breaking out of a query.
+package chquery
+
+import (
+	"regexp"
+	"strings"
+)
+
+var identifierRegexp = regexp.MustCompile(`^[a-zA-Z_][0-9a-zA-Z_]*$`)
+
+// ValidIdentifier reports whether name is a plain unquoted ClickHouse
+// identifier (letters, digits and underscores, not starting with a digit)
+func ValidIdentifier(name string) bool {
+	return identifierRegexp.MatchString(name)
+}
+
+// QuoteID wraps an identifier in backticks, escaping backslashes and
+// backticks so the identifier can't terminate the quoting
+func QuoteID(name string) string {
+	escaped := strings.ReplaceAll(name, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, "`", "\\`")
+	return "`" + escaped + "`"
+}
+
+// QualifiedName renders a quoted `database`.`table` reference
+func QualifiedName(database, table string) string {
+	return QuoteID(database) + "." + QuoteID(table)
+}
+
+// Table functions accepted as ad-hoc FROM sources instead of a
+// database-qualified table name
+var tableFunctionRegexp = regexp.MustCompile(`^(remote|remoteSecure|s3|s3Cluster|url)\s*\(.*\)$`)
+
+// IsTableFunction reports whether expr is an ad-hoc table function source
+// like remote('host:9000', db.table) or s3('https://...', 'CSV')
+func IsTableFunction(expr string) bool {
+	return tableFunctionRegexp.MatchString(strings.TrimSpace(expr))
+}
+
+// TableExpr renders a FROM source: the table function expression verbatim
+// when table is one (the database is ignored then), a quoted
+// `database`.`table` reference otherwise
+func TableExpr(database, table string) string {
+	if IsTableFunction(table) {
+		return strings.TrimSpace(table)
+	}
+	return QualifiedName(database, table)
+}
+
+// EscapeString escapes a value for embedding into a single-quoted SQL string
+// literal, for the few places where a bound parameter can't be used (e.g.
+// cluster names inside cluster() table functions)
+func EscapeString(value string) string {
+	escaped := strings.ReplaceAll(value, `\`, `\\`)
+	return strings.ReplaceAll(escaped, `'`, `\'`)
+}
+
+// splitTopLevelArgs splits a table function argument list at the commas that
+// sit outside single-quoted literals and nested parentheses
+func splitTopLevelArgs(argList string) []string {
+	var args []string
+	depth := 0
+	inQuote := false
+	start := 0
+	for i := 0; i < len(argList); i++ {
+		switch argList[i] {
+		case '\\':
+			if inQuote {
+				i++
+			}
+		case '\'':
+			inQuote = !inQuote
+		case '(':
+			if !inQuote {
+				depth++
+			}
+		case ')':
+			if !inQuote {
+				depth--
+			}
+		case ',':
+			if !inQuote && depth == 0 {
+				args = append(args, argList[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(args, argList[start:])
+}
+
+// matchingParen returns the index of the parenthesis closing the one at open,
+// skipping parentheses inside single-quoted literals, or -1 when unbalanced
+func matchingParen(s string, open int) int {
+	depth := 0
+	inQuote := false
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if inQuote {
+				i++
+			}
+		case '\'':
+			inQuote = !inQuote
+		case '(':
+			if !inQuote {
+				depth++
+			}
+		case ')':
+			if !inQuote {
+				depth--
+				if depth == 0 {
+					return i
+				}
+			}
+		}
+	}
+	return -1
+}
+
+var remoteFunctionRegexp = regexp.MustCompile(`^(remote|remoteSecure)\s*\(`)
+
+// InjectRemoteCredentials appends user and password arguments to a
+// credential-less remote()/remoteSecure() expression, so an ad-hoc remote
+// source reuses the active connection's login instead of requiring it inline.
+// The expression is returned unchanged when user is empty, when it isn't a
+// remote function, or when its last argument is already a quoted literal
+// (taken as credentials being present; remote('h', 'db', 'table') is skipped
+// too, which errs on the side of never appending a password twice).
+func InjectRemoteCredentials(expr, user, password string) string {
+	trimmed := strings.TrimSpace(expr)
+	if user == "" || !remoteFunctionRegexp.MatchString(trimmed) || !strings.HasSuffix(trimmed, ")") {
+		return expr
+	}
+	open := strings.Index(trimmed, "(")
+	inner := trimmed[open+1 : len(trimmed)-1]
+	args := splitTopLevelArgs(inner)
+	if len(args) < 2 || strings.HasPrefix(strings.TrimSpace(args[len(args)-1]), "'") {
+		return expr
+	}
+	injected := inner + ", '" + EscapeString(user) + "'"
+	if password != "" {
+		injected += ", '" + EscapeString(password) + "'"
+	}
+	return trimmed[:open+1] + injected + ")"
+}
+
+// Table functions whose arguments can carry credentials
+var credentialFunctionRegexp = regexp.MustCompile(`\b(remote|remoteSecure|s3|s3Cluster)\s*\(`)
+
+// redactCredentialArgs blanks the arguments of one call that may hold
+// credentials, reporting whether anything changed. Argument positions are
+// ambiguous across the overloaded signatures, so the rules deliberately
+// over-redact rather than risk journaling a password: remote/remoteSecure
+// lose every quoted argument after the address, s3 its 2nd and 3rd argument
+// (key id and secret) when 4+ are given, s3Cluster the 3rd and 4th when 5+.
+func redactCredentialArgs(name string, args []string) bool {
+	for i := range args {
+		args[i] = strings.TrimSpace(args[i])
+	}
+	changed := false
+	redact := func(i int) {
+		if strings.HasPrefix(args[i], "'") {
+			args[i] = "'***'"
+			changed = true
+		}
+	}
+	switch name {
+	case "remote", "remoteSecure":
+		for i := 1; i < len(args); i++ {
+			redact(i)
+		}
+	case "s3":
+		if len(args) >= 4 {
+			redact(1)
+			redact(2)
+		}
+	case "s3Cluster":
+		if len(args) >= 5 {
+			redact(2)
+			redact(3)
+		}
+	}
+	return changed
+}
+
+// RedactTableFunctionCredentials replaces credential arguments of remote,
+// remoteSecure, s3 and s3Cluster calls in query with '***', so executed SQL
+// can be journaled without writing passwords or access keys to disk
+func RedactTableFunctionCredentials(query string) string {
+	var builder strings.Builder
+	last := 0
+	for _, loc := range credentialFunctionRegexp.FindAllStringSubmatchIndex(query, -1) {
+		if loc[0] < last {
+			continue
+		}
+		open := loc[1] - 1
+		closing := matchingParen(query, open)
+		if closing < 0 {
+			continue
+		}
+		args := splitTopLevelArgs(query[open+1 : closing])
+		if !redactCredentialArgs(query[loc[2]:loc[3]], args) {
+			continue
+		}
+		builder.WriteString(query[last : open+1])
+		builder.WriteString(strings.Join(args, ", "))
+		builder.WriteString(")")
+		last = closing + 1
+	}
+	if last == 0 {
+		return query
+	}
+	builder.WriteString(query[last:])
+	return builder.String()
+}
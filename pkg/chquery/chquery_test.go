@@ -0,0 +1,138 @@
+package chquery
+
+import "testing"
+
+func TestValidIdentifier(t *testing.T) {
+	valid := []string{"text_log", "_internal", "Table1"}
+	for _, name := range valid {
+		if !ValidIdentifier(name) {
+			t.Errorf("expected '%s' to be a valid identifier", name)
+		}
+	}
+	invalid := []string{"", "1table", "bad-name", "evil`; DROP TABLE x", "with space"}
+	for _, name := range invalid {
+		if ValidIdentifier(name) {
+			t.Errorf("expected '%s' to be an invalid identifier", name)
+		}
+	}
+}
+
+func TestQuoteID(t *testing.T) {
+	cases := map[string]string{
+		"text_log":      "`text_log`",
+		"evil`.`system": "`evil\\`.\\`system`",
+		`back\slash`:    "`back\\\\slash`",
+	}
+	for input, expected := range cases {
+		if actual := QuoteID(input); actual != expected {
+			t.Errorf("QuoteID(%q) = %s, expected %s", input, actual, expected)
+		}
+	}
+}
+
+func TestQualifiedName(t *testing.T) {
+	if actual := QualifiedName("system", "text_log"); actual != "`system`.`text_log`" {
+		t.Errorf("unexpected qualified name: %s", actual)
+	}
+}
+
+func TestIsTableFunction(t *testing.T) {
+	matching := []string{
+		"remote('host:9000', system.text_log)",
+		"remoteSecure('host:9440', system.text_log, 'user', 'pass')",
+		"s3('https://bucket.s3.amazonaws.com/logs/*.parquet', 'Parquet')",
+		"  url('https://example.com/data.csv', 'CSV')  ",
+	}
+	for _, expr := range matching {
+		if !IsTableFunction(expr) {
+			t.Errorf("expected '%s' to be recognized as a table function", expr)
+		}
+	}
+	notMatching := []string{"", "text_log", "system.text_log", "remote", "myremote('host', t)"}
+	for _, expr := range notMatching {
+		if IsTableFunction(expr) {
+			t.Errorf("expected '%s' not to be recognized as a table function", expr)
+		}
+	}
+}
+
+func TestTableExpr(t *testing.T) {
+	if actual := TableExpr("system", "text_log"); actual != "`system`.`text_log`" {
+		t.Errorf("unexpected table expr: %s", actual)
+	}
+	fn := "remote('host:9000', system.text_log)"
+	if actual := TableExpr("ignored", fn); actual != fn {
+		t.Errorf("unexpected table expr: %s", actual)
+	}
+}
+
+func TestInjectRemoteCredentials(t *testing.T) {
+	cases := []struct {
+		expr, user, password, expected string
+	}{
+		{
+			"remote('host:9000', system.text_log)", "reader", "secret",
+			"remote('host:9000', system.text_log, 'reader', 'secret')",
+		},
+		{
+			"remoteSecure('host:9440', db.logs)", "reader", "",
+			"remoteSecure('host:9440', db.logs, 'reader')",
+		},
+		// already carries credentials, left alone
+		{
+			"remote('host:9000', system.text_log, 'user', 'pass')", "reader", "secret",
+			"remote('host:9000', system.text_log, 'user', 'pass')",
+		},
+		// no user configured, nothing to inject
+		{
+			"remote('host:9000', system.text_log)", "", "secret",
+			"remote('host:9000', system.text_log)",
+		},
+		// not a remote function
+		{
+			"s3('https://bucket/logs/*.parquet', 'Parquet')", "reader", "secret",
+			"s3('https://bucket/logs/*.parquet', 'Parquet')",
+		},
+		// quotes in credentials stay inside their literals
+		{
+			"remote('host:9000', system.text_log)", "o'reader", `se\cret`,
+			`remote('host:9000', system.text_log, 'o\'reader', 'se\\cret')`,
+		},
+	}
+	for _, c := range cases {
+		if actual := InjectRemoteCredentials(c.expr, c.user, c.password); actual != c.expected {
+			t.Errorf("InjectRemoteCredentials(%q, %q, ...) = %s, expected %s", c.expr, c.user, actual, c.expected)
+		}
+	}
+}
+
+func TestRedactTableFunctionCredentials(t *testing.T) {
+	cases := map[string]string{
+		"SELECT * FROM remote('host:9000', system.text_log, 'user', 'pass') WHERE level='error'":   "SELECT * FROM remote('host:9000', system.text_log, '***', '***') WHERE level='error'",
+		"SELECT count() FROM remoteSecure('host:9440', db.logs, 'user') GROUP BY level":            "SELECT count() FROM remoteSecure('host:9440', db.logs, '***') GROUP BY level",
+		"SELECT * FROM s3('https://bucket/*.parquet', 'AKIAKEY', 'wJalrSECRET', 'Parquet')":        "SELECT * FROM s3('https://bucket/*.parquet', '***', '***', 'Parquet')",
+		"SELECT * FROM s3Cluster('main', 'https://bucket/*.csv', 'AKIAKEY', 'wJalrSECRET', 'CSV')": "SELECT * FROM s3Cluster('main', 'https://bucket/*.csv', '***', '***', 'CSV')",
+		// credential-less calls stay byte-identical
+		"SELECT * FROM remote('host:9000', system.text_log)":           "SELECT * FROM remote('host:9000', system.text_log)",
+		"SELECT * FROM s3('https://bucket/*.parquet', 'Parquet')":      "SELECT * FROM s3('https://bucket/*.parquet', 'Parquet')",
+		"SELECT name FROM system.columns WHERE database=? AND table=?": "SELECT name FROM system.columns WHERE database=? AND table=?",
+	}
+	for input, expected := range cases {
+		if actual := RedactTableFunctionCredentials(input); actual != expected {
+			t.Errorf("RedactTableFunctionCredentials(%q) = %s, expected %s", input, actual, expected)
+		}
+	}
+}
+
+func TestEscapeString(t *testing.T) {
+	cases := map[string]string{
+		"default":        "default",
+		"bad'cluster":    `bad\'cluster`,
+		`back\slash'mix`: `back\\slash\'mix`,
+	}
+	for input, expected := range cases {
+		if actual := EscapeString(input); actual != expected {
+			t.Errorf("EscapeString(%q) = %s, expected %s", input, actual, expected)
+		}
+	}
+}
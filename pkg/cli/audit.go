@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Slach/clickhouse-timeline/pkg/audit"
+	"github.com/Slach/clickhouse-timeline/pkg/client"
+	"github.com/Slach/clickhouse-timeline/pkg/config"
+	"github.com/Slach/clickhouse-timeline/pkg/formats"
+	"github.com/Slach/clickhouse-timeline/pkg/types"
+	"github.com/rs/zerolog/log"
+)
+
+// RunHeadlessAudit executes all audit checks without the tview UI and dumps
+// AuditResult records in a machine-readable format, so audits can run in
+// CI/cron and results can be diffed between runs.
+func RunHeadlessAudit(cliInstance *types.CLI, version string) error {
+	home, homeErr := os.UserHomeDir()
+	if homeErr != nil {
+		return homeErr
+	}
+	home = filepath.Join(home, ".clickhouse-timeline")
+
+	cfg, configErr := config.Load(cliInstance, home)
+	if configErr != nil {
+		return configErr
+	}
+
+	if cliInstance.ConnectTo == "" {
+		return fmt.Errorf("headless audit requires --connect with a context name from config")
+	}
+	if cliInstance.Cluster == "" {
+		return fmt.Errorf("headless audit requires --cluster")
+	}
+
+	// --connect accepts a comma-separated list of context names so a fleet of
+	// clusters can be audited in one invocation; with several contexts each
+	// finding is relabeled by context name so the combined set stays attributable
+	contextNames := strings.Split(cliInstance.ConnectTo, ",")
+	multiCluster := len(contextNames) > 1
+
+	var results []audit.Result
+	var serverVersion string
+	for _, rawName := range contextNames {
+		contextName := strings.TrimSpace(rawName)
+		if contextName == "" {
+			continue
+		}
+		label := ""
+		if multiCluster {
+			label = contextName
+		}
+		contextResults, contextVersion, auditErr := auditOneContext(cliInstance, cfg, contextName, label, version)
+		if auditErr != nil {
+			return auditErr
+		}
+		if serverVersion == "" {
+			serverVersion = contextVersion
+		}
+		if multiCluster {
+			for i := range contextResults {
+				contextResults[i].Cluster = contextName
+			}
+		}
+		results = append(results, contextResults...)
+	}
+	audit.SortResults(results)
+
+	baselineMode := cliInstance.AuditBaseline != ""
+	if baselineMode {
+		baseline, baselineErr := loadAuditBaseline(cliInstance.AuditBaseline)
+		if baselineErr != nil {
+			return baselineErr
+		}
+		results = diffAgainstBaseline(results, baseline)
+	}
+
+	output := os.Stdout
+	if cliInstance.AuditFile != "" {
+		file, createErr := os.Create(cliInstance.AuditFile)
+		if createErr != nil {
+			return createErr
+		}
+		defer func() {
+			if closeErr := file.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close audit output file")
+			}
+		}()
+		output = file
+	}
+
+	if cliInstance.AuditOutput == "html" {
+		if reportErr := writeAuditHTMLReport(output, cliInstance.Cluster, serverVersion, results); reportErr != nil {
+			return reportErr
+		}
+	} else {
+		rowWriter, writerErr := formats.New(cliInstance.AuditOutput, output)
+		if writerErr != nil {
+			return writerErr
+		}
+		if headerErr := rowWriter.WriteHeader([]string{"id", "cluster", "host", "severity", "object", "details", "values"}); headerErr != nil {
+			return headerErr
+		}
+		for _, result := range results {
+			values, marshalErr := json.Marshal(result.Values)
+			if marshalErr != nil {
+				return marshalErr
+			}
+			if rowErr := rowWriter.WriteRow([]interface{}{result.ID, result.Cluster, result.Host, result.Severity, result.Object, result.Details, string(values)}); rowErr != nil {
+				return rowErr
+			}
+		}
+		if closeErr := rowWriter.Close(); closeErr != nil {
+			return closeErr
+		}
+	}
+
+	if baselineMode {
+		if len(results) > 0 {
+			return fmt.Errorf("%d new or worsened findings compared to baseline %s", len(results), cliInstance.AuditBaseline)
+		}
+		fmt.Fprintf(os.Stderr, "Audit completed: no new or worsened findings compared to baseline\n")
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "Audit completed: %d issues found\n", len(results))
+	return nil
+}
+
+// auditOneContext connects to one configured context, runs the checks against
+// it and returns the findings together with the server version; label, when
+// set, prefixes the progress lines so parallel fleet output stays readable
+func auditOneContext(cliInstance *types.CLI, cfg *config.Config, contextName, label, version string) ([]audit.Result, string, error) {
+	var selectedContext *config.Context
+	for i, ctx := range cfg.Contexts {
+		if ctx.Name == contextName {
+			selectedContext = &cfg.Contexts[i]
+			break
+		}
+	}
+	if selectedContext == nil {
+		return nil, "", fmt.Errorf("context '%s' not found in config", contextName)
+	}
+
+	clickHouse := client.NewClient(*selectedContext, version)
+	defer func() {
+		if closeErr := clickHouse.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close clickhouse connection")
+		}
+	}()
+	serverVersion, versionErr := clickHouse.GetVersion()
+	if versionErr != nil {
+		return nil, "", fmt.Errorf("can't connect to %s:%d: %v", selectedContext.Host, selectedContext.Port, versionErr)
+	}
+
+	auditor := audit.NewAuditor(clickHouse, cliInstance.Cluster, cfg).WithBackupAPI(selectedContext.BackupAPIURL)
+	progress := func(name string, step, total int) {
+		if label != "" {
+			fmt.Fprintf(os.Stderr, "[%s] [%d/%d] Running %s checks...\n", label, step+1, total, name)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "[%d/%d] Running %s checks...\n", step+1, total, name)
+	}
+	if cliInstance.AuditCheck != "" {
+		results, checkErr := auditor.RunChecksFiltered(cliInstance.AuditCheck, progress)
+		if checkErr != nil {
+			return nil, "", checkErr
+		}
+		return results, serverVersion, nil
+	}
+	return auditor.RunChecks(progress), serverVersion, nil
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Slach/clickhouse-timeline/pkg/audit"
+)
+
+// auditSeverityRank orders severities so a lower rank means a worse finding
+var auditSeverityRank = map[string]int{
+	"Critical": 0,
+	"Major":    1,
+	"Moderate": 2,
+	"Minor":    3,
+}
+
+// baselineRecord is the subset of an audit result row we need from a previous
+// run saved with `audit --output json --file previous.json`
+type baselineRecord struct {
+	ID       string `json:"id"`
+	Host     string `json:"host"`
+	Severity string `json:"severity"`
+	Object   string `json:"object"`
+}
+
+// loadAuditBaseline reads a previous JSON audit run and indexes the worst
+// severity seen per (id, host, object)
+func loadAuditBaseline(path string) (map[string]string, error) {
+	data, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return nil, fmt.Errorf("can't read baseline %s: %v", path, readErr)
+	}
+	var records []baselineRecord
+	if unmarshalErr := json.Unmarshal(data, &records); unmarshalErr != nil {
+		return nil, fmt.Errorf("can't parse baseline %s (expected JSON from `audit --output json`): %v", path, unmarshalErr)
+	}
+	baseline := make(map[string]string, len(records))
+	for _, record := range records {
+		key := auditFindingKey(record.ID, record.Host, record.Object)
+		if existing, found := baseline[key]; !found || auditSeverityRank[record.Severity] < auditSeverityRank[existing] {
+			baseline[key] = record.Severity
+		}
+	}
+	return baseline, nil
+}
+
+// diffAgainstBaseline keeps only findings that are absent from the baseline or
+// have a worse severity than the baseline recorded for them
+func diffAgainstBaseline(results []audit.Result, baseline map[string]string) []audit.Result {
+	changed := make([]audit.Result, 0)
+	for _, result := range results {
+		previousSeverity, found := baseline[auditFindingKey(result.ID, result.Host, result.Object)]
+		if !found || auditSeverityRank[result.Severity] < auditSeverityRank[previousSeverity] {
+			changed = append(changed, result)
+		}
+	}
+	return changed
+}
+
+func auditFindingKey(id, host, object string) string {
+	return id + "\x00" + host + "\x00" + object
+}
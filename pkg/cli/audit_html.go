@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/audit"
+)
+
+// auditReportTemplate renders findings grouped by severity with inline bar
+// charts of each finding's values, so a report can be handed over without
+// terminal screenshots; everything is inlined, the file has no dependencies
+const auditReportTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>ClickHouse audit report {{.Cluster}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.4em; } h2 { font-size: 1.1em; margin-top: 1.5em; }
+table { border-collapse: collapse; width: 100%; margin-top: 0.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; font-size: 0.9em; }
+th { background: #f0f0f0; }
+.summary-bar { display: inline-block; height: 1em; margin-right: 0.5em; vertical-align: middle; }
+.Critical { background: #c0392b; } .Major { background: #e67e22; }
+.Moderate { background: #f1c40f; } .Minor { background: #27ae60; }
+.value-bar { display: inline-block; height: 0.7em; background: #3498db; margin-right: 0.3em; vertical-align: middle; }
+.value-row { white-space: nowrap; font-size: 0.85em; }
+.remediation { font-family: monospace; white-space: pre-wrap; color: #555; }
+footer { margin-top: 2em; font-size: 0.8em; color: #888; }
+</style>
+</head>
+<body>
+<h1>ClickHouse audit report — cluster {{.Cluster}}</h1>
+<p>Generated {{.Generated}} by clickhouse-timeline {{.Version}}. {{.Total}} findings.</p>
+
+<h2>Summary</h2>
+<table>
+{{range .Summary}}<tr>
+<td>{{.Severity}}</td>
+<td style="width: 60%"><span class="summary-bar {{.Severity}}" style="width: {{.Width}}px"></span>{{.Count}}</td>
+</tr>{{end}}
+</table>
+
+{{range .Sections}}
+<h2>{{.Severity}} ({{len .Findings}})</h2>
+<table>
+<tr><th>ID</th><th>Cluster</th><th>Host</th><th>Object</th><th>Details</th><th>Values</th><th>Remediation</th></tr>
+{{range .Findings}}<tr>
+<td>{{.ID}}</td>
+<td>{{.Cluster}}</td>
+<td>{{.Host}}</td>
+<td>{{.Object}}</td>
+<td>{{.Details}}</td>
+<td>{{range .Values}}<div class="value-row"><span class="value-bar" style="width: {{.Width}}px"></span>{{.Name}}: {{.Value}}</div>{{end}}</td>
+<td class="remediation">{{.Remediation}}</td>
+</tr>{{end}}
+</table>
+{{end}}
+
+<footer>Severity order: Critical, Major, Moderate, Minor. Value bars are scaled per finding.</footer>
+</body>
+</html>
+`
+
+// severityOrder fixes the section and summary ordering of the HTML report
+var severityOrder = []string{"Critical", "Major", "Moderate", "Minor"}
+
+type auditReportValue struct {
+	Name  string
+	Value float64
+	Width int
+}
+
+type auditReportFinding struct {
+	ID, Cluster, Host, Object, Details, Remediation string
+	Values                                          []auditReportValue
+}
+
+type auditReportSection struct {
+	Severity string
+	Findings []auditReportFinding
+}
+
+type auditReportSummary struct {
+	Severity string
+	Count    int
+	Width    int
+}
+
+// writeAuditHTMLReport renders the findings as a standalone HTML report
+// grouped by severity, with per-finding value bars scaled to the finding's
+// largest value
+func writeAuditHTMLReport(w io.Writer, cluster, version string, results []audit.Result) error {
+	grouped := make(map[string][]auditReportFinding)
+	counts := make(map[string]int)
+	for _, result := range results {
+		finding := auditReportFinding{
+			ID:          result.ID,
+			Cluster:     result.Cluster,
+			Host:        result.Host,
+			Object:      result.Object,
+			Details:     result.Details,
+			Remediation: result.Remediation,
+		}
+
+		maxValue := 0.0
+		names := make([]string, 0, len(result.Values))
+		for name, value := range result.Values {
+			names = append(names, name)
+			if value > maxValue {
+				maxValue = value
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			width := 0
+			if maxValue > 0 {
+				width = int(result.Values[name] / maxValue * 100)
+			}
+			finding.Values = append(finding.Values, auditReportValue{Name: name, Value: result.Values[name], Width: width})
+		}
+
+		severity := result.Severity
+		grouped[severity] = append(grouped[severity], finding)
+		counts[severity]++
+	}
+
+	maxCount := 0
+	for _, count := range counts {
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+
+	data := struct {
+		Cluster, Version, Generated string
+		Total                       int
+		Summary                     []auditReportSummary
+		Sections                    []auditReportSection
+	}{
+		Cluster:   cluster,
+		Version:   version,
+		Generated: time.Now().Format("2006-01-02 15:04:05"),
+		Total:     len(results),
+	}
+	for _, severity := range severityOrder {
+		width := 0
+		if maxCount > 0 {
+			width = counts[severity] * 300 / maxCount
+		}
+		data.Summary = append(data.Summary, auditReportSummary{Severity: severity, Count: counts[severity], Width: width})
+		if len(grouped[severity]) > 0 {
+			data.Sections = append(data.Sections, auditReportSection{Severity: severity, Findings: grouped[severity]})
+		}
+	}
+
+	report, parseErr := template.New("audit").Parse(auditReportTemplate)
+	if parseErr != nil {
+		return parseErr
+	}
+	return report.Execute(w, data)
+}
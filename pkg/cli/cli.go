@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Slach/clickhouse-timeline/pkg/config"
+	"github.com/Slach/clickhouse-timeline/pkg/formats"
+	"github.com/Slach/clickhouse-timeline/pkg/logging"
+	"github.com/Slach/clickhouse-timeline/pkg/pprof"
+	"github.com/rs/zerolog/log"
+
+	"github.com/Slach/clickhouse-timeline/pkg/tui"
+	"github.com/Slach/clickhouse-timeline/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func NewRootCommand(cli *types.CLI, version string) *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "clickhouse-timeline",
+		Short: "ClickHouse Timeline - interactive performance analysis tool",
+	}
+	rootCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return RunRootCommand(cli, version, cmd, args)
+	}
+
+	// Add global flags
+	rootCmd.PersistentFlags().StringVar(&cli.ConfigPath, "config", "", "Path to config file (default: ~/.clickhouse-timeline/clickhouse-timeline.yml)")
+	rootCmd.PersistentFlags().StringVar(&cli.LogPath, "log", "", "Path to log file (default: ~/.clickhouse-timeline/clickhouse-timeline.log)")
+	rootCmd.PersistentFlags().BoolVar(&cli.Pprof, "pprof", false, "Enable CPU and memory profiling")
+	rootCmd.PersistentFlags().StringVar(&cli.PprofPath, "pprof-path", "", "Path to store pprof files (default: ~/.clickhouse-timeline/)")
+	rootCmd.PersistentFlags().StringVar(&cli.FromTime, "from", "", "Start time: relative like '-2h', 'yesterday 09:00', or any parsable absolute format (see https://github.com/araddon/dateparse)")
+	rootCmd.PersistentFlags().StringVar(&cli.ToTime, "to", "", "End time: 'now', relative like '-30m', or any parsable absolute format (see https://github.com/araddon/dateparse)")
+	rootCmd.PersistentFlags().StringVar(&cli.RangeOption, "range", "", "Predefined time range (e.g. 1h, 24h, 7d)")
+	rootCmd.PersistentFlags().StringVar(&cli.ConnectTo, "connect", "", "ClickHouse connection context name from config (audit accepts a comma-separated list to combine several clusters)")
+	rootCmd.PersistentFlags().StringVar(&cli.ConnectTo, "context", "", "Alias for --connect")
+	rootCmd.PersistentFlags().StringVar(&cli.Cluster, "cluster", "", "Cluster name to analyze")
+	rootCmd.PersistentFlags().StringVar(&cli.Metric, "metric", "", "Metric to visualize (count, memoryUsage, cpuUsage, etc)")
+	rootCmd.PersistentFlags().StringVar(&cli.Category, "category", "", "Category to group by (query_hash, tables, hosts, errors)")
+	rootCmd.PersistentFlags().BoolVar(&cli.FlamegraphNative, "flamegraph-native", false, "Use native flamegraph viewer instead of flamelens")
+	rootCmd.PersistentFlags().BoolVar(&cli.SafeMode, "safe-mode", false, "Connect but defer every automatic query until explicitly requested (for already overloaded clusters)")
+	rootCmd.PersistentFlags().BoolVar(&cli.Resume, "resume", false, "Restore context, cluster, time range and heatmap selections from the previous session")
+	rootCmd.PersistentFlags().StringVar(&cli.Format, "format", "table", fmt.Sprintf("Output format for headless commands (%s)", strings.Join(formats.List(), ", ")))
+
+	heatmapCmd := &cobra.Command{
+		Use:   "heatmap",
+		Short: "Start in heatmap mode",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunSubCommand(cli, version, cmd, args)
+		},
+	}
+	rootCmd.AddCommand(heatmapCmd)
+
+	explainCmd := &cobra.Command{
+		Use:   "explain",
+		Short: "Start in explain mode",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunSubCommand(cli, version, cmd, args)
+		},
+	}
+	rootCmd.AddCommand(explainCmd)
+
+	flamegraphCmd := &cobra.Command{
+		Use:   "flamegraph",
+		Short: "Start in flamegraph mode",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunSubCommand(cli, version, cmd, args)
+		},
+	}
+	rootCmd.AddCommand(flamegraphCmd)
+
+	profileEventsCmd := &cobra.Command{
+		Use:   "profile_events",
+		Short: "Start in profile events mode",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunSubCommand(cli, version, cmd, args)
+		},
+	}
+	rootCmd.AddCommand(profileEventsCmd)
+
+	metricLogCmd := &cobra.Command{
+		Use:   "metric_log",
+		Short: "Start in metric_log mode",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunSubCommand(cli, version, cmd, args)
+		},
+	}
+	rootCmd.AddCommand(metricLogCmd)
+
+	asyncMetricLogCmd := &cobra.Command{
+		Use:   "asynchronous_metric_log",
+		Short: "Start in asynchronous_metric_log mode",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunSubCommand(cli, version, cmd, args)
+		},
+	}
+	rootCmd.AddCommand(asyncMetricLogCmd)
+
+	memoryCmd := &cobra.Command{
+		Use:   "memory",
+		Short: "Start memory viewer",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunSubCommand(cli, version, cmd, args)
+		},
+	}
+	rootCmd.AddCommand(memoryCmd)
+
+	logsCmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Start in logs mode (text_log, error_log, query_log, query_thread_log)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cli.LogsParams.Output != "" {
+				return RunHeadlessLogs(cli, version)
+			}
+			return RunSubCommand(cli, version, cmd, args)
+		},
+	}
+	logsCmd.Flags().StringVar(&cli.LogsParams.Database, "database", "", "Database which will use for look log")
+	logsCmd.Flags().StringVar(&cli.LogsParams.Table, "table", "", "Database which will use for look log")
+	logsCmd.Flags().StringVar(&cli.LogsParams.Message, "message", "", "Column name for message")
+	logsCmd.Flags().StringVar(&cli.LogsParams.Time, "time", "", "Column name for time")
+	logsCmd.Flags().StringVar(&cli.LogsParams.TimeMs, "time-ms", "", "Column name for time with milliseconds")
+	logsCmd.Flags().StringVar(&cli.LogsParams.Date, "date", "", "Column name for date")
+	logsCmd.Flags().StringVar(&cli.LogsParams.Level, "level", "", "Column name for message level")
+	logsCmd.Flags().IntVar(&cli.LogsParams.Window, "window", 1000, "Sliding window size in rows")
+	logsCmd.Flags().IntVar(&cli.LogsParams.MessageLength, "message-length", 0, "Maximum message length fetched for the list view, full message is fetched lazily in details (0 = no limit)")
+	logsCmd.Flags().StringArrayVar(&cli.LogsParams.ExtraSources, "extra-source", nil, "Additional database.table merged into the log stream by timestamp, can be repeated")
+	logsCmd.Flags().StringVar(&cli.LogsParams.Output, "output", "", "Stream logs headless (no TUI) to stdout in this format (json, jsonl, csv, ...)")
+	logsCmd.Flags().StringVar(&cli.LogsParams.Where, "where", "", "Extra SQL condition appended to the headless logs query, passed through verbatim")
+	logsCmd.Flags().IntVar(&cli.LogsParams.Limit, "limit", 0, "Maximum rows streamed in headless mode (0 = no limit)")
+	rootCmd.AddCommand(logsCmd)
+
+	genLogsCmd := &cobra.Command{
+		Use:    "gen-logs",
+		Short:  "Create a demo log table and keep inserting synthetic entries with bursts and error spikes",
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunGenLogs(cli, version)
+		},
+	}
+	genLogsCmd.Flags().StringVar(&cli.LogsParams.Database, "database", "default", "Database for the demo log table")
+	genLogsCmd.Flags().StringVar(&cli.LogsParams.Table, "table", "demo_logs", "Name of the demo log table")
+	rootCmd.AddCommand(genLogsCmd)
+
+	auditCmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Run system audit and show diagnostics",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cli.AuditOutput != "" || cli.AuditBaseline != "" || cli.AuditCheck != "" {
+				if cli.AuditOutput == "" {
+					cli.AuditOutput = "table"
+				}
+				return RunHeadlessAudit(cli, version)
+			}
+			return RunSubCommand(cli, version, cmd, args)
+		},
+	}
+	auditCmd.Flags().StringVar(&cli.AuditOutput, "output", "", "Run audit headless (no TUI) and dump results in this format (json, csv, html for a standalone report, ...)")
+	auditCmd.Flags().StringVar(&cli.AuditFile, "file", "", "Path to write headless audit results (default: stdout)")
+	auditCmd.Flags().StringVar(&cli.AuditBaseline, "baseline", "", "Path to a previous JSON audit run; report only new or worsened findings and exit nonzero if any")
+	auditCmd.Flags().StringVar(&cli.AuditCheck, "check", "", "Comma-separated check IDs (A1.6) or group names to run instead of the full audit")
+	auditCmd.Flags().DurationVar(&cli.AuditWatch, "watch", 0, "Rerun the checks every interval in the TUI and highlight findings whose severity changed (e.g. 5m)")
+	rootCmd.AddCommand(auditCmd)
+
+	metricsCmd := &cobra.Command{
+		Use:   "metrics",
+		Short: "Prometheus metrics export",
+	}
+	metricsServeCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve audit check values and system metrics as Prometheus gauges, refreshed on scrape",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunMetricsServe(cli, version)
+		},
+	}
+	metricsServeCmd.Flags().StringVar(&cli.MetricsListen, "listen", ":9363", "Address to serve /metrics on")
+	metricsCmd.AddCommand(metricsServeCmd)
+	rootCmd.AddCommand(metricsCmd)
+
+	return rootCmd
+}
+
+func RunRootCommand(cliInstance *types.CLI, version string, cmd *cobra.Command, args []string) error {
+	// Get CLI instance from command context
+	if cliInstance == nil {
+		if cliValue := cmd.Context().Value("cli"); cliValue != nil {
+			if instance, ok := cliValue.(*types.CLI); ok {
+				cliInstance = instance
+			}
+		}
+	}
+	if cliInstance == nil {
+		log.Error().Stack().Msg("can't initialize cliInstance from cmd.Context()")
+		return fmt.Errorf("can't initialize cliInstance from cmd.Context()")
+	}
+
+	// Set default paths
+	home, homeErr := os.UserHomeDir()
+	if homeErr != nil {
+		fmt.Println(homeErr.Error())
+		log.Fatal().Stack().Err(homeErr).Msg("failed to get user home directory")
+	}
+	home = filepath.Join(home, ".clickhouse-timeline")
+
+	// Set default pprof path if not provided and profiling is enabled
+	if cliInstance.Pprof && cliInstance.PprofPath == "" {
+		cliInstance.PprofPath = home
+	}
+
+	// Setup profiling if enabled
+	if cliInstance.Pprof {
+		if err := pprof.Setup(cliInstance.PprofPath); err != nil {
+			log.Error().Msgf("Failed to setup profiling: %v", err)
+			fmt.Printf("Failed to setup profiling: %v\n", err)
+			os.Exit(1)
+		}
+		defer pprof.Stop(cliInstance.PprofPath)
+	}
+
+	// Initialize logging
+	if initLogErr := logging.InitLogFile(cliInstance, version); initLogErr != nil {
+		fmt.Println(initLogErr.Error())
+		log.Fatal().Stack().Err(initLogErr).Msg("failed to initialize logger")
+	}
+
+	cfg, configErr := config.Load(cliInstance, home)
+	if configErr != nil {
+		fmt.Println(configErr.Error())
+		log.Fatal().Stack().Err(configErr).Send()
+	}
+
+	app := tui.NewApp(cfg, version)
+
+	// Get CLI instance from command context
+	app.CLI = cliInstance
+	app.ApplyCLIParameters(cliInstance, cmd.Name())
+
+	if runErr := app.Run(); runErr != nil {
+		log.Error().Stack().Err(runErr).Send()
+		return runErr
+	}
+	return nil
+}
+
+func RunSubCommand(c *types.CLI, version string, cmd *cobra.Command, args []string) error {
+	// Create a new context with the CLI instance if the current context is nil
+	ctx := context.Background()
+	if cmd.Context() != nil {
+		ctx = cmd.Context()
+	}
+
+	// Store the CLI instance in the context
+	ctx = context.WithValue(ctx, "cli", c)
+
+	// Set the context on the current command
+	cmd.SetContext(ctx)
+
+	// Run the root command directly instead of executing again
+	return RunRootCommand(c, version, cmd, args)
+}
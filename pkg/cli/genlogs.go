@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"github.com/Slach/clickhouse-timeline/pkg/client"
+	"github.com/Slach/clickhouse-timeline/pkg/config"
+	"github.com/Slach/clickhouse-timeline/pkg/types"
+	"github.com/rs/zerolog/log"
+)
+
+// genLogsBatchSize is how many rows one insert carries at the base rate
+const genLogsBatchSize = 10
+
+// genLogsLevels is the synthetic level distribution at calm times; bursts
+// shift it heavily towards Error
+var genLogsLevels = []string{
+	"Information", "Information", "Information", "Information",
+	"Debug", "Debug", "Trace", "Warning", "Error",
+}
+
+var genLogsMessages = []string{
+	"Merged %d parts in %dms",
+	"Query SELECT count() FROM demo.table_%d finished in %dms",
+	"Flushing buffer with %d rows took %dms",
+	"Connection from 127.0.0.%d accepted, session %d",
+	"Removing %d old parts from disk default after %dms",
+}
+
+var genLogsErrors = []string{
+	"Code: 241. DB::Exception: Memory limit (for query) exceeded: would use %d.00 GiB, maximum: %d.00 GiB",
+	"Code: 159. DB::Exception: Timeout exceeded: elapsed %d seconds, maximum: %d",
+	"Code: 252. DB::Exception: Too many parts (%d). Merges are processing significantly slower than inserts after %dms",
+	"Code: 53. DB::Exception: Cannot convert column n%d because %d values cannot be parsed",
+}
+
+// RunGenLogs creates a demo log table and keeps inserting synthetic
+// multi-level entries with periodic bursts and error spikes until
+// interrupted, so the logs viewer can be exercised against realistic data
+func RunGenLogs(cliInstance *types.CLI, version string) error {
+	home, homeErr := os.UserHomeDir()
+	if homeErr != nil {
+		return homeErr
+	}
+	home = filepath.Join(home, ".clickhouse-timeline")
+
+	cfg, configErr := config.Load(cliInstance, home)
+	if configErr != nil {
+		return configErr
+	}
+
+	if cliInstance.ConnectTo == "" {
+		return fmt.Errorf("gen-logs requires --connect with a context name from config")
+	}
+	var selectedContext *config.Context
+	for i, ctx := range cfg.Contexts {
+		if ctx.Name == cliInstance.ConnectTo {
+			selectedContext = &cfg.Contexts[i]
+			break
+		}
+	}
+	if selectedContext == nil {
+		return fmt.Errorf("context '%s' not found in config", cliInstance.ConnectTo)
+	}
+
+	database := cliInstance.LogsParams.Database
+	if database == "" {
+		database = "default"
+	}
+	table := cliInstance.LogsParams.Table
+	if table == "" {
+		table = "demo_logs"
+	}
+
+	clickHouse := client.NewClient(*selectedContext, version)
+	defer func() {
+		if closeErr := clickHouse.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close clickhouse connection")
+		}
+	}()
+	if _, versionErr := clickHouse.GetVersion(); versionErr != nil {
+		return fmt.Errorf("can't connect to %s:%d: %v", selectedContext.Host, selectedContext.Port, versionErr)
+	}
+
+	qualified := chquery.QualifiedName(database, table)
+	createQuery := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		event_date Date,
+		event_time DateTime,
+		event_time_microseconds DateTime64(6),
+		level Enum8('Trace'=1,'Debug'=2,'Information'=3,'Warning'=4,'Error'=5),
+		logger_name LowCardinality(String),
+		thread_id UInt32,
+		message String
+	) ENGINE = MergeTree ORDER BY event_time`, qualified)
+	if _, createErr := clickHouse.Exec(createQuery); createErr != nil {
+		return fmt.Errorf("can't create %s: %v", qualified, createErr)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "Inserting synthetic logs into %s, Ctrl+C to stop...\n", qualified)
+	started := time.Now()
+	inserted := 0
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Fprintf(os.Stderr, "Stopped after %s, %d rows inserted\n", time.Since(started).Round(time.Second), inserted)
+			return nil
+		case <-time.After(time.Second):
+		}
+
+		elapsed := int(time.Since(started).Seconds())
+		batchSize := genLogsBatchSize
+		// Short burst of traffic every 30 seconds
+		if elapsed%30 < 5 {
+			batchSize *= 10
+		}
+		// Error spike every 2 minutes
+		errorSpike := elapsed%120 < 10
+
+		if insertErr := insertGenLogsBatch(clickHouse, qualified, batchSize, errorSpike); insertErr != nil {
+			log.Error().Err(insertErr).Msg("gen-logs insert failed")
+			continue
+		}
+		inserted += batchSize
+	}
+}
+
+// insertGenLogsBatch inserts one batch of synthetic entries, mostly errors
+// during a spike
+func insertGenLogsBatch(clickHouse *client.Client, qualified string, batchSize int, errorSpike bool) error {
+	placeholders := make([]string, 0, batchSize)
+	args := make([]interface{}, 0, batchSize*6)
+	for i := 0; i < batchSize; i++ {
+		now := time.Now()
+		level := genLogsLevels[rand.Intn(len(genLogsLevels))]
+		if errorSpike && rand.Intn(10) < 7 {
+			level = "Error"
+		}
+		var message string
+		if level == "Error" {
+			message = fmt.Sprintf(genLogsErrors[rand.Intn(len(genLogsErrors))], rand.Intn(100)+1, rand.Intn(1000)+1)
+		} else {
+			message = fmt.Sprintf(genLogsMessages[rand.Intn(len(genLogsMessages))], rand.Intn(100)+1, rand.Intn(5000)+1)
+		}
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?)")
+		args = append(args,
+			now.Format("2006-01-02"),
+			now, now,
+			level,
+			fmt.Sprintf("demo.Worker%d", rand.Intn(4)),
+			uint32(rand.Intn(64)+1),
+			message)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (event_date, event_time, event_time_microseconds, level, logger_name, thread_id, message) VALUES %s",
+		qualified, strings.Join(placeholders, ", "))
+	_, err := clickHouse.Exec(query, args...)
+	return err
+}
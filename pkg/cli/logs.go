@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"github.com/Slach/clickhouse-timeline/pkg/client"
+	"github.com/Slach/clickhouse-timeline/pkg/config"
+	"github.com/Slach/clickhouse-timeline/pkg/formats"
+	"github.com/Slach/clickhouse-timeline/pkg/types"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/rs/zerolog/log"
+)
+
+// RunHeadlessLogs streams log table rows to stdout without the tview UI, so
+// the tool composes with shell pipelines: filters come from --where, the
+// range from --from/--to, the output format from --output.
+func RunHeadlessLogs(cliInstance *types.CLI, version string) error {
+	home, homeErr := os.UserHomeDir()
+	if homeErr != nil {
+		return homeErr
+	}
+	home = filepath.Join(home, ".clickhouse-timeline")
+
+	cfg, configErr := config.Load(cliInstance, home)
+	if configErr != nil {
+		return configErr
+	}
+
+	if cliInstance.ConnectTo == "" {
+		return fmt.Errorf("headless logs require --connect with a context name from config")
+	}
+	var selectedContext *config.Context
+	for i, ctx := range cfg.Contexts {
+		if ctx.Name == cliInstance.ConnectTo {
+			selectedContext = &cfg.Contexts[i]
+			break
+		}
+	}
+	if selectedContext == nil {
+		return fmt.Errorf("context '%s' not found in config", cliInstance.ConnectTo)
+	}
+
+	clickHouse := client.NewClient(*selectedContext, version)
+	defer func() {
+		if closeErr := clickHouse.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close clickhouse connection")
+		}
+	}()
+	if _, versionErr := clickHouse.GetVersion(); versionErr != nil {
+		return fmt.Errorf("can't connect to %s:%d: %v", selectedContext.Host, selectedContext.Port, versionErr)
+	}
+
+	now := time.Now()
+	fromTime := now.Add(-24 * time.Hour)
+	toTime := now
+	if cliInstance.FromTime != "" {
+		var parseErr error
+		if fromTime, parseErr = utils.ParseTimeFlag(cliInstance.FromTime, now); parseErr != nil {
+			return fmt.Errorf("can't parse --from='%s': %v", cliInstance.FromTime, parseErr)
+		}
+	}
+	if cliInstance.ToTime != "" {
+		var parseErr error
+		if toTime, parseErr = utils.ParseTimeFlag(cliInstance.ToTime, now); parseErr != nil {
+			return fmt.Errorf("can't parse --to='%s': %v", cliInstance.ToTime, parseErr)
+		}
+	}
+
+	database := cliInstance.LogsParams.Database
+	if database == "" {
+		database = "system"
+	}
+	table := cliInstance.LogsParams.Table
+	if table == "" {
+		table = "text_log"
+	}
+	timeField := cliInstance.LogsParams.Time
+	if timeField == "" {
+		timeField = "event_time"
+	}
+
+	conditions := []string{fmt.Sprintf("%s >= ? AND %s <= ?", chquery.QuoteID(timeField), chquery.QuoteID(timeField))}
+	args := []interface{}{fromTime, toTime}
+	// a date column prunes partitions before the precise time filter runs
+	if dateField := cliInstance.LogsParams.Date; dateField != "" {
+		conditions = append(conditions,
+			fmt.Sprintf("%s >= '%s' AND %s <= '%s'",
+				chquery.QuoteID(dateField), fromTime.Format("2006-01-02"),
+				chquery.QuoteID(dateField), toTime.Format("2006-01-02")))
+	}
+	// --where is passed through verbatim, it's the caller's SQL
+	if cliInstance.LogsParams.Where != "" {
+		conditions = append(conditions, fmt.Sprintf("(%s)", cliInstance.LogsParams.Where))
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s ORDER BY %s",
+		chquery.TableExpr(database, table), strings.Join(conditions, " AND "), chquery.QuoteID(timeField))
+	if cliInstance.LogsParams.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", cliInstance.LogsParams.Limit)
+	}
+
+	rows, queryErr := clickHouse.Query(query, args...)
+	if queryErr != nil {
+		return queryErr
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close headless logs rows")
+		}
+	}()
+
+	columns, columnsErr := rows.Columns()
+	if columnsErr != nil {
+		return columnsErr
+	}
+
+	rowWriter, writerErr := formats.New(cliInstance.LogsParams.Output, os.Stdout)
+	if writerErr != nil {
+		return writerErr
+	}
+	if headerErr := rowWriter.WriteHeader(columns); headerErr != nil {
+		return headerErr
+	}
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if scanErr := rows.Scan(pointers...); scanErr != nil {
+			return scanErr
+		}
+		if rowErr := rowWriter.WriteRow(values); rowErr != nil {
+			return rowErr
+		}
+	}
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return rowsErr
+	}
+	return rowWriter.Close()
+}
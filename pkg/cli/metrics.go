@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/Slach/clickhouse-timeline/pkg/audit"
+	"github.com/Slach/clickhouse-timeline/pkg/client"
+	"github.com/Slach/clickhouse-timeline/pkg/config"
+	"github.com/Slach/clickhouse-timeline/pkg/types"
+	"github.com/rs/zerolog/log"
+)
+
+// metricNameRegexp strips characters not allowed in Prometheus metric and
+// label names
+var metricNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// RunMetricsServe exposes the audit check values and system.metrics /
+// system.asynchronous_metrics as Prometheus gauges in the text exposition
+// format, refreshed on every scrape. This lets teams alert on the same
+// values and thresholds the audit uses without exporting anything by hand.
+func RunMetricsServe(cliInstance *types.CLI, version string) error {
+	home, homeErr := os.UserHomeDir()
+	if homeErr != nil {
+		return homeErr
+	}
+	home = filepath.Join(home, ".clickhouse-timeline")
+
+	cfg, configErr := config.Load(cliInstance, home)
+	if configErr != nil {
+		return configErr
+	}
+
+	if cliInstance.ConnectTo == "" {
+		return fmt.Errorf("metrics serve requires --connect with a context name from config")
+	}
+	var selectedContext *config.Context
+	for i, ctx := range cfg.Contexts {
+		if ctx.Name == cliInstance.ConnectTo {
+			selectedContext = &cfg.Contexts[i]
+			break
+		}
+	}
+	if selectedContext == nil {
+		return fmt.Errorf("context '%s' not found in config", cliInstance.ConnectTo)
+	}
+	if cliInstance.Cluster == "" {
+		return fmt.Errorf("metrics serve requires --cluster")
+	}
+
+	clickHouse := client.NewClient(*selectedContext, version)
+	defer func() {
+		if closeErr := clickHouse.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close clickhouse connection")
+		}
+	}()
+	if _, versionErr := clickHouse.GetVersion(); versionErr != nil {
+		return fmt.Errorf("can't connect to %s:%d: %v", selectedContext.Host, selectedContext.Port, versionErr)
+	}
+
+	auditor := audit.NewAuditor(clickHouse, cliInstance.Cluster, cfg)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writeAuditMetrics(w, auditor)
+		writeSystemMetrics(w, clickHouse, "system.metrics", "clickhouse_timeline_metric")
+		writeSystemMetrics(w, clickHouse, "system.asynchronous_metrics", "clickhouse_timeline_async_metric")
+	})
+
+	fmt.Fprintf(os.Stderr, "Serving Prometheus metrics on %s/metrics (audit checks run on every scrape)\n", cliInstance.MetricsListen)
+	return http.ListenAndServe(cliInstance.MetricsListen, mux)
+}
+
+// writeAuditMetrics runs the full audit and renders every AuditResult value
+// as one gauge sample labeled with the finding ID, host, object and severity
+func writeAuditMetrics(w http.ResponseWriter, auditor *audit.Auditor) {
+	results := auditor.RunChecks(nil)
+
+	// Group samples per metric name so each gets exactly one TYPE line
+	samples := make(map[string][]string)
+	for _, result := range results {
+		for key, value := range result.Values {
+			name := "clickhouse_timeline_audit_" + sanitizeMetricName(key)
+			// %q escapes backslashes, quotes and newlines exactly like the
+			// Prometheus label value escaping rules
+			samples[name] = append(samples[name], fmt.Sprintf("%s{id=%q,host=%q,object=%q,severity=%q} %v",
+				name, result.ID, result.Host, result.Object, result.Severity, value))
+		}
+	}
+
+	names := make([]string, 0, len(samples))
+	for name := range samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+		for _, sample := range samples[name] {
+			fmt.Fprintln(w, sample)
+		}
+	}
+}
+
+// writeSystemMetrics renders one gauge per row of a (metric, value) system
+// table; query errors are logged and skipped so one broken table doesn't
+// empty the whole scrape
+func writeSystemMetrics(w http.ResponseWriter, clickHouse *client.Client, table, metricName string) {
+	rows, err := clickHouse.Query(fmt.Sprintf("SELECT metric, toFloat64(value) AS value FROM %s ORDER BY metric", table))
+	if err != nil {
+		log.Error().Err(err).Str("table", table).Msg("can't query system metrics for scrape")
+		return
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close %s rows", table)
+		}
+	}()
+
+	fmt.Fprintf(w, "# TYPE %s gauge\n", metricName)
+	for rows.Next() {
+		var metric string
+		var value float64
+		if scanErr := rows.Scan(&metric, &value); scanErr != nil {
+			log.Error().Err(scanErr).Str("table", table).Msg("can't scan system metric row")
+			continue
+		}
+		fmt.Fprintf(w, "%s{name=%q} %v\n", metricName, metric, value)
+	}
+}
+
+// sanitizeMetricName replaces characters not allowed in Prometheus names
+func sanitizeMetricName(name string) string {
+	return metricNameRegexp.ReplaceAllString(name, "_")
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Metadata cache limits: entries expire after metadataCacheTTL so schema
+// changes still show up after a short while, and the least recently used
+// entry is evicted once metadataCacheSize is reached
+const (
+	metadataCacheTTL  = 5 * time.Minute
+	metadataCacheSize = 64
+)
+
+// cacheEntry is one cached query result with its expiry and last-use time
+type cacheEntry struct {
+	rows     [][]string
+	expires  time.Time
+	lastUsed time.Time
+}
+
+// metadataCache is a TTL+LRU cache for small string-result metadata queries
+// (databases, tables, columns) that forms re-run every time they open; it
+// lives on the Client so the connection is implicitly part of the key
+type metadataCache struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+func (mc *metadataCache) get(key string) ([][]string, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	entry, found := mc.entries[key]
+	if !found {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(mc.entries, key)
+		return nil, false
+	}
+	entry.lastUsed = time.Now()
+	return entry.rows, true
+}
+
+func (mc *metadataCache) put(key string, rows [][]string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mc.entries == nil {
+		mc.entries = make(map[string]*cacheEntry)
+	}
+	if len(mc.entries) >= metadataCacheSize {
+		oldestKey := ""
+		var oldestUsed time.Time
+		for key, entry := range mc.entries {
+			if oldestKey == "" || entry.lastUsed.Before(oldestUsed) {
+				oldestKey = key
+				oldestUsed = entry.lastUsed
+			}
+		}
+		delete(mc.entries, oldestKey)
+	}
+	now := time.Now()
+	mc.entries[key] = &cacheEntry{rows: rows, expires: now.Add(metadataCacheTTL), lastUsed: now}
+}
+
+// QueryStringsCached runs a small metadata query through the client's TTL+LRU
+// cache and returns every column of every row as a string, so reopening a
+// form within the TTL doesn't hit system tables again. Only use it for
+// queries whose results are small and change rarely.
+func (c *Client) QueryStringsCached(ctx context.Context, query string, args ...interface{}) ([][]string, error) {
+	key := fmt.Sprintf("%s|%#v", query, args)
+	if cached, found := c.metadata.get(key); found {
+		return cached, nil
+	}
+
+	rows, err := c.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close QueryStringsCached rows")
+		}
+	}()
+
+	columns, columnsErr := rows.Columns()
+	if columnsErr != nil {
+		return nil, columnsErr
+	}
+
+	var result [][]string
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if scanErr := rows.Scan(pointers...); scanErr != nil {
+			return nil, scanErr
+		}
+		row := make([]string, len(columns))
+		for i, value := range values {
+			row[i] = fmt.Sprintf("%v", value)
+		}
+		result = append(result, row)
+	}
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, rowsErr
+	}
+
+	c.metadata.put(key, result)
+	return result, nil
+}
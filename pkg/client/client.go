@@ -0,0 +1,253 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/Slach/clickhouse-timeline/pkg/config"
+	"github.com/rs/zerolog/log"
+)
+
+// Connection pool limits: maxOpenConns caps how many queries run
+// concurrently against one server, maxExecutionTime aborts a single query
+// server-side even when the client context is never cancelled
+const (
+	maxOpenConns     = 10
+	maxIdleConns     = 5
+	connMaxLifetime  = 10 * time.Minute
+	maxExecutionTime = 600 // seconds
+)
+
+type Client struct {
+	config   config.Context
+	db       *sql.DB
+	version  string
+	metadata metadataCache
+}
+
+func NewClient(cfg config.Context, version string) *Client {
+	return &Client{
+		config:  cfg,
+		version: version,
+	}
+}
+
+func (c *Client) GetVersion() (string, error) {
+	if c.db == nil {
+		if err := c.connect(); err != nil {
+			return "", err
+		}
+	}
+
+	var version string
+	err := c.db.QueryRow("SELECT version()").Scan(&version)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get ClickHouse version")
+		return "", err
+	}
+
+	return version, nil
+}
+
+func (c *Client) connect() error {
+	var tlsConfig *tls.Config
+
+	// Enable TLS if secure is true or if certificates are provided
+	if c.config.Secure || (c.config.TLSCert != "" && c.config.TLSKey != "") || c.config.TLSCa != "" || c.config.TLSVerify {
+		tlsConfig = &tls.Config{
+			InsecureSkipVerify: !c.config.TLSVerify,
+		}
+
+		// Load client certificates if provided
+		if c.config.TLSCert != "" && c.config.TLSKey != "" {
+			cert, err := tls.LoadX509KeyPair(c.config.TLSCert, c.config.TLSKey)
+			if err != nil {
+				return fmt.Errorf("failed to load client certificate: %v", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+
+		// Load CA certificate if provided
+		if c.config.TLSCa != "" {
+			caCert, err := os.ReadFile(c.config.TLSCa)
+			if err != nil {
+				return fmt.Errorf("failed to read CA certificate: %v", err)
+			}
+			caCertPool := x509.NewCertPool()
+			caCertPool.AppendCertsFromPEM(caCert)
+			tlsConfig.RootCAs = caCertPool
+		}
+	}
+
+	options := &clickhouse.Options{
+		Addr: []string{fmt.Sprintf("%s:%d", c.config.Host, c.config.Port)},
+		Auth: clickhouse.Auth{
+			Database: c.config.Database,
+			Username: c.config.Username,
+			Password: c.config.Password,
+		},
+		TLS: tlsConfig,
+	}
+	options.ClientInfo.Products = append(options.ClientInfo.Products, struct{ Name, Version string }{
+		"clickhouse-timeline",
+		c.version,
+	})
+
+	options.Protocol = clickhouse.Native
+	if c.config.Protocol == "http" {
+		options.Protocol = clickhouse.HTTP
+	}
+
+	if compression, compressionErr := compressionMethod(c.config.Compression, options.Protocol); compressionErr != nil {
+		return compressionErr
+	} else if compression != nil {
+		options.Compression = compression
+	}
+
+	// Context settings ride along with every query, on both protocols
+	if len(c.config.Settings) > 0 {
+		options.Settings = clickhouse.Settings{}
+		for name, value := range c.config.Settings {
+			options.Settings[name] = value
+		}
+	}
+
+	db := clickhouse.OpenDB(options)
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxIdleConns)
+	db.SetConnMaxLifetime(connMaxLifetime)
+
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return err
+	}
+
+	c.db = db
+	return nil
+}
+
+// compressionMethod maps the context's compression name to driver options;
+// with no name set it defaults to lz4 on native connections and gzip on
+// http ones (what proxies in front of 8123/8443 typically expect), "none"
+// disables compression explicitly
+func compressionMethod(name string, protocol clickhouse.Protocol) (*clickhouse.Compression, error) {
+	switch name {
+	case "":
+		if protocol == clickhouse.HTTP {
+			return &clickhouse.Compression{Method: clickhouse.CompressionGZIP}, nil
+		}
+		return &clickhouse.Compression{Method: clickhouse.CompressionLZ4}, nil
+	case "none":
+		return nil, nil
+	case "lz4":
+		return &clickhouse.Compression{Method: clickhouse.CompressionLZ4}, nil
+	case "zstd":
+		return &clickhouse.Compression{Method: clickhouse.CompressionZSTD}, nil
+	case "gzip":
+		return &clickhouse.Compression{Method: clickhouse.CompressionGZIP}, nil
+	}
+	return nil, fmt.Errorf("unknown compression '%s', expected lz4, zstd, gzip or none", name)
+}
+
+// withQueryLimits applies the per-query server-side timeout to the context
+func withQueryLimits(ctx context.Context) context.Context {
+	return clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{
+		"max_execution_time": maxExecutionTime,
+	}))
+}
+
+// QueryWithLogComment executes a query with the given log_comment setting so
+// its own system.query_log entry can be found later for cost reporting.
+// Cancelling ctx aborts the query server-side.
+func (c *Client) QueryWithLogComment(ctx context.Context, logComment string, query string, args ...interface{}) (*sql.Rows, error) {
+	log.Info().Str("log_comment", logComment).Msg(query)
+	if len(args) > 0 {
+		log.Info().Msgf("args=%#v", args)
+	}
+	ctx = clickhouse.Context(withQueryLimits(ctx), clickhouse.WithSettings(clickhouse.Settings{
+		"log_comment": logComment,
+	}))
+	start := time.Now()
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	recordQuery(query, args, time.Since(start), -1, err)
+	if err != nil {
+		log.Error().Stack().Err(err).Str("query", query).Interface("args", args).Msg("QueryWithLogComment failed")
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (c *Client) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.QueryContext(context.Background(), query, args...)
+}
+
+// QueryContext runs a query which is cancelled server-side when ctx is done,
+// so navigating away from a panel doesn't leave slow queries running
+func (c *Client) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	log.Info().Msg(query)
+	if len(args) > 0 {
+		log.Info().Msgf("args=%#v", args)
+	}
+	start := time.Now()
+	rows, err := c.db.QueryContext(withQueryLimits(ctx), query, args...)
+	recordQuery(query, args, time.Since(start), -1, err)
+	if err != nil {
+		log.Error().Stack().Err(err).Str("query", query).Interface("args", args).Msg("Query failed")
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (c *Client) QueryRow(query string, args ...interface{}) *sql.Row {
+	return c.QueryRowContext(context.Background(), query, args...)
+}
+
+// QueryRowContext is the single-row variant of QueryContext
+func (c *Client) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	log.Info().Msg(query)
+	if len(args) > 0 {
+		log.Info().Msgf("args=%#v", args)
+	}
+	start := time.Now()
+	row := c.db.QueryRowContext(withQueryLimits(ctx), query, args...)
+	recordQuery(query, args, time.Since(start), -1, row.Err())
+	if row.Err() != nil {
+		log.Error().Stack().Err(row.Err()).Str("query", query).Interface("args", args).Msg("QueryRow failed")
+		return nil
+	}
+	return row
+}
+
+func (c *Client) Exec(query string, args ...interface{}) (sql.Result, error) {
+	log.Info().Msg(query)
+	if len(args) > 0 {
+		log.Info().Msgf("args=%#v", args)
+	}
+	start := time.Now()
+	res, err := c.db.ExecContext(withQueryLimits(context.Background()), query, args...)
+	affected := int64(-1)
+	if err == nil {
+		if rows, affectedErr := res.RowsAffected(); affectedErr == nil {
+			affected = rows
+		}
+	}
+	recordQuery(query, args, time.Since(start), affected, err)
+	if err != nil {
+		log.Error().Stack().Err(err).Str("query", query).Interface("args", args).Msg("Exec failed")
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) Close() error {
+	if c.db != nil {
+		return c.db.Close()
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"github.com/rs/zerolog/log"
+)
+
+// Query audit limits: the on-disk journal is rotated aside once it outgrows
+// queryLogMaxBytes (one previous generation is kept), the in-memory tail shown
+// by :queries keeps the last queryLogKeep records
+const (
+	queryLogMaxBytes = 10 * 1024 * 1024
+	queryLogKeep     = 200
+)
+
+// QueryRecord is one executed SQL statement with its outcome, recorded for
+// every query the tool runs so "why is the tool slow" and "what load does it
+// put on the server" can be answered from the tool itself
+type QueryRecord struct {
+	Time       time.Time `json:"time"`
+	Query      string    `json:"query"`
+	Args       string    `json:"args,omitempty"`
+	DurationMs float64   `json:"duration_ms"`
+	// Rows is the affected-row count for Exec statements, -1 when the
+	// statement streams its result and the count isn't known here
+	Rows  int64  `json:"rows"`
+	Error string `json:"error,omitempty"`
+}
+
+// queryAudit holds the in-memory tail of recorded statements; the journal
+// file gets every record appended as one JSON line
+type queryAudit struct {
+	mu      sync.Mutex
+	records []QueryRecord
+}
+
+var audit queryAudit
+
+// queryLogPath returns ~/.clickhouse-timeline/queries.log
+func queryLogPath() string {
+	home, homeErr := os.UserHomeDir()
+	if homeErr != nil {
+		log.Error().Err(homeErr).Msg("can't detect home directory for query log")
+		return ""
+	}
+	return filepath.Join(home, ".clickhouse-timeline", "queries.log")
+}
+
+// recordQuery appends one executed statement to the in-memory tail and the
+// on-disk journal; rows is -1 for streaming statements. Table function
+// credentials are redacted so the journal never holds passwords.
+func recordQuery(query string, args []interface{}, duration time.Duration, rows int64, err error) {
+	record := QueryRecord{
+		Time:       time.Now(),
+		Query:      chquery.RedactTableFunctionCredentials(query),
+		DurationMs: float64(duration.Microseconds()) / 1000,
+		Rows:       rows,
+	}
+	if len(args) > 0 {
+		record.Args = fmt.Sprintf("%#v", args)
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	audit.mu.Lock()
+	audit.records = append(audit.records, record)
+	if len(audit.records) > queryLogKeep {
+		audit.records = audit.records[len(audit.records)-queryLogKeep:]
+	}
+	audit.mu.Unlock()
+
+	appendQueryLog(record)
+}
+
+// appendQueryLog writes one record as a JSON line, rotating the journal aside
+// first when it outgrew its size limit
+func appendQueryLog(record QueryRecord) {
+	path := queryLogPath()
+	if path == "" {
+		return
+	}
+	if mkdirErr := os.MkdirAll(filepath.Dir(path), 0755); mkdirErr != nil {
+		log.Error().Err(mkdirErr).Str("path", path).Msg("can't create query log directory")
+		return
+	}
+	rotateQueryLog(path)
+
+	data, marshalErr := json.Marshal(record)
+	if marshalErr != nil {
+		log.Error().Err(marshalErr).Msg("can't serialize query record")
+		return
+	}
+	logFile, openErr := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if openErr != nil {
+		log.Error().Err(openErr).Str("path", path).Msg("can't open query log")
+		return
+	}
+	defer func() {
+		if closeErr := logFile.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Str("path", path).Msg("can't close query log")
+		}
+	}()
+	if _, writeErr := logFile.Write(append(data, '\n')); writeErr != nil {
+		log.Error().Err(writeErr).Str("path", path).Msg("can't write query log")
+	}
+}
+
+// rotateQueryLog moves the journal aside once it outgrows queryLogMaxBytes,
+// keeping exactly one previous generation
+func rotateQueryLog(path string) {
+	info, statErr := os.Stat(path)
+	if statErr != nil || info.Size() < queryLogMaxBytes {
+		return
+	}
+	_ = os.Remove(path + ".1")
+	if renameErr := os.Rename(path, path+".1"); renameErr != nil {
+		log.Error().Err(renameErr).Str("path", path).Msg("can't rotate query log")
+	}
+}
+
+// RecentQueries returns a copy of the in-memory record tail, oldest first
+func RecentQueries() []QueryRecord {
+	audit.mu.Lock()
+	defer audit.mu.Unlock()
+	records := make([]QueryRecord, len(audit.records))
+	copy(records, audit.records)
+	return records
+}
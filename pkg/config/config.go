@@ -0,0 +1,163 @@
+package config
+
+import (
+	"github.com/Slach/clickhouse-timeline/pkg/types"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type Context struct {
+	Name     string `yaml:"name"`
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Database string `yaml:"database"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// Secret references resolved at load time, so the config file doesn't
+	// have to hold the password itself: PasswordFile reads the (trimmed)
+	// file contents, PasswordEnv reads an environment variable. Password
+	// takes precedence when set directly.
+	PasswordFile string `yaml:"password_file"`
+	PasswordEnv  string `yaml:"password_env"`
+	Protocol     string `yaml:"protocol"` // http or native
+	// Compression on the wire: lz4, zstd, gzip or none; defaults to lz4 for
+	// native connections and gzip for http, which matters most behind
+	// load balancers that only expose 8123/8443
+	Compression string `yaml:"compression"`
+	// Extra ClickHouse settings sent with every query of this context,
+	// e.g. max_memory_usage or readonly
+	Settings  map[string]string `yaml:"settings"`
+	Secure    bool              `yaml:"secure"`
+	TLSVerify bool              `yaml:"tls_verify"`
+	TLSCert   string            `yaml:"tls_cert"`
+	TLSKey    string            `yaml:"tls_key"`
+	TLSCa     string            `yaml:"tls_ca"`
+	// BackupAPIURL is an optional clickhouse-backup API endpoint for this
+	// context (e.g. http://localhost:7171); the backup audit checks and the
+	// :backups page query it for the remote backup list, since system.backups
+	// only remembers operations since the server started
+	BackupAPIURL string `yaml:"backup_api_url"`
+	// OTLPEndpoint is an optional OTLP/HTTP collector base URL (e.g.
+	// http://localhost:4318); the log explorer ships entries there as OTLP
+	// LogRecords, so findings land in an existing observability stack
+	OTLPEndpoint string `yaml:"otlp_endpoint"`
+}
+
+type Config struct {
+	Contexts []Context `yaml:"contexts"`
+	// Go time layouts used for displayed timestamps, e.g. "02.01.2006 15:04:05"
+	// (full form for details/titles/exports, short form for dense tables)
+	TimestampFormat      string `yaml:"timestamp_format"`
+	TimestampShortFormat string `yaml:"timestamp_short_format"`
+	// Table row density: "compact" fits more rows on small terminals by
+	// dropping cell padding and shortening timestamps, default "comfortable"
+	RowDensity string `yaml:"row_density"`
+	// Zone displayed timestamps are converted to: "utc", "local" or an IANA
+	// name like "Europe/Berlin"; empty shows timestamps as delivered, which
+	// on mixed-timezone clusters means mixed offsets. Switchable at runtime
+	// with :tz.
+	Timezone string `yaml:"timezone"`
+	// Color palette: "dark" (default), "light", "solarized", "monochrome" or
+	// "high-contrast" (the last two mark severity with symbols and bold, not
+	// color alone); switchable at runtime with :theme
+	Theme            string            `yaml:"theme"`
+	AuditOverrides   []AuditOverride   `yaml:"audit_overrides"`
+	AuditAcks        []AuditAck        `yaml:"audit_acks"`
+	LogLevelMappings []LogLevelMapping `yaml:"log_level_mappings"`
+}
+
+// AuditAck acknowledges one audit finding so runs hide it as an accepted
+// warning; empty object matches every object of the check, until is a
+// "2006-01-02" date after which the ack expires. Acks saved with 'a' in the
+// audit panel live in ~/.clickhouse-timeline/audit-acks.yaml instead.
+type AuditAck struct {
+	ID     string `yaml:"id"`
+	Object string `yaml:"object"`
+	Until  string `yaml:"until"`
+	Reason string `yaml:"reason"`
+}
+
+// LogLevelMapping seeds the raw level value -> canonical level translation of
+// one log table (canonical: error, warning, info, debug, trace, unknown), the
+// same structure the :logs level-mapping editor persists; an editor-saved
+// mapping takes precedence over config entries
+type LogLevelMapping struct {
+	Database string            `yaml:"database"`
+	Table    string            `yaml:"table"`
+	Field    string            `yaml:"field"`
+	Mapping  map[string]string `yaml:"mapping"`
+}
+
+// AuditThreshold maps a minimal value to a severity (critical, major, moderate, minor)
+type AuditThreshold struct {
+	Min      float64 `yaml:"min"`
+	Severity string  `yaml:"severity"`
+}
+
+// AuditOverride replaces the built-in severity thresholds of one audit check
+// ID for a specific cluster, e.g. raising the A0.1.05 parts-count limits on a
+// large cluster where 200k parts are legitimate. Empty cluster matches all.
+type AuditOverride struct {
+	ID         string           `yaml:"id"`
+	Cluster    string           `yaml:"cluster"`
+	Thresholds []AuditThreshold `yaml:"thresholds"`
+}
+
+func Load(cliInstance *types.CLI, home string) (*Config, error) {
+	configPath := filepath.Join(home, "clickhouse-timeline.yml")
+	if cliInstance != nil && cliInstance.ConfigPath != "" {
+		configPath = cliInstance.ConfigPath
+	}
+
+	data, readErr := os.ReadFile(configPath)
+	if readErr != nil {
+		return nil, errors.WithStack(readErr) // Wrap with stack trace
+	}
+
+	var cfg Config
+	if unmarshalErr := yaml.Unmarshal(data, &cfg); unmarshalErr != nil {
+		return nil, errors.WithStack(unmarshalErr) // Wrap with stack trace
+	}
+
+	utils.SetTimestampFormats(cfg.TimestampFormat, cfg.TimestampShortFormat)
+	utils.SetRowDensity(cfg.RowDensity)
+	if tzErr := utils.SetDisplayLocation(cfg.Timezone); tzErr != nil {
+		return nil, errors.Wrapf(tzErr, "invalid timezone %q in %s", cfg.Timezone, configPath)
+	}
+
+	for i := range cfg.Contexts {
+		if resolveErr := resolvePassword(&cfg.Contexts[i]); resolveErr != nil {
+			return nil, resolveErr
+		}
+	}
+
+	return &cfg, nil
+}
+
+// resolvePassword fills ctx.Password from its password_file or password_env
+// secret reference; a directly set password wins over both
+func resolvePassword(ctx *Context) error {
+	if ctx.Password != "" {
+		return nil
+	}
+	if ctx.PasswordFile != "" {
+		data, readErr := os.ReadFile(ctx.PasswordFile)
+		if readErr != nil {
+			return errors.Wrapf(readErr, "can't read password_file for context '%s'", ctx.Name)
+		}
+		ctx.Password = strings.TrimSpace(string(data))
+		return nil
+	}
+	if ctx.PasswordEnv != "" {
+		value, found := os.LookupEnv(ctx.PasswordEnv)
+		if !found {
+			return errors.Errorf("environment variable '%s' from password_env for context '%s' is not set", ctx.PasswordEnv, ctx.Name)
+		}
+		ctx.Password = value
+	}
+	return nil
+}
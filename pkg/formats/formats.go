@@ -0,0 +1,216 @@
+package formats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+)
+
+// RowWriter writes tabular output of headless commands in a concrete format.
+// Usage: WriteHeader once, WriteRow per data row, Close to flush.
+type RowWriter interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+var registry = map[string]func(w io.Writer) RowWriter{
+	"json":     func(w io.Writer) RowWriter { return &jsonWriter{w: w} },
+	"jsonl":    func(w io.Writer) RowWriter { return &jsonWriter{w: w, lines: true} },
+	"csv":      func(w io.Writer) RowWriter { return &csvWriter{csv: csv.NewWriter(w)} },
+	"tsv":      func(w io.Writer) RowWriter { return newTSVWriter(w) },
+	"table":    func(w io.Writer) RowWriter { return &tableWriter{tab: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)} },
+	"markdown": func(w io.Writer) RowWriter { return &markdownWriter{w: w} },
+	"parquet":  func(w io.Writer) RowWriter { return &parquetWriter{w: w} },
+}
+
+// New returns a RowWriter for the given format name writing to w
+func New(format string, w io.Writer) (RowWriter, error) {
+	newWriter, found := registry[format]
+	if !found {
+		return nil, fmt.Errorf("unknown format '%s', available formats: %s", format, strings.Join(List(), ", "))
+	}
+	return newWriter(w), nil
+}
+
+// List returns all registered format names sorted alphabetically
+func List() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ToString renders a single value the way textual writers expect it
+func ToString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return utils.FormatTimestamp(v)
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+type jsonWriter struct {
+	w       io.Writer
+	lines   bool
+	columns []string
+	rows    int
+}
+
+func (jw *jsonWriter) WriteHeader(columns []string) error {
+	jw.columns = columns
+	if !jw.lines {
+		if _, err := io.WriteString(jw.w, "[\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (jw *jsonWriter) WriteRow(values []interface{}) error {
+	var builder strings.Builder
+	if !jw.lines && jw.rows > 0 {
+		builder.WriteString(",\n")
+	}
+	builder.WriteString("{")
+	for i, column := range jw.columns {
+		if i > 0 {
+			builder.WriteString(",")
+		}
+		name, nameErr := json.Marshal(column)
+		if nameErr != nil {
+			return nameErr
+		}
+		var value interface{}
+		if i < len(values) {
+			value = values[i]
+		}
+		if b, isBytes := value.([]byte); isBytes {
+			value = string(b)
+		}
+		data, marshalErr := json.Marshal(value)
+		if marshalErr != nil {
+			return marshalErr
+		}
+		builder.Write(name)
+		builder.WriteString(":")
+		builder.Write(data)
+	}
+	builder.WriteString("}")
+	if jw.lines {
+		builder.WriteString("\n")
+	}
+	jw.rows++
+	_, err := io.WriteString(jw.w, builder.String())
+	return err
+}
+
+func (jw *jsonWriter) Close() error {
+	if !jw.lines {
+		_, err := io.WriteString(jw.w, "\n]\n")
+		return err
+	}
+	return nil
+}
+
+type csvWriter struct {
+	csv *csv.Writer
+}
+
+func newTSVWriter(w io.Writer) *csvWriter {
+	tsv := csv.NewWriter(w)
+	tsv.Comma = '\t'
+	return &csvWriter{csv: tsv}
+}
+
+func (cw *csvWriter) WriteHeader(columns []string) error {
+	return cw.csv.Write(columns)
+}
+
+func (cw *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = ToString(value)
+	}
+	return cw.csv.Write(record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.csv.Flush()
+	return cw.csv.Error()
+}
+
+type tableWriter struct {
+	tab *tabwriter.Writer
+}
+
+func (tw *tableWriter) WriteHeader(columns []string) error {
+	_, err := fmt.Fprintln(tw.tab, strings.Join(columns, "\t"))
+	return err
+}
+
+func (tw *tableWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = strings.ReplaceAll(ToString(value), "\t", " ")
+	}
+	_, err := fmt.Fprintln(tw.tab, strings.Join(record, "\t"))
+	return err
+}
+
+func (tw *tableWriter) Close() error {
+	return tw.tab.Flush()
+}
+
+type markdownWriter struct {
+	w       io.Writer
+	columns int
+}
+
+func (mw *markdownWriter) writeRecord(record []string) error {
+	for i := range record {
+		record[i] = strings.ReplaceAll(strings.ReplaceAll(record[i], "|", "\\|"), "\n", " ")
+	}
+	_, err := fmt.Fprintf(mw.w, "| %s |\n", strings.Join(record, " | "))
+	return err
+}
+
+func (mw *markdownWriter) WriteHeader(columns []string) error {
+	mw.columns = len(columns)
+	if err := mw.writeRecord(append([]string{}, columns...)); err != nil {
+		return err
+	}
+	separator := make([]string, len(columns))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	return mw.writeRecord(separator)
+}
+
+func (mw *markdownWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = ToString(value)
+	}
+	return mw.writeRecord(record)
+}
+
+func (mw *markdownWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,64 @@
+package formats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRowWriters(t *testing.T) {
+	expected := map[string]string{
+		"csv":      "id,details\nA0.1.01,\"too many, parts\"\n",
+		"tsv":      "id\tdetails\nA0.1.01\ttoo many, parts\n",
+		"jsonl":    "{\"id\":\"A0.1.01\",\"details\":\"too many, parts\"}\n",
+		"json":     "[\n{\"id\":\"A0.1.01\",\"details\":\"too many, parts\"}\n]\n",
+		"markdown": "| id | details |\n| --- | --- |\n| A0.1.01 | too many, parts |\n",
+		"table":    "id       details\nA0.1.01  too many, parts\n",
+	}
+
+	for format, expectedOutput := range expected {
+		var buf bytes.Buffer
+		rowWriter, err := New(format, &buf)
+		if err != nil {
+			t.Fatalf("New(%s) failed: %v", format, err)
+		}
+		if headerErr := rowWriter.WriteHeader([]string{"id", "details"}); headerErr != nil {
+			t.Fatalf("%s WriteHeader failed: %v", format, headerErr)
+		}
+		if rowErr := rowWriter.WriteRow([]interface{}{"A0.1.01", "too many, parts"}); rowErr != nil {
+			t.Fatalf("%s WriteRow failed: %v", format, rowErr)
+		}
+		if closeErr := rowWriter.Close(); closeErr != nil {
+			t.Fatalf("%s Close failed: %v", format, closeErr)
+		}
+		if buf.String() != expectedOutput {
+			t.Errorf("%s output mismatch:\ngot:\n%s\nexpected:\n%s", format, buf.String(), expectedOutput)
+		}
+	}
+}
+
+func TestParquetWriter(t *testing.T) {
+	var buf bytes.Buffer
+	rowWriter, err := New("parquet", &buf)
+	if err != nil {
+		t.Fatalf("New(parquet) failed: %v", err)
+	}
+	if headerErr := rowWriter.WriteHeader([]string{"id", "details"}); headerErr != nil {
+		t.Fatalf("parquet WriteHeader failed: %v", headerErr)
+	}
+	if rowErr := rowWriter.WriteRow([]interface{}{"A0.1.01", "too many parts"}); rowErr != nil {
+		t.Fatalf("parquet WriteRow failed: %v", rowErr)
+	}
+	if closeErr := rowWriter.Close(); closeErr != nil {
+		t.Fatalf("parquet Close failed: %v", closeErr)
+	}
+	if !strings.HasPrefix(buf.String(), "PAR1") {
+		t.Errorf("parquet output does not start with PAR1 magic")
+	}
+}
+
+func TestNewUnknownFormat(t *testing.T) {
+	if _, err := New("xml", nil); err == nil {
+		t.Errorf("expected error for unknown format")
+	}
+}
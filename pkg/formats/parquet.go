@@ -0,0 +1,48 @@
+package formats
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/xitongsys/parquet-go-source/writerfile"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// parquetWriter stores every column as UTF8 BYTE_ARRAY, which keeps the schema
+// independent from the actual ClickHouse column types
+type parquetWriter struct {
+	w       io.Writer
+	parquet *writer.CSVWriter
+}
+
+func (pw *parquetWriter) WriteHeader(columns []string) error {
+	metadata := make([]string, len(columns))
+	for i, column := range columns {
+		metadata[i] = fmt.Sprintf("name=%s, type=BYTE_ARRAY, convertedtype=UTF8", column)
+	}
+	parquetFile := writerfile.NewWriterFile(pw.w)
+	csvWriter, err := writer.NewCSVWriter(metadata, parquetFile, 1)
+	if err != nil {
+		return err
+	}
+	pw.parquet = csvWriter
+	return nil
+}
+
+func (pw *parquetWriter) WriteRow(values []interface{}) error {
+	if pw.parquet == nil {
+		return fmt.Errorf("parquet writer is not initialized, call WriteHeader first")
+	}
+	record := make([]interface{}, len(values))
+	for i, value := range values {
+		record[i] = ToString(value)
+	}
+	return pw.parquet.Write(record)
+}
+
+func (pw *parquetWriter) Close() error {
+	if pw.parquet == nil {
+		return nil
+	}
+	return pw.parquet.WriteStop()
+}
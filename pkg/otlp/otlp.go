@@ -0,0 +1,131 @@
+// Package otlp ships log records to an OTLP/HTTP endpoint using the JSON
+// encoding of the OpenTelemetry logs protocol. The payload is hand-rolled so
+// the tool doesn't pull in the OpenTelemetry SDK for what is a single POST.
+package otlp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportTimeout caps the OTLP round trip; a slow collector shouldn't hang
+// the UI action that triggered the export
+const exportTimeout = 15 * time.Second
+
+// LogRecord is one log entry to export; Attributes carry all fields that
+// aren't the timestamp, the level or the message itself
+type LogRecord struct {
+	Time         time.Time
+	SeverityText string
+	Body         string
+	Attributes   map[string]string
+}
+
+// OTLP/HTTP JSON payload shapes, trimmed to the fields this exporter fills
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano   string          `json:"timeUnixNano"`
+	SeverityNumber int             `json:"severityNumber"`
+	SeverityText   string          `json:"severityText"`
+	Body           otlpValue       `json:"body"`
+	Attributes     []otlpAttribute `json:"attributes,omitempty"`
+}
+
+type otlpScopeLogs struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpResourceLogs struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpPayload struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+// severityNumber maps ClickHouse log levels onto the OTLP severity scale
+// (TRACE=1, DEBUG=5, INFO=9, WARN=13, ERROR=17, FATAL=21)
+func severityNumber(level string) int {
+	switch strings.ToLower(level) {
+	case "fatal", "critical":
+		return 21
+	case "error":
+		return 17
+	case "warning", "warn":
+		return 13
+	case "information", "info", "notice":
+		return 9
+	case "debug":
+		return 5
+	case "trace", "test":
+		return 1
+	}
+	return 0
+}
+
+// ExportLogs POSTs the records to the endpoint's /v1/logs path as one
+// resource; serviceName becomes the service.name resource attribute
+func ExportLogs(endpoint, serviceName string, records []LogRecord) error {
+	scopeLogs := otlpScopeLogs{}
+	scopeLogs.Scope.Name = "clickhouse-timeline"
+	for _, record := range records {
+		logRecord := otlpLogRecord{
+			TimeUnixNano:   strconv.FormatInt(record.Time.UnixNano(), 10),
+			SeverityNumber: severityNumber(record.SeverityText),
+			SeverityText:   record.SeverityText,
+			Body:           otlpValue{StringValue: record.Body},
+		}
+		keys := make([]string, 0, len(record.Attributes))
+		for key := range record.Attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			logRecord.Attributes = append(logRecord.Attributes, otlpAttribute{Key: key, Value: otlpValue{StringValue: record.Attributes[key]}})
+		}
+		scopeLogs.LogRecords = append(scopeLogs.LogRecords, logRecord)
+	}
+
+	resourceLogs := otlpResourceLogs{ScopeLogs: []otlpScopeLogs{scopeLogs}}
+	resourceLogs.Resource.Attributes = []otlpAttribute{
+		{Key: "service.name", Value: otlpValue{StringValue: serviceName}},
+	}
+
+	body, marshalErr := json.Marshal(otlpPayload{ResourceLogs: []otlpResourceLogs{resourceLogs}})
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	httpClient := &http.Client{Timeout: exportTimeout}
+	resp, err := httpClient.Post(strings.TrimRight(endpoint, "/")+"/v1/logs", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("OTLP endpoint returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
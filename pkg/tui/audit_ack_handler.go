@@ -0,0 +1,183 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// auditAck acknowledges one audit finding so subsequent runs hide it instead
+// of re-triaging an accepted warning; empty Object matches every object of
+// the check, Until is a "2006-01-02" date after which the ack expires
+type auditAck struct {
+	ID     string `yaml:"id"`
+	Object string `yaml:"object,omitempty"`
+	Until  string `yaml:"until,omitempty"`
+	Reason string `yaml:"reason,omitempty"`
+}
+
+// auditAcksPath returns ~/.clickhouse-timeline/audit-acks.yaml
+func auditAcksPath() string {
+	home, homeErr := os.UserHomeDir()
+	if homeErr != nil {
+		log.Error().Err(homeErr).Msg("can't detect home directory for audit acks")
+		return ""
+	}
+	return filepath.Join(home, ".clickhouse-timeline", "audit-acks.yaml")
+}
+
+// loadAuditAcks reads the persisted acks, a missing file is not an error
+func loadAuditAcks() []auditAck {
+	path := auditAcksPath()
+	if path == "" {
+		return nil
+	}
+	data, readErr := os.ReadFile(path)
+	if readErr != nil {
+		if !os.IsNotExist(readErr) {
+			log.Error().Err(readErr).Str("path", path).Msg("can't read audit acks")
+		}
+		return nil
+	}
+	var acks []auditAck
+	if unmarshalErr := yaml.Unmarshal(data, &acks); unmarshalErr != nil {
+		log.Error().Err(unmarshalErr).Str("path", path).Msg("can't parse audit acks")
+		return nil
+	}
+	return acks
+}
+
+// saveAuditAcks persists the full ack list
+func saveAuditAcks(acks []auditAck) {
+	path := auditAcksPath()
+	if path == "" {
+		return
+	}
+	data, marshalErr := yaml.Marshal(acks)
+	if marshalErr != nil {
+		log.Error().Err(marshalErr).Msg("can't serialize audit acks")
+		return
+	}
+	if mkdirErr := os.MkdirAll(filepath.Dir(path), 0755); mkdirErr != nil {
+		log.Error().Err(mkdirErr).Str("path", path).Msg("can't create audit acks directory")
+		return
+	}
+	if writeErr := os.WriteFile(path, data, 0644); writeErr != nil {
+		log.Error().Err(writeErr).Str("path", path).Msg("can't write audit acks")
+	}
+}
+
+// ackExpired reports whether an ack's expiry date lies in the past; acks with
+// an unparseable date count as expired so a typo doesn't hide findings forever
+func ackExpired(ack auditAck) bool {
+	if ack.Until == "" {
+		return false
+	}
+	until, parseErr := time.Parse("2006-01-02", ack.Until)
+	if parseErr != nil {
+		log.Warn().Str("id", ack.ID).Str("until", ack.Until).Msg("audit ack has an invalid until date, treating as expired")
+		return true
+	}
+	return until.Before(time.Now())
+}
+
+// ackMatches reports whether an ack covers the given finding
+func ackMatches(ack auditAck, result AuditResult) bool {
+	return ack.ID == result.ID && (ack.Object == "" || ack.Object == result.Object)
+}
+
+// ackFor returns the first unexpired ack covering the finding, searching the
+// acks saved from the TUI first and the config file entries second
+func (ap *AuditPanel) ackFor(result AuditResult) *auditAck {
+	for i := range ap.acks {
+		if ackMatches(ap.acks[i], result) && !ackExpired(ap.acks[i]) {
+			return &ap.acks[i]
+		}
+	}
+	if ap.app != nil && ap.app.cfg != nil {
+		for _, cfgAck := range ap.app.cfg.AuditAcks {
+			ack := auditAck{ID: cfgAck.ID, Object: cfgAck.Object, Until: cfgAck.Until, Reason: cfgAck.Reason}
+			if ackMatches(ack, result) && !ackExpired(ack) {
+				matched := ack
+				return &matched
+			}
+		}
+	}
+	return nil
+}
+
+// toggleAck acknowledges the selected finding via a small form, or removes an
+// existing TUI-saved ack; config-file acks can only be removed in the config
+func (ap *AuditPanel) toggleAck() {
+	row, _ := ap.table.Table.GetSelection()
+	if row <= 0 || row >= len(ap.rowMeta) || ap.rowMeta[row] < 0 {
+		return
+	}
+	result := ap.results[ap.rowMeta[row]]
+
+	for i := range ap.acks {
+		if ackMatches(ap.acks[i], result) {
+			ap.acks = append(ap.acks[:i:i], ap.acks[i+1:]...)
+			saveAuditAcks(ap.acks)
+			ap.renderResults()
+			ap.updateAckStatus()
+			return
+		}
+	}
+	if ap.ackFor(result) != nil {
+		ap.statusText.SetText("[yellow]This finding is acknowledged in the config file, remove it there[white]")
+		return
+	}
+
+	until := ""
+	reason := ""
+	form := tview.NewForm().
+		AddInputField("Until (YYYY-MM-DD, empty = forever): ", "", 20, nil, func(text string) { until = text }).
+		AddInputField("Reason: ", "", 40, nil, func(text string) { reason = text })
+	form.AddButton("Acknowledge", func() {
+		if until != "" {
+			if _, parseErr := time.Parse("2006-01-02", until); parseErr != nil {
+				ap.statusText.SetText(fmt.Sprintf("[red]Invalid until date '%s', expected YYYY-MM-DD[white]", until))
+				return
+			}
+		}
+		ap.acks = append(ap.acks, auditAck{ID: result.ID, Object: result.Object, Until: until, Reason: reason})
+		saveAuditAcks(ap.acks)
+		ap.app.pages.RemovePage("auditAck")
+		ap.app.pages.SwitchToPage("audit")
+		ap.app.tviewApp.SetFocus(ap.table.Table)
+		ap.renderResults()
+		ap.updateAckStatus()
+	})
+	form.AddButton("Cancel", func() {
+		ap.app.pages.RemovePage("auditAck")
+		ap.app.pages.SwitchToPage("audit")
+		ap.app.tviewApp.SetFocus(ap.table.Table)
+	})
+	form.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Acknowledge %s on %s", result.ID, result.Object))
+
+	ap.app.pages.AddPage("auditAck", form, true, true)
+	ap.app.pages.SwitchToPage("auditAck")
+	ap.app.tviewApp.SetFocus(form)
+}
+
+// updateAckStatus reports in the status line how many findings are hidden
+func (ap *AuditPanel) updateAckStatus() {
+	hidden := 0
+	for _, result := range ap.results {
+		if ap.ackFor(result) != nil {
+			hidden++
+		}
+	}
+	if ap.showAcked {
+		ap.statusText.SetText(fmt.Sprintf("[yellow]Showing %d acknowledged findings (press 'k' to hide)[white]", hidden))
+	} else {
+		ap.statusText.SetText(fmt.Sprintf("[yellow]%d acknowledged findings hidden (press 'k' to show)[white]", hidden))
+	}
+}
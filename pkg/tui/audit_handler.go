@@ -0,0 +1,551 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/audit"
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// AuditResult is an alias for the engine result type, kept so panel code and
+// older callers keep reading naturally after the engine moved to pkg/audit
+type AuditResult = audit.Result
+
+// AuditPanel manages the audit interface
+type AuditPanel struct {
+	app         *App
+	auditor     *audit.Auditor
+	table       *widgets.FilteredTable
+	statusText  *tview.TextView
+	progressBar *tview.TextView
+	flex        *tview.Flex
+	results     []AuditResult
+	isRunning   bool
+	// rank findings by estimated blast radius instead of severity alone
+	sortByImpact bool
+	// group findings by check family with collapsible section headers
+	grouped   bool
+	collapsed map[string]bool
+	// result index per table row, -1 for header rows (filled by renderResults)
+	rowMeta []int
+	// acknowledged findings (accepted warnings) are hidden unless showAcked;
+	// acks saved from the TUI live in audit-acks.yaml, more come from config
+	acks      []auditAck
+	showAcked bool
+	// watch mode (audit --watch 5m) reruns the checks on this interval and
+	// highlights findings whose severity changed since the previous run
+	watchInterval time.Duration
+	watchStop     chan struct{}
+	prevSeverity  map[string]string
+	changed       map[string]string
+}
+
+// auditResultKey identifies one finding across watch reruns
+func auditResultKey(result AuditResult) string {
+	return result.ID + "\x00" + result.Host + "\x00" + result.Object
+}
+
+// ShowAudit displays the audit interface
+func (a *App) ShowAudit() {
+	if a.clickHouse == nil {
+		a.mainView.SetText("Error: Please connect to a ClickHouse instance first")
+		return
+	}
+
+	panel := &AuditPanel{
+		app:           a,
+		auditor:       audit.NewAuditor(a.clickHouse, a.cluster, a.cfg).WithQueryContext(a.queryContext).WithBackupAPI(a.backupAPIURL()),
+		acks:          loadAuditAcks(),
+		watchInterval: a.CLI.AuditWatch,
+	}
+	panel.setupUI()
+	panel.runAudit()
+	if panel.watchInterval > 0 {
+		panel.startWatch()
+	}
+}
+
+// startWatch reruns the audit on the configured interval until the panel is
+// left; a rerun is skipped while the previous one is still in flight
+func (ap *AuditPanel) startWatch() {
+	ap.watchStop = make(chan struct{})
+	stop := ap.watchStop
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(ap.watchInterval):
+				if !ap.isRunning {
+					ap.runAudit()
+				}
+			}
+		}
+	}()
+}
+
+func (ap *AuditPanel) setupUI() {
+	// Create filtered table for audit results
+	ap.table = widgets.NewFilteredTable()
+	ap.table.Table.SetBorders(false).SetSelectable(true, false)
+
+	// Set headers
+	ap.table.SetupHeaders(auditTableHeaders)
+
+	// Status text
+	ap.statusText = tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[yellow]Initializing audit...[white]")
+
+	// Progress bar
+	ap.progressBar = tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("")
+
+	// Create layout
+	ap.flex = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(ap.statusText, 1, 0, false).
+		AddItem(ap.progressBar, 1, 0, false).
+		AddItem(ap.table.Table, 0, 1, true)
+
+	ap.flex.SetBorder(true).SetTitle("ClickHouse System Audit")
+
+	// Add to pages
+	ap.app.pages.AddPage("audit", ap.flex, true, false)
+	ap.app.pages.SwitchToPage("audit")
+	ap.app.tviewApp.SetFocus(ap.table.Table)
+
+	// Setup key bindings with filtering support
+	ap.table.Table.SetInputCapture(ap.table.GetInputCapture(ap.app.tviewApp, ap.app.pages))
+
+	// Add custom key bindings for audit-specific actions
+	originalCapture := ap.table.Table.GetInputCapture()
+	ap.table.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			// leaving a running audit aborts its queries server-side and
+			// lets the worker pool drain the remaining checks
+			if ap.isRunning {
+				ap.app.cancelQueries()
+			}
+			if ap.watchStop != nil {
+				close(ap.watchStop)
+				ap.watchStop = nil
+			}
+			ap.app.pages.SwitchToPage("main")
+			ap.app.tviewApp.SetFocus(ap.app.mainView)
+			return nil
+		case tcell.KeyEnter:
+			// On a group header Enter collapses/expands the section,
+			// on a finding row it opens the details view
+			row, _ := ap.table.Table.GetSelection()
+			if group := ap.groupAt(row); group != "" {
+				if ap.collapsed == nil {
+					ap.collapsed = make(map[string]bool)
+				}
+				ap.collapsed[group] = !ap.collapsed[group]
+				ap.renderResults()
+				return nil
+			}
+			ap.showResultDetails()
+			return nil
+		}
+		switch event.Rune() {
+		case 'i':
+			// Toggle between severity ordering and blast-radius (impact) ordering
+			ap.sortByImpact = !ap.sortByImpact
+			ap.renderResults()
+			return nil
+		case 'g':
+			// Toggle grouping by check family
+			ap.grouped = !ap.grouped
+			ap.renderResults()
+			return nil
+		case 'n':
+			ap.selectAdjacentGroup(1)
+			return nil
+		case 'p':
+			ap.selectAdjacentGroup(-1)
+			return nil
+		case 'a':
+			// Acknowledge the selected finding, or remove its ack again
+			ap.toggleAck()
+			return nil
+		case 'k':
+			// Toggle whether acknowledged findings are listed
+			ap.showAcked = !ap.showAcked
+			ap.renderResults()
+			ap.updateAckStatus()
+			return nil
+		}
+		// Let the filtered table handle other keys (like '/' for filtering)
+		if originalCapture != nil {
+			return originalCapture(event)
+		}
+		return event
+	})
+}
+
+func (ap *AuditPanel) updateProgress(message string, step, total int) {
+	ap.app.tviewApp.QueueUpdateDraw(func() {
+		ap.statusText.SetText(fmt.Sprintf("[yellow]%s[white]", message))
+		if total > 0 {
+			progress := float64(step) / float64(total) * 100
+			progressBar := strings.Repeat("█", int(progress/5)) + strings.Repeat("░", 20-int(progress/5))
+			ap.progressBar.SetText(fmt.Sprintf("[green]%s[white] %.1f%%", progressBar, progress))
+		}
+	})
+}
+
+func (ap *AuditPanel) runAudit() {
+	ap.isRunning = true
+	go func() {
+		defer func() {
+			ap.isRunning = false
+		}()
+
+		var totalChecks int
+		allResults := ap.auditor.RunChecks(func(name string, step, total int) {
+			totalChecks = total
+			ap.updateProgress(fmt.Sprintf("Finished %s checks (%d/%d)...", name, step, total), step, total)
+		})
+
+		ap.updateProgress("Audit completed", totalChecks, totalChecks)
+		ap.displayResults(allResults)
+	}()
+}
+
+var auditTableHeaders = []string{"ID", "Host", "Severity", "Impact", "Object", "Details"}
+
+// renderResults re-sorts ap.results by the current sort mode and rebuilds the
+// table, optionally grouping findings into collapsible check family sections.
+// Must be called from the UI goroutine.
+func (ap *AuditPanel) renderResults() {
+	if ap.sortByImpact {
+		audit.SortResultsByImpact(ap.results)
+	} else {
+		audit.SortResults(ap.results)
+	}
+
+	ap.table.Table.Clear()
+	ap.table.OriginalRows = nil
+	ap.table.SetupHeaders(auditTableHeaders)
+	ap.rowMeta = []int{-1} // row 0 is the table header
+
+	if !ap.grouped {
+		for i, result := range ap.results {
+			if !ap.showAcked && ap.ackFor(result) != nil {
+				continue
+			}
+			ap.addResultRow(result)
+			ap.rowMeta = append(ap.rowMeta, i)
+		}
+		return
+	}
+
+	// Preserve the sort order inside each group, order groups by first appearance
+	var groupOrder []string
+	grouped := make(map[string][]int)
+	for i, result := range ap.results {
+		if !ap.showAcked && ap.ackFor(result) != nil {
+			continue
+		}
+		if _, seen := grouped[result.Group]; !seen {
+			groupOrder = append(groupOrder, result.Group)
+		}
+		grouped[result.Group] = append(grouped[result.Group], i)
+	}
+
+	for _, group := range groupOrder {
+		indexes := grouped[group]
+
+		severityCounts := make(map[string]int)
+		for _, idx := range indexes {
+			severityCounts[ap.results[idx].Severity]++
+		}
+
+		marker := "▼"
+		if ap.collapsed[group] {
+			marker = "▶"
+		}
+		summary := fmt.Sprintf("Critical: %d | Major: %d | Moderate: %d | Minor: %d | Total: %d",
+			severityCounts["Critical"], severityCounts["Major"], severityCounts["Moderate"], severityCounts["Minor"], len(indexes))
+
+		headerCells := []*tview.TableCell{
+			tview.NewTableCell(fmt.Sprintf("%s %s", marker, group)).SetTextColor(tcell.ColorAqua).SetAttributes(tcell.AttrBold),
+			tview.NewTableCell("").SetTextColor(tcell.ColorAqua),
+			tview.NewTableCell("").SetTextColor(tcell.ColorAqua),
+			tview.NewTableCell("").SetTextColor(tcell.ColorAqua),
+			tview.NewTableCell("").SetTextColor(tcell.ColorAqua),
+			tview.NewTableCell(summary).SetTextColor(tcell.ColorAqua),
+		}
+		ap.table.AddRow(headerCells)
+		ap.rowMeta = append(ap.rowMeta, -1)
+
+		if ap.collapsed[group] {
+			continue
+		}
+		for _, idx := range indexes {
+			ap.addResultRow(ap.results[idx])
+			ap.rowMeta = append(ap.rowMeta, idx)
+		}
+	}
+}
+
+// addResultRow appends one finding row to the table
+func (ap *AuditPanel) addResultRow(result AuditResult) {
+	// Color code by severity through the active theme
+	color := severityColor(result.Severity)
+
+	// Truncate details if too long
+	details := result.Details
+	if len(details) > 256 {
+		details = details[:255] + "..."
+	}
+
+	// Acknowledged findings only show up with 'k' and are dimmed as known
+	attrsOverride := false
+	if ack := ap.ackFor(result); ack != nil {
+		color = tcell.ColorGray
+		attrsOverride = true
+		ackInfo := "acked"
+		if ack.Until != "" {
+			ackInfo += " until " + ack.Until
+		}
+		if ack.Reason != "" {
+			ackInfo += ": " + ack.Reason
+		}
+		details = fmt.Sprintf("[%s] %s", ackInfo, details)
+	}
+
+	impact := ""
+	if impactValue := audit.Impact(result); impactValue > 0 {
+		impact = utils.FormatReadable(impactValue, 1)
+	}
+
+	// Create row cells; symbol themes mark severity with a prefix and bold
+	// so the ranking survives without color
+	attrs := severityAttrs(result.Severity)
+	if attrsOverride {
+		attrs = tcell.AttrNone
+	}
+
+	// Findings that appeared or changed severity since the previous watch run
+	// stand out in bold with the old severity inlined
+	if change, found := ap.changed[auditResultKey(result)]; found {
+		if change == "new" {
+			details = "[new since last run] " + details
+		} else {
+			details = fmt.Sprintf("[was %s] %s", change, details)
+		}
+		attrs |= tcell.AttrBold
+	}
+
+	cells := []*tview.TableCell{
+		tview.NewTableCell(result.ID).SetTextColor(color).SetAttributes(attrs),
+		tview.NewTableCell(result.Host).SetTextColor(color).SetAttributes(attrs),
+		tview.NewTableCell(severityMarker(result.Severity) + result.Severity).SetTextColor(color).SetAttributes(attrs),
+		tview.NewTableCell(impact).SetTextColor(color).SetAlign(tview.AlignRight).SetAttributes(attrs),
+		tview.NewTableCell(result.Object).SetTextColor(color).SetAttributes(attrs),
+		tview.NewTableCell(details).SetTextColor(color).SetAttributes(attrs),
+	}
+
+	ap.table.AddRow(cells)
+}
+
+// saveRemediation writes the finding's remediation template next to the
+// working directory as audit-<ID>-remediation.sql and reports the outcome
+func (ap *AuditPanel) saveRemediation(result AuditResult) string {
+	path := fmt.Sprintf("audit-%s-remediation.sql", result.ID)
+	content := fmt.Sprintf("-- %s on %s: %s\n%s\n", result.ID, result.Object, result.Details, result.Remediation)
+	if writeErr := os.WriteFile(path, []byte(content), 0644); writeErr != nil {
+		return fmt.Sprintf("can't write %s: %v", path, writeErr)
+	}
+	return "saved to " + path
+}
+
+// groupAt returns the group name of the header row at the given table row, or ""
+func (ap *AuditPanel) groupAt(row int) string {
+	if !ap.grouped || row <= 0 || row >= len(ap.rowMeta) || ap.rowMeta[row] != -1 {
+		return ""
+	}
+	text := ap.table.Table.GetCell(row, 0).Text
+	return strings.TrimSpace(strings.TrimLeft(text, "▼▶ "))
+}
+
+// selectAdjacentGroup moves the selection to the next (direction > 0) or
+// previous (direction < 0) group header row
+func (ap *AuditPanel) selectAdjacentGroup(direction int) {
+	if !ap.grouped {
+		return
+	}
+	row, col := ap.table.Table.GetSelection()
+	for next := row + direction; next > 0 && next < len(ap.rowMeta); next += direction {
+		if ap.rowMeta[next] == -1 {
+			ap.table.Table.Select(next, col)
+			return
+		}
+	}
+}
+
+func (ap *AuditPanel) displayResults(results []AuditResult) {
+	ap.app.tviewApp.QueueUpdateDraw(func() {
+		ap.results = results
+
+		// Mark findings whose severity changed since the previous watch run;
+		// the first run has nothing to compare against
+		current := make(map[string]string, len(results))
+		for _, result := range results {
+			current[auditResultKey(result)] = result.Severity
+		}
+		ap.changed = make(map[string]string)
+		if ap.prevSeverity != nil {
+			for key, severity := range current {
+				old, seen := ap.prevSeverity[key]
+				if !seen {
+					ap.changed[key] = "new"
+				} else if old != severity {
+					ap.changed[key] = old
+				}
+			}
+		}
+		ap.prevSeverity = current
+
+		ap.renderResults()
+
+		// Update status
+		criticalCount := 0
+		majorCount := 0
+		moderateCount := 0
+		minorCount := 0
+
+		for _, result := range results {
+			switch result.Severity {
+			case "Critical":
+				criticalCount++
+			case "Major":
+				majorCount++
+			case "Moderate":
+				moderateCount++
+			case "Minor":
+				minorCount++
+			}
+		}
+
+		statusMsg := fmt.Sprintf("[red]Critical: %d[white] | [orange]Major: %d[white] | [yellow]Moderate: %d[white] | [green]Minor: %d[white] | Total: %d issues found",
+			criticalCount, majorCount, moderateCount, minorCount, len(results))
+		if ap.watchInterval > 0 {
+			statusMsg += fmt.Sprintf(" | watch: every %s, %d changed", ap.watchInterval, len(ap.changed))
+		}
+
+		ap.statusText.SetText(statusMsg)
+		ap.progressBar.SetText("[green]Enter: details/collapse, 'i': impact sort, 'g': group by check, 'n'/'p': next/prev group, 'a': ack, 'k': show acked, Esc: return[white]")
+	})
+}
+
+// drillAction maps a finding to the panel that helps investigate it further,
+// turning the audit into the entrypoint of a guided investigation; it returns
+// a nil action when no contextual panel exists for the finding
+func (ap *AuditPanel) drillAction(result AuditResult) (string, func()) {
+	switch {
+	case strings.HasPrefix(result.ID, "A0.1.06"):
+		return "per-table cleanup advisor", ap.showInactivePartsAdvisor
+	case strings.HasPrefix(result.ID, "A1.2") || strings.HasPrefix(result.ID, "A1.5.01") || strings.HasPrefix(result.ID, "A1.5.02"):
+		return "cache metrics chart", func() {
+			ap.app.ShowAsynchronousMetricLog(ap.app.fromTime, ap.app.toTime, ap.app.cluster)
+		}
+	case strings.HasPrefix(result.ID, "A1.7") || result.ID == "A3.0.15":
+		return "memory panel", ap.app.ShowMemory
+	case strings.HasPrefix(result.ID, "A1.6"):
+		return "replication lag dashboard", ap.app.ShowReplicationLag
+	case strings.HasPrefix(result.ID, "A1.4"):
+		return "background pool metrics", func() {
+			ap.app.ShowMetricLog(ap.app.fromTime, ap.app.toTime, ap.app.cluster)
+		}
+	}
+	return "", nil
+}
+
+func (ap *AuditPanel) showResultDetails() {
+	row, _ := ap.table.Table.GetSelection()
+	if row <= 0 || row >= len(ap.rowMeta) || ap.rowMeta[row] < 0 {
+		return
+	}
+
+	result := ap.results[ap.rowMeta[row]]
+
+	details := fmt.Sprintf(`[yellow::b]Audit Result Details[white::-]
+
+[yellow]ID:[white] %s
+[yellow]Host:[white] %s
+[yellow]Severity:[white] %s
+[yellow]Object:[white] %s
+
+[yellow]Details:[white]
+%s
+
+[yellow]Values:[white]`, result.ID, result.Host, result.Severity, result.Object, result.Details)
+
+	if len(result.Values) > 0 {
+		for key, value := range result.Values {
+			details += fmt.Sprintf("\n  %s: %.2f", key, value)
+		}
+	} else {
+		details += "\n  No additional values"
+	}
+
+	if result.Remediation != "" {
+		details += fmt.Sprintf("\n\n[yellow]Remediation:[white]\n%s", result.Remediation)
+	}
+
+	details += "\n\n[green]Press Esc to return, Ctrl+Y to copy details[white]"
+	if result.Remediation != "" {
+		details += "\n[green]Ctrl+R copies the remediation SQL, Ctrl+S saves it to a .sql file[white]"
+	}
+	drillLabel, drillFn := ap.drillAction(result)
+	if drillFn != nil {
+		details += fmt.Sprintf("\n[green]Press Enter to open the %s[white]", drillLabel)
+	}
+
+	detailView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(details).
+		SetBorder(true).
+		SetTitle("Audit Result Details")
+
+	detailView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			ap.app.pages.SwitchToPage("audit")
+			ap.app.tviewApp.SetFocus(ap.table.Table)
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlY {
+			detailView.SetTitle(fmt.Sprintf("Audit Result Details (%s)", ap.app.copyToClipboard(result.Details)))
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlR && result.Remediation != "" {
+			detailView.SetTitle(fmt.Sprintf("Audit Result Details (%s)", ap.app.copyToClipboard(result.Remediation)))
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlS && result.Remediation != "" {
+			detailView.SetTitle(fmt.Sprintf("Audit Result Details (%s)", ap.saveRemediation(result)))
+			return nil
+		}
+		if event.Key() == tcell.KeyEnter && drillFn != nil {
+			drillFn()
+			return nil
+		}
+		return event
+	})
+
+	ap.app.pages.AddPage("audit_detail", detailView, true, false)
+	ap.app.pages.SwitchToPage("audit_detail")
+	ap.app.tviewApp.SetFocus(detailView)
+}
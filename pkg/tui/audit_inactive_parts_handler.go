@@ -0,0 +1,209 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// inactivePartsStat aggregates the obsolete inactive parts of one table on one
+// host, as counted by the A0.1.06 audit check
+type inactivePartsStat struct {
+	host     string
+	database string
+	table    string
+	parts    uint64
+	bytes    uint64
+	oldest   time.Time
+}
+
+// showInactivePartsAdvisor breaks the obsolete-inactive-parts finding down per
+// table with age and size, and generates safe cleanup guidance for the
+// selected table instead of leaving the operator with only a count
+func (ap *AuditPanel) showInactivePartsAdvisor() {
+	query := fmt.Sprintf(`
+		WITH (SELECT max(modification_time) FROM cluster('%s', system.parts)) AS max_ts
+		SELECT hostName() AS host, database, table, count() AS parts,
+			sum(bytes_on_disk) AS bytes, min(modification_time) AS oldest
+		FROM cluster('%s', system.parts)
+		WHERE NOT active
+		AND ((remove_time > 0 AND remove_time < max_ts - INTERVAL 20 MINUTE)
+		     OR (remove_time = 0 AND modification_time < max_ts - INTERVAL 20 MINUTE))
+		GROUP BY host, database, table
+		ORDER BY parts DESC
+		LIMIT 100
+	`, chquery.EscapeString(ap.app.cluster), chquery.EscapeString(ap.app.cluster))
+
+	go func() {
+		rows, err := ap.app.clickHouse.QueryContext(ap.app.queryContext(), query)
+		if err != nil {
+			ap.app.tviewApp.QueueUpdateDraw(func() {
+				ap.app.SwitchToMainPage(fmt.Sprintf("showInactivePartsAdvisor query failed: %v", err))
+			})
+			return
+		}
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msgf("can't close showInactivePartsAdvisor rows")
+			}
+		}()
+
+		var stats []*inactivePartsStat
+		for rows.Next() {
+			stat := &inactivePartsStat{}
+			if scanErr := rows.Scan(&stat.host, &stat.database, &stat.table, &stat.parts, &stat.bytes, &stat.oldest); scanErr != nil {
+				log.Error().Err(scanErr).Msg("can't scan inactive parts row")
+				continue
+			}
+			stats = append(stats, stat)
+		}
+
+		oldPartsLifetime := ap.fetchOldPartsLifetime()
+
+		ap.app.tviewApp.QueueUpdateDraw(func() {
+			ap.renderInactivePartsAdvisor(stats, oldPartsLifetime)
+		})
+	}()
+}
+
+// fetchOldPartsLifetime reads the server-wide old_parts_lifetime MergeTree
+// setting; an empty string means it could not be determined
+func (ap *AuditPanel) fetchOldPartsLifetime() string {
+	var value string
+	row := ap.app.clickHouse.QueryRowContext(ap.app.queryContext(),
+		"SELECT value FROM system.merge_tree_settings WHERE name = 'old_parts_lifetime'")
+	if row == nil {
+		return ""
+	}
+	if err := row.Scan(&value); err != nil {
+		log.Error().Err(err).Msg("can't fetch old_parts_lifetime")
+		return ""
+	}
+	return value
+}
+
+// renderInactivePartsAdvisor shows the per-table breakdown, Enter opens the
+// generated cleanup guidance for the selected table
+func (ap *AuditPanel) renderInactivePartsAdvisor(stats []*inactivePartsStat, oldPartsLifetime string) {
+	filteredTable := widgets.NewFilteredTable()
+	filteredTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	filteredTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle("Obsolete inactive parts per table [yellow](Enter: cleanup guidance, Esc: return)[-]")
+	filteredTable.SetupHeaders([]string{"Host", "Table", "Parts", "Size", "Oldest"})
+
+	for _, stat := range stats {
+		color := tcell.ColorWhite
+		switch {
+		case stat.parts >= 2000:
+			color = tcell.ColorRed
+		case stat.parts >= 500:
+			color = tcell.ColorYellow
+		}
+		filteredTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(stat.host).
+				SetTextColor(color).
+				SetAlign(tview.AlignLeft),
+			tview.NewTableCell(fmt.Sprintf("%s.%s", stat.database, stat.table)).
+				SetTextColor(color).
+				SetAlign(tview.AlignLeft),
+			tview.NewTableCell(fmt.Sprintf("%d", stat.parts)).
+				SetTextColor(color).
+				SetAlign(tview.AlignRight),
+			tview.NewTableCell(fmt.Sprintf("%sB", utils.FormatReadable(float64(stat.bytes), 1))).
+				SetTextColor(color).
+				SetAlign(tview.AlignRight),
+			tview.NewTableCell(fmt.Sprintf("%s (%s ago)",
+				utils.FormatTimestampShort(stat.oldest),
+				time.Since(stat.oldest).Round(time.Minute))).
+				SetTextColor(color).
+				SetAlign(tview.AlignLeft),
+		})
+	}
+
+	existingHandler := filteredTable.GetInputCapture(ap.app.tviewApp, ap.app.pages)
+	filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if result := existingHandler(event); result == nil {
+			return nil
+		}
+		switch event.Key() {
+		case tcell.KeyEnter:
+			row, _ := filteredTable.Table.GetSelection()
+			if row > 0 && row <= len(stats) {
+				ap.showInactivePartsGuidance(stats[row-1], oldPartsLifetime)
+			}
+			return nil
+		case tcell.KeyEscape:
+			ap.app.pages.RemovePage("auditInactiveParts")
+			ap.app.pages.SwitchToPage("audit")
+			ap.app.tviewApp.SetFocus(ap.table.Table)
+			return nil
+		}
+		return event
+	})
+
+	ap.app.pages.AddPage("auditInactiveParts", filteredTable.Table, true, true)
+	ap.app.pages.SwitchToPage("auditInactiveParts")
+	ap.app.tviewApp.SetFocus(filteredTable.Table)
+}
+
+// showInactivePartsGuidance renders the generated cleanup statements and the
+// surrounding safety context for one table
+func (ap *AuditPanel) showInactivePartsGuidance(stat *inactivePartsStat, oldPartsLifetime string) {
+	qualified := chquery.QualifiedName(stat.database, stat.table)
+	lifetime := oldPartsLifetime
+	if lifetime == "" {
+		lifetime = "unknown"
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("[yellow::b]Inactive parts cleanup for %s on %s[white::-]\n\n", qualified, stat.host))
+	builder.WriteString(fmt.Sprintf("%d inactive parts, %sB on disk, oldest from %s.\n\n",
+		stat.parts, utils.FormatReadable(float64(stat.bytes), 1), utils.FormatTimestamp(stat.oldest)))
+	builder.WriteString("Inactive parts are former merge sources kept for old_parts_lifetime\n")
+	builder.WriteString(fmt.Sprintf("(currently %s seconds) and then removed by a background thread.\n", lifetime))
+	builder.WriteString("Parts lingering far beyond that usually mean merges were stopped, the\n")
+	builder.WriteString("background pool is saturated, or the parts are still referenced by\n")
+	builder.WriteString("long-running queries.\n\n")
+	builder.WriteString("[yellow]Safe steps, in order:[white]\n\n")
+	builder.WriteString(fmt.Sprintf("  -- resume merges if they were stopped for maintenance\n  SYSTEM START MERGES %s;\n\n", qualified))
+	builder.WriteString(fmt.Sprintf("  -- check for long-running queries still holding old parts\n  SELECT query_id, elapsed FROM system.processes WHERE query ILIKE '%%%s%%';\n\n", stat.table))
+	builder.WriteString("  -- review old_parts_lifetime before lowering it; values below the\n")
+	builder.WriteString("  -- default (480s) trade crash-recovery safety for disk space\n")
+	builder.WriteString("  SELECT value, changed FROM system.merge_tree_settings WHERE name = 'old_parts_lifetime';\n\n")
+	builder.WriteString("[red]Never delete part directories from disk manually while the server is\nrunning, and avoid SYSTEM STOP MERGES on production tables: it is the\nmost common reason inactive parts accumulate in the first place.[white]\n")
+	builder.WriteString("\n[green]Press Esc to return, Ctrl+Y to copy the statements[white]")
+
+	guidance := builder.String()
+	guidanceView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(guidance).
+		SetBorder(true).
+		SetTitle("Inactive Parts Cleanup Guidance")
+
+	guidanceView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			ap.app.pages.RemovePage("auditInactivePartsGuidance")
+			ap.app.pages.SwitchToPage("auditInactiveParts")
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlY {
+			guidanceView.SetTitle(fmt.Sprintf("Inactive Parts Cleanup Guidance (%s)",
+				ap.app.copyToClipboard(fmt.Sprintf("SYSTEM START MERGES %s;", qualified))))
+			return nil
+		}
+		return event
+	})
+
+	ap.app.pages.AddPage("auditInactivePartsGuidance", guidanceView, true, true)
+	ap.app.pages.SwitchToPage("auditInactivePartsGuidance")
+	ap.app.tviewApp.SetFocus(guidanceView)
+}
@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/audit"
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// backupsRefreshInterval is how often system.backups is re-polled while the
+// status page is open, so a running BACKUP/RESTORE shows up without reopening
+const backupsRefreshInterval = 5 * time.Second
+
+// backupStatusRow is one BACKUP/RESTORE operation from system.backups, or one
+// entry of the clickhouse-backup API list when the context configures an
+// endpoint
+type backupStatusRow struct {
+	host      string
+	name      string
+	status    string
+	startTime string
+	endTime   string
+	size      float64
+	errorText string
+}
+
+// backupAPIURL returns the clickhouse-backup API endpoint of the connected
+// context, empty when none is configured
+func (a *App) backupAPIURL() string {
+	if a.selectedContext == nil {
+		return ""
+	}
+	return a.selectedContext.BackupAPIURL
+}
+
+// backupStatusColor maps operation status to the audit severity colors:
+// failures critical, in-flight operations highlighted, finished ones ok
+func backupStatusColor(status string) tcell.Color {
+	switch status {
+	case "BACKUP_FAILED", "RESTORE_FAILED":
+		return currentTheme.critical
+	case "CREATING_BACKUP", "RESTORING":
+		return currentTheme.warning
+	case "BACKUP_CREATED", "RESTORED":
+		return currentTheme.ok
+	}
+	return currentTheme.text
+}
+
+// ShowBackups opens the backup/restore status page: every BACKUP and RESTORE
+// known to system.backups across the cluster, refreshed periodically, plus
+// the remote backup list from the clickhouse-backup API when the context
+// configures one; the A4.0 audit checks flag the same data
+func (a *App) ShowBackups() {
+	filteredTable := widgets.NewFilteredTable()
+	filteredTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	filteredTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Backups | refresh every %s [yellow](Esc: return)[-]", backupsRefreshInterval))
+	filteredTable.SetupHeaders([]string{"Host", "Name", "Status", "Started", "Finished", "Size", "Error"})
+
+	stop := make(chan struct{})
+	stopped := false
+	existingHandler := filteredTable.GetInputCapture(a.tviewApp, a.pages)
+	filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if result := existingHandler(event); result == nil {
+			return nil
+		}
+		if event.Key() == tcell.KeyEscape {
+			if !stopped {
+				stopped = true
+				close(stop)
+			}
+			a.pages.RemovePage("backups")
+			a.SwitchToMainPage("Returned from :backups")
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("backups", filteredTable.Table, true, true)
+	a.pages.SwitchToPage("backups")
+	a.tviewApp.SetFocus(filteredTable.Table)
+
+	go func() {
+		for {
+			snapshot := a.fetchBackupsSnapshot()
+			a.tviewApp.QueueUpdateDraw(func() {
+				a.renderBackups(filteredTable, snapshot)
+			})
+			select {
+			case <-stop:
+				return
+			case <-time.After(backupsRefreshInterval):
+			}
+		}
+	}()
+}
+
+// fetchBackupsSnapshot loads the operation states across the cluster; servers
+// without system.backups (or with no operations yet) yield an empty list
+func (a *App) fetchBackupsSnapshot() []backupStatusRow {
+	var snapshot []backupStatusRow
+
+	query := fmt.Sprintf(
+		"SELECT hostName() AS host, name, toString(status) AS status, "+
+			"toString(start_time) AS start_time, toString(end_time) AS end_time, "+
+			"toFloat64(total_size) AS total_size, error "+
+			"FROM clusterAllReplicas('%s', merge(system,'^backups$')) "+
+			"SETTINGS skip_unavailable_shards=1",
+		a.cluster)
+
+	rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("backups"), query)
+	if err != nil {
+		log.Error().Err(err).Msg("can't fetch backups snapshot")
+	} else {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close fetchBackupsSnapshot rows")
+			}
+		}()
+		for rows.Next() {
+			var row backupStatusRow
+			if scanErr := rows.Scan(&row.host, &row.name, &row.status, &row.startTime, &row.endTime, &row.size, &row.errorText); scanErr != nil {
+				log.Error().Err(scanErr).Msg("can't scan backups snapshot row")
+				continue
+			}
+			snapshot = append(snapshot, row)
+		}
+		sort.Slice(snapshot, func(i, j int) bool {
+			if snapshot[i].startTime != snapshot[j].startTime {
+				return snapshot[i].startTime > snapshot[j].startTime
+			}
+			return snapshot[i].host < snapshot[j].host
+		})
+	}
+
+	// The API list goes below the live operations: its entries are finished
+	// backups, the Status column carries the location instead
+	if url := a.backupAPIURL(); url != "" {
+		items, apiErr := audit.FetchBackupList(url)
+		if apiErr != nil {
+			log.Warn().Err(apiErr).Str("url", url).Msg("can't fetch clickhouse-backup list")
+		}
+		for _, item := range items {
+			snapshot = append(snapshot, backupStatusRow{
+				host:      "clickhouse-backup",
+				name:      item.Name,
+				status:    item.Location,
+				startTime: item.Created,
+				size:      float64(item.Size),
+			})
+		}
+	}
+
+	return snapshot
+}
+
+// renderBackups repopulates the status table from the latest poll, keeping
+// the current selection where possible
+func (a *App) renderBackups(filteredTable *widgets.FilteredTable, snapshot []backupStatusRow) {
+	selectedRow, selectedCol := filteredTable.Table.GetSelection()
+	filteredTable.Table.Clear()
+	filteredTable.OriginalRows = nil
+	filteredTable.SetupHeaders(filteredTable.Headers)
+
+	for _, row := range snapshot {
+		color := backupStatusColor(row.status)
+		size := ""
+		if row.size > 0 {
+			size = utils.FormatReadable(row.size, 1) + "B"
+		}
+		filteredTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(row.host).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(row.name).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(row.status).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(row.startTime).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(row.endTime).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(size).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(row.errorText).SetTextColor(color).SetAlign(tview.AlignLeft),
+		})
+	}
+
+	if selectedRow > 0 && selectedRow < filteredTable.Table.GetRowCount() {
+		filteredTable.Table.Select(selectedRow, selectedCol)
+	} else if filteredTable.Table.GetRowCount() > 1 {
+		filteredTable.Table.Select(1, 0)
+	}
+}
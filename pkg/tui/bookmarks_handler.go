@@ -0,0 +1,219 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// quickRanges are the relative ranges offered at the top of the bookmarks
+// menu; the full Grafana-like list stays in :range, these are the ones worth
+// one keystroke during an incident
+var quickRanges = []string{
+	"Last 15 minutes",
+	"Last 1 hour",
+	"Last 3 hours",
+	"Last 6 hours",
+	"Last 24 hours",
+	"Last 7 days",
+}
+
+// timeRangeBookmark is one named absolute time range persisted on disk, e.g.
+// "incident 2024-06-01", so a range under investigation can be revisited later
+type timeRangeBookmark struct {
+	Name string    `yaml:"name"`
+	From time.Time `yaml:"from"`
+	To   time.Time `yaml:"to"`
+}
+
+// rangeBookmarksPath returns ~/.clickhouse-timeline/range-bookmarks.yaml
+func rangeBookmarksPath() string {
+	home, homeErr := os.UserHomeDir()
+	if homeErr != nil {
+		log.Error().Err(homeErr).Msg("can't detect home directory for range bookmarks")
+		return ""
+	}
+	return filepath.Join(home, ".clickhouse-timeline", "range-bookmarks.yaml")
+}
+
+// loadRangeBookmarks reads the persisted bookmarks, a missing file is not an error
+func loadRangeBookmarks() []timeRangeBookmark {
+	path := rangeBookmarksPath()
+	if path == "" {
+		return nil
+	}
+	data, readErr := os.ReadFile(path)
+	if readErr != nil {
+		if !os.IsNotExist(readErr) {
+			log.Error().Err(readErr).Str("path", path).Msg("can't read range bookmarks")
+		}
+		return nil
+	}
+	var bookmarks []timeRangeBookmark
+	if unmarshalErr := yaml.Unmarshal(data, &bookmarks); unmarshalErr != nil {
+		log.Error().Err(unmarshalErr).Str("path", path).Msg("can't parse range bookmarks")
+		return nil
+	}
+	return bookmarks
+}
+
+// saveRangeBookmarks persists the full bookmark list
+func saveRangeBookmarks(bookmarks []timeRangeBookmark) {
+	path := rangeBookmarksPath()
+	if path == "" {
+		return
+	}
+	data, marshalErr := yaml.Marshal(bookmarks)
+	if marshalErr != nil {
+		log.Error().Err(marshalErr).Msg("can't serialize range bookmarks")
+		return
+	}
+	if mkdirErr := os.MkdirAll(filepath.Dir(path), 0755); mkdirErr != nil {
+		log.Error().Err(mkdirErr).Str("path", path).Msg("can't create range bookmarks directory")
+		return
+	}
+	if writeErr := os.WriteFile(path, data, 0644); writeErr != nil {
+		log.Error().Err(writeErr).Str("path", path).Msg("can't write range bookmarks")
+	}
+}
+
+// showBookmarksMenu opens a quick menu of relative ranges and saved
+// time-range bookmarks; Enter applies the selection to the shared from/to
+// range every page uses, 's' saves the current range under a name
+func (a *App) showBookmarksMenu() {
+	filteredTable := widgets.NewFilteredTable()
+	filteredTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	filteredTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle("Time ranges [yellow](Enter: apply, s: save current range, d: delete bookmark, Esc: return)[-]")
+	filteredTable.SetupHeaders([]string{"Name", "From", "To"})
+
+	var bookmarks []timeRangeBookmark
+
+	render := func() {
+		bookmarks = loadRangeBookmarks()
+		filteredTable.Table.Clear()
+		filteredTable.OriginalRows = nil
+		filteredTable.SetupHeaders(filteredTable.Headers)
+		for _, option := range quickRanges {
+			filteredTable.AddRow([]*tview.TableCell{
+				tview.NewTableCell(option).SetTextColor(tcell.ColorAqua),
+				tview.NewTableCell("relative").SetTextColor(tcell.ColorAqua),
+				tview.NewTableCell("now").SetTextColor(tcell.ColorAqua),
+			})
+		}
+		for _, bookmark := range bookmarks {
+			filteredTable.AddRow([]*tview.TableCell{
+				tview.NewTableCell(bookmark.Name).SetTextColor(tcell.ColorWhite),
+				tview.NewTableCell(utils.FormatTimestamp(bookmark.From)).SetTextColor(tcell.ColorWhite),
+				tview.NewTableCell(utils.FormatTimestamp(bookmark.To)).SetTextColor(tcell.ColorWhite),
+			})
+		}
+	}
+	render()
+
+	existingHandler := filteredTable.GetInputCapture(a.tviewApp, a.pages)
+	filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if result := existingHandler(event); result == nil {
+			return nil
+		}
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.pages.RemovePage("bookmarks")
+			a.pages.SwitchToPage("main")
+			return nil
+		case tcell.KeyEnter:
+			row, _ := filteredTable.Table.GetSelection()
+			switch {
+			case row >= 1 && row <= len(quickRanges):
+				a.applyPredefinedRange(quickRanges[row-1])
+			case row > len(quickRanges) && row <= len(quickRanges)+len(bookmarks):
+				bookmark := bookmarks[row-1-len(quickRanges)]
+				a.fromTime = bookmark.From
+				a.toTime = bookmark.To
+			default:
+				return nil
+			}
+			a.pages.RemovePage("bookmarks")
+			a.SwitchToMainPage(fmt.Sprintf("Time range set to: %s", a.formatTimeRange()))
+			return nil
+		}
+		switch event.Rune() {
+		case 's':
+			a.showBookmarkSaveForm(render, filteredTable)
+			return nil
+		case 'd':
+			row, _ := filteredTable.Table.GetSelection()
+			if row > len(quickRanges) && row <= len(quickRanges)+len(bookmarks) {
+				index := row - 1 - len(quickRanges)
+				saveRangeBookmarks(append(bookmarks[:index:index], bookmarks[index+1:]...))
+				render()
+			}
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("bookmarks", filteredTable.Table, true, true)
+	a.pages.SwitchToPage("bookmarks")
+	a.tviewApp.SetFocus(filteredTable.Table)
+}
+
+// showBookmarkSaveForm asks for a name and bookmarks the current from/to
+// range under it; an existing bookmark with the same name is replaced
+func (a *App) showBookmarkSaveForm(render func(), filteredTable *widgets.FilteredTable) {
+	nameInput := tview.NewInputField().
+		SetLabel("Bookmark name: ").
+		SetFieldWidth(40)
+
+	closeForm := func() {
+		a.pages.RemovePage("bookmarkSave")
+		a.pages.SwitchToPage("bookmarks")
+		a.tviewApp.SetFocus(filteredTable.Table)
+	}
+
+	nameInput.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			closeForm()
+			return
+		}
+		if key != tcell.KeyEnter {
+			return
+		}
+		name := nameInput.GetText()
+		if name == "" {
+			return
+		}
+		bookmarks := loadRangeBookmarks()
+		kept := make([]timeRangeBookmark, 0, len(bookmarks)+1)
+		for _, bookmark := range bookmarks {
+			if bookmark.Name != name {
+				kept = append(kept, bookmark)
+			}
+		}
+		kept = append(kept, timeRangeBookmark{Name: name, From: a.fromTime, To: a.toTime})
+		saveRangeBookmarks(kept)
+		closeForm()
+		render()
+	})
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(tview.NewTextView().SetDynamicColors(true).
+			SetText("Current range: "+a.formatTimeRange()), 3, 0, false).
+		AddItem(nameInput, 1, 0, true)
+	flex.SetBorder(true).SetTitle("Save time-range bookmark")
+
+	a.pages.AddPage("bookmarkSave", flex, true, true)
+	a.pages.SwitchToPage("bookmarkSave")
+	a.tviewApp.SetFocus(nameInput)
+}
@@ -0,0 +1,187 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// columnCompressionRow is one column's aggregated size and codec information
+type columnCompressionRow struct {
+	name         string
+	colType      string
+	codec        string
+	compressed   uint64
+	uncompressed uint64
+}
+
+func (row columnCompressionRow) ratio() float64 {
+	if row.uncompressed == 0 {
+		return 0
+	}
+	return float64(row.compressed) / float64(row.uncompressed)
+}
+
+// codecSuggestion turns a column's type, current codec and compression ratio
+// into an actionable hint, the interactive counterpart of audit check A2.4.03
+func codecSuggestion(row columnCompressionRow) string {
+	if row.codec != "" {
+		return ""
+	}
+	baseType := row.colType
+	baseType = strings.TrimPrefix(baseType, "Nullable(")
+	switch {
+	case strings.HasPrefix(baseType, "DateTime") || strings.HasPrefix(baseType, "Date"):
+		return "try CODEC(DoubleDelta, ZSTD) for timestamps"
+	case strings.HasPrefix(baseType, "Float"):
+		return "try CODEC(Gorilla, ZSTD) for gauge-like values"
+	case strings.HasPrefix(baseType, "UInt") || strings.HasPrefix(baseType, "Int"):
+		return "try CODEC(T64, ZSTD) for integers"
+	case strings.HasPrefix(baseType, "String") && row.ratio() > 0.5:
+		return "try CODEC(ZSTD(3)) or LowCardinality(String)"
+	case row.ratio() > 0.9:
+		return "barely compresses, check if the data is effectively random"
+	}
+	return ""
+}
+
+// ShowColumnCompression analyzes one table's per-column compressed and
+// uncompressed sizes from system.parts_columns, joined with the codec
+// declared in system.columns, and surfaces codec suggestions for the
+// columns worth tuning
+func (a *App) ShowColumnCompression(database, table string) {
+	go func() {
+		columns, err := a.fetchColumnCompression(database, table)
+		a.tviewApp.QueueUpdateDraw(func() {
+			if err != nil {
+				a.SwitchToMainPage(fmt.Sprintf("ShowColumnCompression query failed: %v", err))
+				return
+			}
+			a.renderColumnCompression(database, table, columns)
+		})
+	}()
+}
+
+func (a *App) fetchColumnCompression(database, table string) ([]columnCompressionRow, error) {
+	query := fmt.Sprintf(
+		"SELECT column AS name, any(type) AS type, "+
+			"sum(column_data_compressed_bytes) AS compressed, "+
+			"sum(column_data_uncompressed_bytes) AS uncompressed "+
+			"FROM clusterAllReplicas('%s', merge(system,'^parts_columns$')) "+
+			"WHERE active AND database = ? AND table = ? "+
+			"GROUP BY name ORDER BY compressed DESC "+
+			"SETTINGS skip_unavailable_shards=1",
+		chquery.EscapeString(a.cluster))
+
+	rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("columnCompression"), query, database, table)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close fetchColumnCompression rows")
+		}
+	}()
+
+	var columns []columnCompressionRow
+	for rows.Next() {
+		var row columnCompressionRow
+		if scanErr := rows.Scan(&row.name, &row.colType, &row.compressed, &row.uncompressed); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan column compression row")
+			continue
+		}
+		columns = append(columns, row)
+	}
+
+	codecs, codecsErr := a.fetchColumnCodecs(database, table)
+	if codecsErr != nil {
+		// sizes alone are still useful, hints just assume no codec
+		log.Error().Err(codecsErr).Msg("can't fetch column codecs")
+	}
+	for i := range columns {
+		columns[i].codec = codecs[columns[i].name]
+	}
+	return columns, nil
+}
+
+func (a *App) fetchColumnCodecs(database, table string) (map[string]string, error) {
+	query := fmt.Sprintf(
+		"SELECT name, any(compression_codec) AS codec "+
+			"FROM clusterAllReplicas('%s', merge(system,'^columns$')) "+
+			"WHERE database = ? AND table = ? GROUP BY name "+
+			"SETTINGS skip_unavailable_shards=1",
+		chquery.EscapeString(a.cluster))
+
+	rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("columnCompression"), query, database, table)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close fetchColumnCodecs rows")
+		}
+	}()
+
+	codecs := make(map[string]string)
+	for rows.Next() {
+		var name, codec string
+		if scanErr := rows.Scan(&name, &codec); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan column codec row")
+			continue
+		}
+		codecs[name] = codec
+	}
+	return codecs, nil
+}
+
+func (a *App) renderColumnCompression(database, table string, columns []columnCompressionRow) {
+	filteredTable := widgets.NewFilteredTable()
+	filteredTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	filteredTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Column compression `%s`.`%s` [yellow](Esc: back)[-]", database, table))
+	filteredTable.SetupHeaders([]string{"Column", "Type", "Codec", "Compressed", "Uncompressed", "Ratio", "Hint"})
+
+	for _, row := range columns {
+		ratio := row.ratio()
+		color := tcell.ColorWhite
+		if ratio > 0.8 {
+			color = tcell.ColorRed
+		} else if ratio > 0.5 {
+			color = tcell.ColorYellow
+		}
+		filteredTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(row.name).SetTextColor(color),
+			tview.NewTableCell(row.colType).SetTextColor(color),
+			tview.NewTableCell(row.codec).SetTextColor(color),
+			tview.NewTableCell(utils.FormatReadable(float64(row.compressed), 1) + "B").SetTextColor(color),
+			tview.NewTableCell(utils.FormatReadable(float64(row.uncompressed), 1) + "B").SetTextColor(color),
+			tview.NewTableCell(fmt.Sprintf("%.2f", ratio)).SetTextColor(color),
+			tview.NewTableCell(codecSuggestion(row)).SetTextColor(tcell.ColorGreen),
+		})
+	}
+
+	existingHandler := filteredTable.GetInputCapture(a.tviewApp, a.pages)
+	filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if result := existingHandler(event); result == nil {
+			return nil
+		}
+		if event.Key() == tcell.KeyEscape {
+			a.pages.RemovePage("columnCompression")
+			a.pages.SwitchToPage("storage")
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("columnCompression", filteredTable.Table, true, true)
+	a.pages.SwitchToPage("columnCompression")
+	a.tviewApp.SetFocus(filteredTable.Table)
+}
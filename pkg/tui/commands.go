@@ -0,0 +1,167 @@
+package tui
+
+ // Available commands
+ const (
+ 	CmdHelp           = "help"
+ 	CmdConnect        = "connect"
+ 	CmdQuit           = "quit"
+ 	CmdFlamegraph     = "flamegraph"
+ 	CmdExplain        = "explain"
+ 	CmdFrom           = "from"
+ 	CmdTo             = "to"
+ 	CmdRange          = "range"
+ 	CmdCluster        = "cluster"
+ 	CmdHeatmap        = "heatmap"
+ 	CmdCategory       = "categoryType"
+ 	CmdMetric         = "heatmapMetric"
+ 	CmdScale          = "scale"
+ 	CmdProfileEvents  = "profile_events"
+ 	CmdMetricLog      = "metric_log"
+ 	CmdAsyncMetricLog = "asynchronous_metric_log"
+ 	CmdLogs           = "logs"
+ 	CmdMemory         = "memory"
+ 	CmdAudit          = "audit"
+ 	CmdProcesses      = "processes"
+ 	CmdProfiler       = "profiler"
+ 	CmdReplication    = "replication"
+ 	CmdMerges         = "merges"
+ 	CmdSQL            = "sql"
+ 	CmdDensity        = "density"
+ 	CmdTop            = "top"
+ 	CmdIncident       = "incident"
+ 	CmdErrors         = "errors"
+ 	CmdStorage        = "storage"
+ 	CmdDictionaries   = "dictionaries"
+	CmdBackups        = "backups"
+	CmdProjections    = "projections"
+	CmdBookmarks      = "bookmarks"
+	CmdTz             = "tz"
+	CmdQueries        = "queries"
+	CmdTheme          = "theme"
+ )
+
+type TraceType string
+
+const (
+	TraceMemory        TraceType = "Memory"
+	TraceCPU           TraceType = "CPU"
+	TraceReal          TraceType = "Real"
+	TraceMemorySample  TraceType = "MemorySample"
+	// TraceProfileEvents is not a system.trace_log trace type: it renders the
+	// per-event time breakdown from query_log ProfileEvents as a flamegraph
+	TraceProfileEvents TraceType = "ProfileEvents"
+)
+
+// HeatmapMetric Heatmap metric types
+type HeatmapMetric string
+
+const (
+	MetricCount          HeatmapMetric = "count"
+	MetricMemoryUsage    HeatmapMetric = "memoryUsage"
+	MetricCPUUsage       HeatmapMetric = "cpuUsage"
+	MetricNetworkSent    HeatmapMetric = "networkSent"
+	MetricNetworkReceive HeatmapMetric = "networkReceive"
+	MetricReadRows       HeatmapMetric = "readRows"
+	MetricWrittenRows    HeatmapMetric = "writtenRows"
+	MetricReadBytes      HeatmapMetric = "readBytes"
+	MetricWrittenBytes   HeatmapMetric = "writtenBytes"
+	MetricErrorRatio     HeatmapMetric = "errorRatio"
+)
+
+// CategoryType Category types for heatmap
+type CategoryType string
+
+const (
+	CategoryQueryHash CategoryType = "normalized_query_hash"
+	CategoryTable     CategoryType = "tables"
+	CategoryHost      CategoryType = "hosts"
+	CategoryError     CategoryType = "errors"
+	// rows are hours of the day instead of distinct values, giving a
+	// daily activity profile over the selected range
+	CategoryHourOfDay CategoryType = "hour_of_day"
+)
+
+var availableCommands = []string{
+	CmdHelp,
+	CmdConnect,
+	CmdQuit,
+	CmdFlamegraph,
+	CmdExplain,
+	CmdFrom,
+	CmdTo,
+	CmdRange,
+	CmdHeatmap,
+	CmdCategory,
+	CmdCluster,
+	CmdMetric,
+	CmdScale,
+	CmdProfileEvents,
+	CmdMetricLog,
+	CmdAsyncMetricLog,
+	CmdLogs,
+	CmdMemory,
+	CmdAudit,
+	CmdProcesses,
+	CmdProfiler,
+	CmdReplication,
+	CmdMerges,
+	CmdSQL,
+	CmdDensity,
+	CmdTop,
+	CmdIncident,
+	CmdErrors,
+	CmdStorage,
+	CmdDictionaries,
+	CmdBackups,
+	CmdProjections,
+	CmdBookmarks,
+	CmdTz,
+	CmdQueries,
+	CmdTheme,
+}
+
+ // Help text
+ const helpText = `ClickHouse Timeline Commands:
+ :help            - Show this help
+ :connect         - Connect to a ClickHouse instance
+ :quit            - Exit the application
+ :flamegraph      - Generate a flamegraph
+ :explain         - Open explain query flow (filter by normalized_query_hash, tables, kinds)
+ :from            - Set the start time
+ :to              - Set the end time
+ :range           - Set time range with predefined options
+ :heatmap         - Generate a heatmap visualization
+ :categoryType    - Set categoryType for heatmap (query_hash, tables, hosts)
+ :heatmapMetric   - Select metric for heatmap visualization
+ :profile_events  - Show profile events
+ :cluster         - Select cluster for queries
+ :scale           - Set scale type for heatmap (linear, log2, log10)
+ :logs            - Show any table logs (text_log, query_log, query_thread_log)
+ :metric_log              - Show system.metric_log metrics
+ :asynchronous_metric_log - Show system.asynchronous_metric_log metrics
+ :memory          - Show Memory usage (grouped by host)
+ :audit          - Run system audit and show diagnostics and suggestions
+ :processes      - Snapshot system.processes for a few minutes and report long runners
+ :profiler       - Aggregate query_log by fingerprint (count, duration, reads, errors)
+ :replication    - Live replication lag dashboard (system.replicas, refreshed periodically)
+ :merges         - Monitor system.merges and system.mutations (progress, ETA, kill mutation)
+ :sql            - Ad-hoc SQL console (Ctrl+Enter to execute, history on disk)
+ :density        - Toggle table row density (compact/comfortable)
+ :top            - Live system.processes list (auto-refresh, Enter: KILL QUERY)
+:incident       - Guided incident report around a time (error spikes, slow hashes, audit, replication, logs)
+:errors         - Explore query_log exceptions grouped by code and query hash (drill to stack traces)
+:storage        - Browse disk usage by database/table/partition (u: uncompressed bytes)
+:dictionaries   - Monitor dictionary load status and memory (Enter: SYSTEM RELOAD DICTIONARY)
+:backups        - Backup/restore status from system.backups (and clickhouse-backup API if configured)
+:projections    - Projection and skipping-index usage vs definitions (unused ones, candidates)
+:bookmarks      - Time-range bookmarks and quick relative ranges (s: save current range)
+:tz             - Set display timezone (utc, local, server, or an IANA name)
+:queries        - SQL the tool itself executed, with timing and errors
+:theme          - Switch color palette (dark, light, solarized, monochrome, high-contrast)
+ 
+ Navigation:
+ - Use arrow keys to navigate
+ - Press / to filter
+ - Press Esc to cancel current operation
+ - Press Enter to show action menu
+ - Double click to show action menu`
@@ -0,0 +1,245 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// journalInterval is how often the session state is journaled to disk
+const journalInterval = 5 * time.Second
+
+// crashLogTailLines is how many recent app log lines go into a crash report
+const crashLogTailLines = 100
+
+// stateJournal is the crash-safe snapshot of the session, written periodically
+// while the TUI runs and removed again on clean exit; when it survives a crash
+// the next start offers to resume from it
+type stateJournal struct {
+	WrittenAt     string `yaml:"written_at"`
+	Context       string `yaml:"context"`
+	Cluster       string `yaml:"cluster"`
+	FromTime      string `yaml:"from_time"`
+	ToTime        string `yaml:"to_time"`
+	CategoryType  string `yaml:"category_type"`
+	CategoryValue string `yaml:"category_value"`
+	Metric        string `yaml:"metric"`
+	Scale         string `yaml:"scale"`
+	Page          string `yaml:"page"`
+}
+
+// stateSnapshot captures the current session as a journal entry
+func (a *App) stateSnapshot() stateJournal {
+	journal := stateJournal{
+		WrittenAt:     time.Now().Format(time.RFC3339),
+		Cluster:       a.cluster,
+		FromTime:      a.fromTime.Format(time.RFC3339),
+		ToTime:        a.toTime.Format(time.RFC3339),
+		CategoryType:  string(a.categoryType),
+		CategoryValue: a.categoryValue,
+		Metric:        string(a.heatmapMetric),
+		Scale:         string(a.scaleType),
+	}
+	if a.selectedContext != nil {
+		journal.Context = a.selectedContext.Name
+	}
+	if a.pages != nil {
+		journal.Page, _ = a.pages.GetFrontPage()
+	}
+	return journal
+}
+
+// journalDir returns the config directory the journal and crash reports live in
+func journalDir() string {
+	home, homeErr := os.UserHomeDir()
+	if homeErr != nil {
+		return ""
+	}
+	return filepath.Join(home, ".clickhouse-timeline")
+}
+
+// writeStateJournal snapshots the current session to disk
+func (a *App) writeStateJournal() {
+	dir := journalDir()
+	if dir == "" {
+		return
+	}
+	data, marshalErr := yaml.Marshal(a.stateSnapshot())
+	if marshalErr != nil {
+		log.Error().Err(marshalErr).Msg("can't marshal state journal")
+		return
+	}
+	if writeErr := os.WriteFile(filepath.Join(dir, "state-journal.yml"), data, 0644); writeErr != nil {
+		log.Error().Err(writeErr).Msg("can't write state journal")
+	}
+}
+
+// readStateJournal loads a journal left behind by a crashed session, nil when
+// the previous session exited cleanly
+func readStateJournal() *stateJournal {
+	dir := journalDir()
+	if dir == "" {
+		return nil
+	}
+	data, readErr := os.ReadFile(filepath.Join(dir, "state-journal.yml"))
+	if readErr != nil {
+		return nil
+	}
+	var journal stateJournal
+	if unmarshalErr := yaml.Unmarshal(data, &journal); unmarshalErr != nil {
+		log.Error().Err(unmarshalErr).Msg("can't parse state journal")
+		return nil
+	}
+	return &journal
+}
+
+// clearStateJournal removes the journal on clean shutdown
+func clearStateJournal() {
+	if dir := journalDir(); dir != "" {
+		_ = os.Remove(filepath.Join(dir, "state-journal.yml"))
+	}
+}
+
+// writeSessionFile persists the session on clean exit so --resume can pick an
+// investigation up where it was left; unlike the crash journal it stays on
+// disk until the next clean exit overwrites it
+func (a *App) writeSessionFile() {
+	dir := journalDir()
+	if dir == "" {
+		return
+	}
+	data, marshalErr := yaml.Marshal(a.stateSnapshot())
+	if marshalErr != nil {
+		log.Error().Err(marshalErr).Msg("can't marshal session state")
+		return
+	}
+	if writeErr := os.WriteFile(filepath.Join(dir, "session.yml"), data, 0644); writeErr != nil {
+		log.Error().Err(writeErr).Msg("can't write session state")
+	}
+}
+
+// readSessionFile loads the session saved by the previous clean exit, nil
+// when none was saved yet
+func readSessionFile() *stateJournal {
+	dir := journalDir()
+	if dir == "" {
+		return nil
+	}
+	data, readErr := os.ReadFile(filepath.Join(dir, "session.yml"))
+	if readErr != nil {
+		return nil
+	}
+	var journal stateJournal
+	if unmarshalErr := yaml.Unmarshal(data, &journal); unmarshalErr != nil {
+		log.Error().Err(unmarshalErr).Msg("can't parse session state")
+		return nil
+	}
+	return &journal
+}
+
+// writeCrashReport dumps the panic, the stack, the journaled state and the
+// tail of the app log into a timestamped file and returns its path
+func (a *App) writeCrashReport(panicValue interface{}, stack []byte) string {
+	dir := journalDir()
+	if dir == "" {
+		return ""
+	}
+	path := filepath.Join(dir, fmt.Sprintf("crash-%s.log", time.Now().Format("20060102-150405")))
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("panic: %v\n\n%s\n", panicValue, stack))
+	if a.pages != nil {
+		page, _ := a.pages.GetFrontPage()
+		report.WriteString(fmt.Sprintf("\ncurrent page: %s\n", page))
+	}
+	report.WriteString(fmt.Sprintf("cluster: %s\nfrom: %s\nto: %s\n",
+		a.cluster, a.fromTime.Format(time.RFC3339), a.toTime.Format(time.RFC3339)))
+	if tail := tailAppLog(); tail != "" {
+		report.WriteString("\nrecent log:\n")
+		report.WriteString(tail)
+	}
+
+	if writeErr := os.WriteFile(path, []byte(report.String()), 0644); writeErr != nil {
+		return ""
+	}
+	return path
+}
+
+// tailAppLog returns the last lines of the application log file
+func tailAppLog() string {
+	dir := journalDir()
+	if dir == "" {
+		return ""
+	}
+	data, readErr := os.ReadFile(filepath.Join(dir, "clickhouse-timeline.log"))
+	if readErr != nil {
+		return ""
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > crashLogTailLines {
+		lines = lines[len(lines)-crashLogTailLines:]
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// offerResumeFromJournal shows a modal when a journal survived the previous
+// session, restoring context, cluster and time range on "Resume"
+func (a *App) offerResumeFromJournal(journal *stateJournal) {
+	message := fmt.Sprintf("The previous session did not exit cleanly (last state %s, page %s).\n\nResume with context '%s', cluster '%s' and the journaled time range?",
+		journal.WrittenAt, journal.Page, journal.Context, journal.Cluster)
+	modal := tview.NewModal().
+		SetText(message).
+		AddButtons([]string{"Resume", "Discard"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("resumeJournal")
+			if buttonLabel == "Resume" {
+				a.resumeFromJournal(journal)
+			} else {
+				a.SwitchToMainPage("Discarded journaled session state")
+			}
+		})
+	a.pages.AddPage("resumeJournal", modal, true, true)
+	a.tviewApp.SetFocus(modal)
+}
+
+// resumeFromJournal applies the journaled state to the current session
+func (a *App) resumeFromJournal(journal *stateJournal) {
+	a.SwitchToMainPage("Resumed journaled session state" + a.applyJournalState(journal))
+}
+
+// applyJournalState restores context, cluster, time range and heatmap
+// selections from a journal entry and returns any caveats
+func (a *App) applyJournalState(journal *stateJournal) string {
+	mainMsg := ""
+	if journal.Context != "" {
+		if found := a.SetConnectByName(journal.Context); !found {
+			mainMsg += fmt.Sprintf("\nContext '%s' no longer exists in config", journal.Context)
+		}
+	}
+	if journal.Cluster != "" {
+		a.cluster = journal.Cluster
+	}
+	if t, parseErr := time.Parse(time.RFC3339, journal.FromTime); parseErr == nil {
+		a.fromTime = t
+	}
+	if t, parseErr := time.Parse(time.RFC3339, journal.ToTime); parseErr == nil {
+		a.toTime = t
+	}
+	if journal.CategoryType != "" {
+		a.categoryType = CategoryType(journal.CategoryType)
+	}
+	a.categoryValue = journal.CategoryValue
+	if journal.Metric != "" {
+		a.heatmapMetric = HeatmapMetric(journal.Metric)
+	}
+	if journal.Scale != "" {
+		a.scaleType = ScaleType(journal.Scale)
+	}
+	return mainMsg
+}
@@ -0,0 +1,208 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// dictionariesRefreshInterval is how often system.dictionaries is re-polled
+// while the page is open
+const dictionariesRefreshInterval = 5 * time.Second
+
+// dictionaryRow is one dictionary's state from one poll of system.dictionaries
+type dictionaryRow struct {
+	host           string
+	database       string
+	name           string
+	status         string
+	lastException  string
+	bytesAllocated uint64
+	elementCount   uint64
+	lifetimeMin    uint64
+	lifetimeMax    uint64
+}
+
+// qualifiedName renders the identifier SYSTEM RELOAD DICTIONARY expects;
+// XML-defined dictionaries have no database
+func (row dictionaryRow) qualifiedName() string {
+	if row.database == "" {
+		return chquery.QuoteID(row.name)
+	}
+	return chquery.QualifiedName(row.database, row.name)
+}
+
+// ShowDictionaries opens a periodically refreshing page with every
+// dictionary's load status, last exception, memory footprint and lifetime;
+// dictionaries that fail to load silently break queries, and this makes them
+// visible. Enter offers SYSTEM RELOAD DICTIONARY behind a confirmation.
+func (a *App) ShowDictionaries() {
+	filteredTable := widgets.NewFilteredTable()
+	filteredTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	filteredTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Dictionaries | refresh every %s [yellow](Enter: reload, Esc: return)[-]", dictionariesRefreshInterval))
+	filteredTable.SetupHeaders([]string{"Host", "Dictionary", "Status", "Elements", "Bytes", "Lifetime", "Last exception"})
+
+	var dictionaries []dictionaryRow
+
+	stop := make(chan struct{})
+	stopped := false
+	existingHandler := filteredTable.GetInputCapture(a.tviewApp, a.pages)
+	filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if result := existingHandler(event); result == nil {
+			return nil
+		}
+		switch event.Key() {
+		case tcell.KeyEscape:
+			if !stopped {
+				stopped = true
+				close(stop)
+			}
+			a.pages.RemovePage("dictionaries")
+			a.SwitchToMainPage("Returned from :dictionaries")
+			return nil
+		case tcell.KeyEnter:
+			row, _ := filteredTable.Table.GetSelection()
+			if row >= 1 && row <= len(dictionaries) {
+				a.confirmReloadDictionary(filteredTable, dictionaries[row-1])
+			}
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("dictionaries", filteredTable.Table, true, true)
+	a.pages.SwitchToPage("dictionaries")
+	a.tviewApp.SetFocus(filteredTable.Table)
+
+	go func() {
+		for {
+			snapshot := a.fetchDictionaries()
+			a.tviewApp.QueueUpdateDraw(func() {
+				dictionaries = snapshot
+				a.renderDictionaries(filteredTable, snapshot)
+			})
+			select {
+			case <-stop:
+				return
+			case <-time.After(dictionariesRefreshInterval):
+			}
+		}
+	}()
+}
+
+func (a *App) fetchDictionaries() []dictionaryRow {
+	query := fmt.Sprintf(
+		"SELECT hostName() AS host, database, name, toString(status) AS status, "+
+			"last_exception, bytes_allocated, element_count, "+
+			"toUInt64(lifetime_min) AS lifetime_min, toUInt64(lifetime_max) AS lifetime_max "+
+			"FROM clusterAllReplicas('%s', merge(system,'^dictionaries$')) "+
+			"SETTINGS skip_unavailable_shards=1",
+		chquery.EscapeString(a.cluster))
+
+	rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("dictionaries"), query)
+	if err != nil {
+		log.Error().Err(err).Msg("can't fetch dictionaries")
+		return nil
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close fetchDictionaries rows")
+		}
+	}()
+
+	var snapshot []dictionaryRow
+	for rows.Next() {
+		var row dictionaryRow
+		if scanErr := rows.Scan(&row.host, &row.database, &row.name, &row.status, &row.lastException,
+			&row.bytesAllocated, &row.elementCount, &row.lifetimeMin, &row.lifetimeMax); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan dictionary row")
+			continue
+		}
+		snapshot = append(snapshot, row)
+	}
+	// broken dictionaries first, then the biggest ones
+	sort.Slice(snapshot, func(i, j int) bool {
+		failedI := snapshot[i].lastException != ""
+		failedJ := snapshot[j].lastException != ""
+		if failedI != failedJ {
+			return failedI
+		}
+		if snapshot[i].bytesAllocated != snapshot[j].bytesAllocated {
+			return snapshot[i].bytesAllocated > snapshot[j].bytesAllocated
+		}
+		return snapshot[i].host+snapshot[i].database+snapshot[i].name < snapshot[j].host+snapshot[j].database+snapshot[j].name
+	})
+	return snapshot
+}
+
+// renderDictionaries repopulates the table from the latest poll, keeping the
+// current selection where possible
+func (a *App) renderDictionaries(filteredTable *widgets.FilteredTable, snapshot []dictionaryRow) {
+	selectedRow, selectedCol := filteredTable.Table.GetSelection()
+	filteredTable.Table.Clear()
+	filteredTable.OriginalRows = nil
+	filteredTable.SetupHeaders(filteredTable.Headers)
+
+	for _, row := range snapshot {
+		color := tcell.ColorWhite
+		if row.lastException != "" {
+			color = tcell.ColorRed
+		} else if row.status != "LOADED" {
+			color = tcell.ColorYellow
+		}
+		filteredTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(row.host).SetTextColor(color),
+			tview.NewTableCell(row.qualifiedName()).SetTextColor(color),
+			tview.NewTableCell(row.status).SetTextColor(color),
+			tview.NewTableCell(fmt.Sprintf("%d", row.elementCount)).SetTextColor(color),
+			tview.NewTableCell(utils.FormatReadable(float64(row.bytesAllocated), 1) + "B").SetTextColor(color),
+			tview.NewTableCell(fmt.Sprintf("%d-%ds", row.lifetimeMin, row.lifetimeMax)).SetTextColor(color),
+			tview.NewTableCell(strings.ReplaceAll(row.lastException, "\n", " ")).SetTextColor(color),
+		})
+	}
+
+	if selectedRow > 0 && selectedRow < filteredTable.Table.GetRowCount() {
+		filteredTable.Table.Select(selectedRow, selectedCol)
+	}
+}
+
+// confirmReloadDictionary asks before issuing SYSTEM RELOAD DICTIONARY on the
+// connected server; the outcome is shown in the table title
+func (a *App) confirmReloadDictionary(filteredTable *widgets.FilteredTable, dictionary dictionaryRow) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Reload dictionary %s?", dictionary.qualifiedName())).
+		AddButtons([]string{"Reload", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("reloadDictionary")
+			a.pages.SwitchToPage("dictionaries")
+			a.tviewApp.SetFocus(filteredTable.Table)
+			if buttonLabel != "Reload" {
+				return
+			}
+			go func() {
+				_, execErr := a.clickHouse.Exec(fmt.Sprintf("SYSTEM RELOAD DICTIONARY %s", dictionary.qualifiedName()))
+				a.tviewApp.QueueUpdateDraw(func() {
+					if execErr != nil {
+						filteredTable.Table.SetTitle(fmt.Sprintf("Dictionaries [red]reload of %s failed: %v[-]", dictionary.qualifiedName(), execErr))
+						return
+					}
+					filteredTable.Table.SetTitle(fmt.Sprintf("Dictionaries [green]reload of %s triggered[-]", dictionary.qualifiedName()))
+				})
+			}()
+		})
+
+	a.pages.AddPage("reloadDictionary", modal, true, true)
+	a.pages.SwitchToPage("reloadDictionary")
+	a.tviewApp.SetFocus(modal)
+}
@@ -0,0 +1,314 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// errorQueriesLimit caps how many individual failing queries the drill-down
+// lists per exception group
+const errorQueriesLimit = 100
+
+// errorGroupRow is one (exception code, query hash) group of failed queries
+type errorGroupRow struct {
+	exceptionCode int32
+	hash          string
+	count         uint64
+	firstSeen     time.Time
+	lastSeen      time.Time
+	sample        string
+}
+
+// errorQueryRow is one individual failed query inside a group
+type errorQueryRow struct {
+	host       string
+	eventTime  time.Time
+	queryID    string
+	user       string
+	query      string
+	exception  string
+	stackTrace string
+}
+
+// ShowErrorExplorer lists exceptions from system.query_log for the selected
+// range, grouped by exception code and normalized query hash with counts and
+// first/last seen; Enter drills into the individual failing queries and from
+// there into the exception's stack trace and server log context
+func (a *App) ShowErrorExplorer() {
+	go func() {
+		groups, err := a.fetchErrorGroups()
+		a.tviewApp.QueueUpdateDraw(func() {
+			if err != nil {
+				a.SwitchToMainPage(fmt.Sprintf("ShowErrorExplorer query failed: %v", err))
+				return
+			}
+			a.renderErrorGroups(groups)
+		})
+	}()
+}
+
+func (a *App) fetchErrorGroups() ([]errorGroupRow, error) {
+	query := fmt.Sprintf(
+		"SELECT exception_code, toString(normalized_query_hash) AS hash, count() AS cnt, "+
+			"min(event_time) AS first_seen, max(event_time) AS last_seen, any(left(exception, 200)) AS sample "+
+			"FROM clusterAllReplicas('%s', merge(system,'^query_log$')) "+
+			"WHERE event_date >= toDate(?) AND event_date <= toDate(?) "+
+			"AND event_time >= ? AND event_time <= ? "+
+			"AND type = 'ExceptionWhileProcessing' "+
+			"GROUP BY exception_code, hash ORDER BY cnt DESC "+
+			"SETTINGS skip_unavailable_shards=1",
+		a.cluster)
+
+	rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("errorExplorer"), query,
+		a.fromTime, a.toTime, a.fromTime, a.toTime)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close fetchErrorGroups rows")
+		}
+	}()
+
+	var groups []errorGroupRow
+	for rows.Next() {
+		var row errorGroupRow
+		if scanErr := rows.Scan(&row.exceptionCode, &row.hash, &row.count, &row.firstSeen, &row.lastSeen, &row.sample); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan error group row")
+			continue
+		}
+		groups = append(groups, row)
+	}
+	return groups, nil
+}
+
+func (a *App) renderErrorGroups(groups []errorGroupRow) {
+	filteredTable := widgets.NewFilteredTable()
+	filteredTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	filteredTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Query errors | %s - %s [yellow](Enter: failing queries, Esc: return)[-]",
+			utils.FormatTimestampShort(a.fromTime), utils.FormatTimestampShort(a.toTime)))
+	filteredTable.SetupHeaders([]string{"Code", "Hash", "Count", "First seen", "Last seen", "Sample"})
+
+	for _, group := range groups {
+		color := tcell.ColorWhite
+		if group.count > 100 {
+			color = tcell.ColorRed
+		} else if group.count > 10 {
+			color = tcell.ColorYellow
+		}
+		filteredTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(fmt.Sprintf("%d", group.exceptionCode)).SetTextColor(color),
+			tview.NewTableCell(group.hash).SetTextColor(color),
+			tview.NewTableCell(fmt.Sprintf("%d", group.count)).SetTextColor(color),
+			tview.NewTableCell(utils.FormatTimestampTable(group.firstSeen)).SetTextColor(color),
+			tview.NewTableCell(utils.FormatTimestampTable(group.lastSeen)).SetTextColor(color),
+			tview.NewTableCell(strings.ReplaceAll(group.sample, "\n", " ")).SetTextColor(color),
+		})
+	}
+
+	existingHandler := filteredTable.GetInputCapture(a.tviewApp, a.pages)
+	filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if result := existingHandler(event); result == nil {
+			return nil
+		}
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.pages.RemovePage("errorExplorer")
+			a.SwitchToMainPage("Returned from :errors")
+			return nil
+		case tcell.KeyEnter:
+			row, _ := filteredTable.Table.GetSelection()
+			if row >= 1 && row <= len(groups) {
+				a.showErrorQueries(groups[row-1])
+			}
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("errorExplorer", filteredTable.Table, true, true)
+	a.pages.SwitchToPage("errorExplorer")
+	a.tviewApp.SetFocus(filteredTable.Table)
+}
+
+// showErrorQueries lists the individual failing queries of one exception
+// group; Enter opens the stack trace view for the selected query
+func (a *App) showErrorQueries(group errorGroupRow) {
+	go func() {
+		queries, err := a.fetchErrorQueries(group)
+		a.tviewApp.QueueUpdateDraw(func() {
+			if err != nil {
+				a.SwitchToMainPage(fmt.Sprintf("showErrorQueries query failed: %v", err))
+				return
+			}
+			a.renderErrorQueries(group, queries)
+		})
+	}()
+}
+
+func (a *App) fetchErrorQueries(group errorGroupRow) ([]errorQueryRow, error) {
+	query := fmt.Sprintf(
+		"SELECT hostName() AS host, event_time, query_id, user, left(query, 200) AS query, "+
+			"exception, stack_trace "+
+			"FROM clusterAllReplicas('%s', merge(system,'^query_log$')) "+
+			"WHERE event_date >= toDate(?) AND event_date <= toDate(?) "+
+			"AND event_time >= ? AND event_time <= ? "+
+			"AND type = 'ExceptionWhileProcessing' "+
+			"AND exception_code = ? AND toString(normalized_query_hash) = ? "+
+			"ORDER BY event_time DESC LIMIT %d "+
+			"SETTINGS skip_unavailable_shards=1",
+		a.cluster, errorQueriesLimit)
+
+	rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("errorExplorer"), query,
+		a.fromTime, a.toTime, a.fromTime, a.toTime, group.exceptionCode, group.hash)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close fetchErrorQueries rows")
+		}
+	}()
+
+	var queries []errorQueryRow
+	for rows.Next() {
+		var row errorQueryRow
+		if scanErr := rows.Scan(&row.host, &row.eventTime, &row.queryID, &row.user, &row.query, &row.exception, &row.stackTrace); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan error query row")
+			continue
+		}
+		queries = append(queries, row)
+	}
+	return queries, nil
+}
+
+func (a *App) renderErrorQueries(group errorGroupRow, queries []errorQueryRow) {
+	filteredTable := widgets.NewFilteredTable()
+	filteredTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	filteredTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Failing queries | code %d hash %s [yellow](Enter: stack trace, Esc: back)[-]",
+			group.exceptionCode, group.hash))
+	filteredTable.SetupHeaders([]string{"Time", "Host", "User", "Query ID", "Query"})
+
+	for _, row := range queries {
+		filteredTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(utils.FormatTimestampTable(row.eventTime)),
+			tview.NewTableCell(row.host),
+			tview.NewTableCell(row.user),
+			tview.NewTableCell(row.queryID),
+			tview.NewTableCell(strings.ReplaceAll(row.query, "\n", " ")),
+		})
+	}
+
+	existingHandler := filteredTable.GetInputCapture(a.tviewApp, a.pages)
+	filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if result := existingHandler(event); result == nil {
+			return nil
+		}
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.pages.RemovePage("errorQueries")
+			a.pages.SwitchToPage("errorExplorer")
+			return nil
+		case tcell.KeyEnter:
+			row, _ := filteredTable.Table.GetSelection()
+			if row >= 1 && row <= len(queries) {
+				a.showErrorTrace(queries[row-1])
+			}
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("errorQueries", filteredTable.Table, true, true)
+	a.pages.SwitchToPage("errorQueries")
+	a.tviewApp.SetFocus(filteredTable.Table)
+}
+
+// showErrorTrace shows one failed query's exception and stack trace from
+// system.query_log, followed by the server log lines recorded for the same
+// query_id in system.text_log when that log is enabled
+func (a *App) showErrorTrace(failed errorQueryRow) {
+	go func() {
+		logLines := a.fetchErrorTextLog(failed)
+		a.tviewApp.QueueUpdateDraw(func() {
+			var builder strings.Builder
+			builder.WriteString(fmt.Sprintf("[yellow]Query ID:[-] %s on %s by %s at %s\n\n",
+				failed.queryID, failed.host, failed.user, utils.FormatTimestamp(failed.eventTime)))
+			builder.WriteString(fmt.Sprintf("[yellow]Query:[-]\n%s\n\n", failed.query))
+			builder.WriteString(fmt.Sprintf("[red]Exception:[-]\n%s\n\n", tview.Escape(failed.exception)))
+			if failed.stackTrace != "" {
+				builder.WriteString(fmt.Sprintf("[yellow]Stack trace:[-]\n%s\n\n", tview.Escape(failed.stackTrace)))
+			}
+			if len(logLines) > 0 {
+				builder.WriteString("[yellow]Server log (system.text_log):[-]\n")
+				for _, line := range logLines {
+					builder.WriteString(tview.Escape(line))
+					builder.WriteString("\n")
+				}
+			}
+
+			view := tview.NewTextView().
+				SetDynamicColors(true).
+				SetScrollable(true).
+				SetText(builder.String())
+			view.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+				SetTitle(fmt.Sprintf("Error trace %s [yellow](Esc: back)[-]", failed.queryID))
+			view.SetDoneFunc(func(key tcell.Key) {
+				a.pages.RemovePage("errorTrace")
+				a.pages.SwitchToPage("errorQueries")
+			})
+
+			a.pages.AddPage("errorTrace", view, true, true)
+			a.pages.SwitchToPage("errorTrace")
+			a.tviewApp.SetFocus(view)
+		})
+	}()
+}
+
+func (a *App) fetchErrorTextLog(failed errorQueryRow) []string {
+	query := fmt.Sprintf(
+		"SELECT event_time, toString(level) AS level, message "+
+			"FROM clusterAllReplicas('%s', merge(system,'^text_log$')) "+
+			"WHERE event_date >= toDate(?) AND event_date <= toDate(?) "+
+			"AND query_id = ? ORDER BY event_time LIMIT 200 "+
+			"SETTINGS skip_unavailable_shards=1",
+		a.cluster)
+
+	rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("errorExplorer"), query,
+		failed.eventTime.Add(-time.Hour), failed.eventTime.Add(time.Hour), failed.queryID)
+	if err != nil {
+		// text_log is optional, the trace view just omits the section
+		log.Error().Err(err).Msg("can't fetch text_log for error trace")
+		return nil
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close fetchErrorTextLog rows")
+		}
+	}()
+
+	var lines []string
+	for rows.Next() {
+		var eventTime time.Time
+		var level, message string
+		if scanErr := rows.Scan(&eventTime, &level, &message); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan text_log line")
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s [%s] %s", utils.FormatTimestampTable(eventTime), level, message))
+	}
+	return lines
+}
@@ -0,0 +1,182 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// explainSide is one side of the EXPLAIN comparison: a representative query
+// near one percentile with its PLAN and PIPELINE outputs
+type explainSide struct {
+	label    string
+	queryID  string
+	duration float64
+	plan     []string
+	pipeline []string
+}
+
+// showExplainComparison picks representative executions of the same normalized
+// hash near two percentiles (fast and slow) and renders their EXPLAIN PLAN and
+// EXPLAIN PIPELINE side-by-side, highlighting lines that exist on only one
+// side, so the pipeline stage that blows up in the slow case stands out
+func (a *App) showExplainComparison(hash string, lowLabel string, lowThreshold float64, highLabel string, highThreshold float64, fromTime, toTime time.Time, cluster string, explainOutput *tview.TextView) {
+	go func() {
+		low, lowErr := a.fetchExplainSide(hash, lowLabel, lowThreshold, fromTime, toTime, cluster)
+		if lowErr != nil {
+			a.tviewApp.QueueUpdateDraw(func() {
+				explainOutput.SetText(fmt.Sprintf("Error preparing %s side: %v", lowLabel, lowErr))
+				a.pages.SwitchToPage("explain")
+			})
+			return
+		}
+		high, highErr := a.fetchExplainSide(hash, highLabel, highThreshold, fromTime, toTime, cluster)
+		if highErr != nil {
+			a.tviewApp.QueueUpdateDraw(func() {
+				explainOutput.SetText(fmt.Sprintf("Error preparing %s side: %v", highLabel, highErr))
+				a.pages.SwitchToPage("explain")
+			})
+			return
+		}
+
+		a.tviewApp.QueueUpdateDraw(func() {
+			a.renderExplainComparison(low, high)
+		})
+	}()
+}
+
+// fetchExplainSide finds the slowest execution at or below the threshold and
+// collects its EXPLAIN PLAN and EXPLAIN PIPELINE output lines
+func (a *App) fetchExplainSide(hash, label string, threshold float64, fromTime, toTime time.Time, cluster string) (*explainSide, error) {
+	fromStr := fromTime.Format("2006-01-02 15:04:05 -07:00")
+	toStr := toTime.Format("2006-01-02 15:04:05 -07:00")
+
+	q := fmt.Sprintf("SELECT query, query_duration_ms, query_id FROM clusterAllReplicas('%s', merge(system,'^query_log')) WHERE event_date >= toDate(parseDateTimeBestEffort('%s')) AND event_date <= toDate(parseDateTimeBestEffort('%s')) AND event_time >= parseDateTimeBestEffort('%s') AND event_time <= parseDateTimeBestEffort('%s') AND normalized_query_hash = '%s' AND query_duration_ms <= %f ORDER BY query_duration_ms DESC LIMIT 1",
+		cluster, fromStr, toStr, fromStr, toStr, strings.ReplaceAll(hash, "'", "''"), threshold,
+	)
+	rows, err := a.clickHouse.QueryContext(a.queryContext(), q)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close fetchExplainSide rows")
+		}
+	}()
+
+	side := &explainSide{label: label}
+	var queryText string
+	if rows.Next() {
+		if scanErr := rows.Scan(&queryText, &side.duration, &side.queryID); scanErr != nil {
+			return nil, scanErr
+		}
+	} else {
+		return nil, fmt.Errorf("no query found at or below %.2f ms", threshold)
+	}
+
+	side.plan = a.fetchExplainLines(fmt.Sprintf("EXPLAIN PLAN indexes=1, projections=1 %s", queryText))
+	side.pipeline = a.fetchExplainLines(fmt.Sprintf("EXPLAIN PIPELINE %s", queryText))
+	return side, nil
+}
+
+// fetchExplainLines runs one EXPLAIN statement and returns its output lines,
+// an error is reported as a single line (best-effort, like the explain view)
+func (a *App) fetchExplainLines(explain string) []string {
+	rows, err := a.clickHouse.QueryContext(a.queryContext(), explain)
+	if err != nil {
+		return []string{fmt.Sprintf("Error running explain: %v", err)}
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close fetchExplainLines rows")
+		}
+	}()
+
+	var lines []string
+	for rows.Next() {
+		var s string
+		if scanErr := rows.Scan(&s); scanErr != nil {
+			continue
+		}
+		lines = append(lines, s)
+	}
+	return lines
+}
+
+// diffHighlightLines colors every line that doesn't appear on the other side
+// (ignoring indentation and the 0x... ids pipelines embed), so structural
+// differences stand out while common stages stay white
+func diffHighlightLines(lines, other []string, color string) string {
+	normalize := func(line string) string {
+		normalized := strings.TrimSpace(line)
+		if idx := strings.Index(normalized, " 0x"); idx >= 0 {
+			normalized = normalized[:idx]
+		}
+		return normalized
+	}
+	otherSet := make(map[string]int, len(other))
+	for _, line := range other {
+		otherSet[normalize(line)]++
+	}
+
+	var builder strings.Builder
+	for _, line := range lines {
+		key := normalize(line)
+		if otherSet[key] > 0 {
+			otherSet[key]--
+			builder.WriteString(tview.Escape(line))
+		} else {
+			builder.WriteString(fmt.Sprintf("[%s]%s[-]", color, tview.Escape(line)))
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// renderExplainComparison lays both sides out in two columns with PLAN on top
+// and PIPELINE below; Esc returns to the query list
+func (a *App) renderExplainComparison(low, high *explainSide) {
+	column := func(side, other *explainSide, color string) *tview.Flex {
+		plan := tview.NewTextView().
+			SetWrap(true).
+			SetDynamicColors(true).
+			SetScrollable(true)
+		plan.SetBorder(true).SetTitle(fmt.Sprintf("%s PLAN (%.2f ms, %s)", side.label, side.duration, side.queryID))
+		plan.SetText(diffHighlightLines(side.plan, other.plan, color))
+
+		pipeline := tview.NewTextView().
+			SetWrap(true).
+			SetDynamicColors(true).
+			SetScrollable(true)
+		pipeline.SetBorder(true).SetTitle(fmt.Sprintf("%s PIPELINE", side.label))
+		pipeline.SetText(diffHighlightLines(side.pipeline, other.pipeline, color))
+
+		return tview.NewFlex().
+			SetDirection(tview.FlexRow).
+			AddItem(plan, 0, 1, false).
+			AddItem(pipeline, 0, 1, false)
+	}
+
+	// Lines only in the fast plan are green, lines only in the slow plan red
+	flex := tview.NewFlex().
+		AddItem(column(low, high, "green"), 0, 1, true).
+		AddItem(column(high, low, "red"), 0, 1, false)
+	flex.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("EXPLAIN comparison %s vs %s [yellow](differences highlighted, Esc: return)[-]", low.label, high.label))
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			a.pages.RemovePage("explain_compare")
+			a.pages.SwitchToPage("explain_queries")
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("explain_compare", flex, true, true)
+	a.pages.SwitchToPage("explain_compare")
+	a.tviewApp.SetFocus(flex)
+}
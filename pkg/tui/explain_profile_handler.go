@@ -0,0 +1,224 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// profileEventsByQueryID fetches one run's ProfileEvents counters
+const profileEventsByQueryID = `
+SELECT event, toFloat64(value) AS value
+FROM clusterAllReplicas('%s', merge(system, '^query_log'))
+ARRAY JOIN mapKeys(ProfileEvents) AS event, mapValues(ProfileEvents) AS value
+WHERE query_id = '%s' AND type != 'QueryStart'
+AND event_date >= toDate(parseDateTimeBestEffort('%s')) AND event_date <= toDate(parseDateTimeBestEffort('%s'))
+`
+
+// profileEventsMedianByHash fetches the per-event median across all runs of
+// the normalized hash in the time range, the baseline a single run compares to
+const profileEventsMedianByHash = `
+SELECT event, toFloat64(median(value)) AS value
+FROM clusterAllReplicas('%s', merge(system, '^query_log'))
+ARRAY JOIN mapKeys(ProfileEvents) AS event, mapValues(ProfileEvents) AS value
+WHERE normalized_query_hash = '%s' AND type != 'QueryStart'
+AND event_date >= toDate(parseDateTimeBestEffort('%s')) AND event_date <= toDate(parseDateTimeBestEffort('%s'))
+AND event_time >= parseDateTimeBestEffort('%s') AND event_time <= parseDateTimeBestEffort('%s')
+GROUP BY event
+`
+
+// settingsByQueryID fetches one run's non-default settings
+const settingsByQueryID = `
+SELECT name, value
+FROM clusterAllReplicas('%s', merge(system, '^query_log'))
+ARRAY JOIN mapKeys(Settings) AS name, mapValues(Settings) AS value
+WHERE query_id = '%s' AND type != 'QueryStart'
+AND event_date >= toDate(parseDateTimeBestEffort('%s')) AND event_date <= toDate(parseDateTimeBestEffort('%s'))
+LIMIT 1 BY name
+`
+
+// settingsTypicalByHash fetches the most frequent value of each setting across
+// the hash's runs, so a one-off settings override stands out
+const settingsTypicalByHash = `
+SELECT name, topK(1)(value)[1] AS value
+FROM clusterAllReplicas('%s', merge(system, '^query_log'))
+ARRAY JOIN mapKeys(Settings) AS name, mapValues(Settings) AS value
+WHERE normalized_query_hash = '%s' AND type != 'QueryStart'
+AND event_date >= toDate(parseDateTimeBestEffort('%s')) AND event_date <= toDate(parseDateTimeBestEffort('%s'))
+AND event_time >= parseDateTimeBestEffort('%s') AND event_time <= parseDateTimeBestEffort('%s')
+GROUP BY name
+`
+
+// profileComparisonRow is one ProfileEvents counter of the selected run next
+// to the hash median; ratio is 0 when the median is missing or zero
+type profileComparisonRow struct {
+	name   string
+	value  float64
+	median float64
+	ratio  float64
+}
+
+// fetchNameValuePairs runs a two-column (String, Float64-castable or String)
+// query and returns the pairs; used by both sides of the comparison
+func (a *App) fetchNameValuePairs(query string) (map[string]string, error) {
+	rows, queryErr := a.clickHouse.QueryContext(a.queryContext(), query)
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close profile comparison rows")
+		}
+	}()
+
+	pairs := make(map[string]string)
+	for rows.Next() {
+		var name, value string
+		if scanErr := rows.Scan(&name, &value); scanErr != nil {
+			return nil, scanErr
+		}
+		pairs[name] = value
+	}
+	return pairs, rows.Err()
+}
+
+// buildProfileComparison joins the selected run's counters with the hash
+// medians, sorted by ratio descending so outliers surface at the top
+func buildProfileComparison(current, medians map[string]string) []profileComparisonRow {
+	comparison := make([]profileComparisonRow, 0, len(current))
+	for name, valueStr := range current {
+		row := profileComparisonRow{name: name}
+		fmt.Sscanf(valueStr, "%g", &row.value)
+		if medianStr, found := medians[name]; found {
+			fmt.Sscanf(medianStr, "%g", &row.median)
+		}
+		if row.median > 0 {
+			row.ratio = row.value / row.median
+		}
+		comparison = append(comparison, row)
+	}
+	sort.Slice(comparison, func(i, j int) bool {
+		if comparison[i].ratio != comparison[j].ratio {
+			return comparison[i].ratio > comparison[j].ratio
+		}
+		return comparison[i].name < comparison[j].name
+	})
+	return comparison
+}
+
+// profileRatioColor maps how far a counter deviates from the hash median to a
+// severity color: 10x either way is an outlier, 2x is worth a look
+func profileRatioColor(ratio float64) tcell.Color {
+	switch {
+	case ratio >= 10 || (ratio > 0 && ratio <= 0.1):
+		return currentTheme.critical
+	case ratio >= 2 || (ratio > 0 && ratio <= 0.5):
+		return currentTheme.warning
+	default:
+		return currentTheme.text
+	}
+}
+
+// showProfileEventsComparison opens a table comparing one run's ProfileEvents
+// and Settings against the medians/typical values of its normalized hash, so
+// "why was this percentile example slow" turns into "10x more MarkCacheMisses"
+func (a *App) showProfileEventsComparison(queryID, hash string, fromStr, toStr, cluster string) {
+	escapedHash := strings.ReplaceAll(hash, "'", "''")
+	escapedQueryID := strings.ReplaceAll(queryID, "'", "''")
+
+	go func() {
+		current, currentErr := a.fetchNameValuePairs(fmt.Sprintf(profileEventsByQueryID, cluster, escapedQueryID, fromStr, toStr))
+		if currentErr != nil {
+			a.tviewApp.QueueUpdateDraw(func() {
+				a.SwitchToMainPage(fmt.Sprintf("Error fetching ProfileEvents for %s: %v", queryID, currentErr))
+			})
+			return
+		}
+		medians, medianErr := a.fetchNameValuePairs(fmt.Sprintf(profileEventsMedianByHash, cluster, escapedHash, fromStr, toStr, fromStr, toStr))
+		if medianErr != nil {
+			a.tviewApp.QueueUpdateDraw(func() {
+				a.SwitchToMainPage(fmt.Sprintf("Error fetching ProfileEvents medians for hash %s: %v", hash, medianErr))
+			})
+			return
+		}
+		settings, settingsErr := a.fetchNameValuePairs(fmt.Sprintf(settingsByQueryID, cluster, escapedQueryID, fromStr, toStr))
+		if settingsErr != nil {
+			log.Error().Err(settingsErr).Msg("can't fetch settings for profile comparison")
+		}
+		typicalSettings, typicalErr := a.fetchNameValuePairs(fmt.Sprintf(settingsTypicalByHash, cluster, escapedHash, fromStr, toStr, fromStr, toStr))
+		if typicalErr != nil {
+			log.Error().Err(typicalErr).Msg("can't fetch typical settings for profile comparison")
+		}
+
+		comparison := buildProfileComparison(current, medians)
+
+		a.tviewApp.QueueUpdateDraw(func() {
+			filteredTable := widgets.NewFilteredTable()
+			filteredTable.Table.SetBorders(false).
+				SetSelectable(true, false).
+				SetFixed(1, 0)
+			filteredTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+				SetTitle(fmt.Sprintf("ProfileEvents %s vs hash median [yellow](Esc: back to explain)[-]", queryID))
+			filteredTable.SetupHeaders([]string{"Name", "This query", "Hash median", "Ratio"})
+
+			for _, row := range comparison {
+				color := profileRatioColor(row.ratio)
+				ratioText := "-"
+				if row.ratio > 0 {
+					ratioText = fmt.Sprintf("%.1fx", row.ratio)
+				}
+				filteredTable.AddRow([]*tview.TableCell{
+					tview.NewTableCell(row.name).SetTextColor(color),
+					tview.NewTableCell(fmt.Sprintf("%.0f", row.value)).SetTextColor(color).SetAlign(tview.AlignRight),
+					tview.NewTableCell(fmt.Sprintf("%.0f", row.median)).SetTextColor(color).SetAlign(tview.AlignRight),
+					tview.NewTableCell(ratioText).SetTextColor(color).SetAlign(tview.AlignRight),
+				})
+			}
+
+			// settings differing from the hash's typical value follow the
+			// counters; identical settings are noise and stay hidden
+			settingNames := make([]string, 0, len(settings))
+			for name := range settings {
+				settingNames = append(settingNames, name)
+			}
+			sort.Strings(settingNames)
+			for _, name := range settingNames {
+				typical, found := typicalSettings[name]
+				if found && typical == settings[name] {
+					continue
+				}
+				if !found {
+					typical = "-"
+				}
+				filteredTable.AddRow([]*tview.TableCell{
+					tview.NewTableCell("Setting: " + name).SetTextColor(currentTheme.warning),
+					tview.NewTableCell(settings[name]).SetTextColor(currentTheme.warning).SetAlign(tview.AlignRight),
+					tview.NewTableCell(typical).SetTextColor(currentTheme.warning).SetAlign(tview.AlignRight),
+					tview.NewTableCell("differs").SetTextColor(currentTheme.warning).SetAlign(tview.AlignRight),
+				})
+			}
+
+			existingHandler := filteredTable.GetInputCapture(a.tviewApp, a.pages)
+			filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+				if result := existingHandler(event); result == nil {
+					return nil
+				}
+				if event.Key() == tcell.KeyEscape {
+					a.pages.RemovePage("explain_profile")
+					a.pages.SwitchToPage("explain_result")
+					return nil
+				}
+				return event
+			})
+
+			a.pages.AddPage("explain_profile", filteredTable.Table, true, true)
+			a.pages.SwitchToPage("explain_profile")
+			a.tviewApp.SetFocus(filteredTable.Table)
+		})
+	}()
+}
@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files with current render output")
+
+// renderPrimitive draws p on a simulation screen with a fixed size and returns
+// the visible text with colors stripped and trailing spaces trimmed, so layout
+// regressions show up as a plain-text diff against the golden file
+func renderPrimitive(t *testing.T, p tview.Primitive, width, height int) string {
+	t.Helper()
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if initErr := screen.Init(); initErr != nil {
+		t.Fatalf("can't init simulation screen: %v", initErr)
+	}
+	defer screen.Fini()
+	screen.SetSize(width, height)
+
+	p.SetRect(0, 0, width, height)
+	p.Draw(screen)
+	screen.Show()
+
+	cells, w, h := screen.GetContents()
+	var builder strings.Builder
+	for y := 0; y < h; y++ {
+		line := make([]rune, 0, w)
+		for x := 0; x < w; x++ {
+			runes := cells[y*w+x].Runes
+			if len(runes) == 0 {
+				line = append(line, ' ')
+				continue
+			}
+			line = append(line, runes[0])
+		}
+		builder.WriteString(strings.TrimRight(string(line), " "))
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// checkGolden compares got with testdata/<name>.golden, rewriting the file
+// when tests run with -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *updateGolden {
+		if writeErr := os.WriteFile(path, []byte(got), 0644); writeErr != nil {
+			t.Fatalf("can't update golden %s: %v", path, writeErr)
+		}
+		return
+	}
+	want, readErr := os.ReadFile(path)
+	if readErr != nil {
+		t.Fatalf("can't read golden %s (regenerate with `go test -run Golden -update ./pkg/tui/`): %v", path, readErr)
+	}
+	if string(want) != got {
+		t.Errorf("%s render differs from %s, got:\n%s", name, path, got)
+	}
+}
+
+func auditFixtureResults() []AuditResult {
+	return []AuditResult{
+		{ID: "A0.1.05", Host: "ch1", Severity: "Critical", Object: "default.events", Details: "Too many parts", Values: map[string]float64{"parts": 120000}, Group: "system counts"},
+		{ID: "A0.1.05", Host: "ch2", Severity: "Moderate", Object: "default.events", Details: "Too many parts", Values: map[string]float64{"parts": 12000}, Group: "system counts"},
+		{ID: "A2.3.01", Host: "ch1", Severity: "Minor", Object: "default.mv_clicks", Details: "Slow materialized view", Values: map[string]float64{"value": 3}, Group: "materialized views"},
+	}
+}
+
+func TestAuditViewGolden(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		grouped   bool
+		collapsed map[string]bool
+	}{
+		{name: "audit_flat"},
+		{name: "audit_grouped", grouped: true},
+		{name: "audit_grouped_collapsed", grouped: true, collapsed: map[string]bool{"system counts": true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ap := &AuditPanel{
+				table:     widgets.NewFilteredTable(),
+				results:   auditFixtureResults(),
+				grouped:   tc.grouped,
+				collapsed: tc.collapsed,
+			}
+			ap.renderResults()
+			checkGolden(t, tc.name, renderPrimitive(t, ap.table.Table, 110, 8))
+		})
+	}
+}
+
+func TestLogExplorerViewGolden(t *testing.T) {
+	utils.SetTimestampFormats("02.01.2006 15:04:05", "15:04:05")
+	entries := []LogEntry{
+		{Time: time.Date(2025, 8, 1, 10, 0, 1, 0, time.UTC), Level: "Information", Message: "Merge of 12 parts finished"},
+		{Time: time.Date(2025, 8, 1, 10, 0, 2, 0, time.UTC), Level: "Error", Message: "Code: 241. DB::Exception: Memory limit (total) exceeded"},
+		{Time: time.Date(2025, 8, 1, 10, 0, 3, 0, time.UTC), Level: "Warning", Message: "Replica is stale", AllFields: map[string]interface{}{logSourceColumn: "system.text_log"}},
+	}
+
+	lp := &LogPanel{logDetails: widgets.NewFilteredTable()}
+	lp.logDetails.SetupHeaders([]string{"Time", "Message"})
+	lp.renderBatch(entries, 0, false)
+	lp.totalRows = len(entries)
+	checkGolden(t, "logs_explorer", renderPrimitive(t, lp.logDetails.Table, 80, 6))
+
+	// Older rows loaded with Ctrl+PageUp go above the existing ones
+	older := []LogEntry{
+		{Time: time.Date(2025, 8, 1, 9, 59, 59, 0, time.UTC), Level: "Debug", Message: "Scheduling next merge"},
+	}
+	lp.renderBatch(older, 0, true)
+	checkGolden(t, "logs_explorer_prepend", renderPrimitive(t, lp.logDetails.Table, 80, 6))
+}
+
+func TestExplainPercentilesViewGolden(t *testing.T) {
+	modal := tview.NewModal().
+		SetText(explainPercentilesText("SELECT count() FROM default.events WHERE event_date = today()", 12.5, 88.1, 240.7)).
+		AddButtons([]string{"p50", "p90", "p99", "Back"})
+	checkGolden(t, "explain_percentiles", renderPrimitive(t, modal, 70, 16))
+}
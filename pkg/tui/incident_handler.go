@@ -0,0 +1,410 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/audit"
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/araddon/dateparse"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// The report window around the given incident time: enough lead time to see
+// the build-up, enough tail to catch delayed fallout
+const (
+	incidentLeadTime = 1 * time.Hour
+	incidentTailTime = 30 * time.Minute
+)
+
+// incidentSpikeFactor is how many times above the preceding window's
+// per-minute average the error count must rise to count as a spike
+const incidentSpikeFactor = 3.0
+
+// incidentFinding is one line of the assembled report; drill opens the panel
+// that investigates the finding further
+type incidentFinding struct {
+	section    string
+	severity   string
+	summary    string
+	drillLabel string
+	drill      func()
+}
+
+// ShowIncidentReport asks for a rough incident time and assembles a report
+// around it: error-rate spikes, the slowest query hashes, audit findings,
+// replication and merge anomalies and the loudest error log messages — the
+// usual incident runbook packaged as one page, with Enter drilling into the
+// panel behind each finding
+func (a *App) ShowIncidentReport() {
+	form := tview.NewForm()
+	form.AddInputField("Incident time", utils.FormatTimestampShort(time.Now()), 30, nil, nil)
+	form.AddButton("Assemble", func() {
+		value := form.GetFormItemByLabel("Incident time").(*tview.InputField).GetText()
+		incidentTime, parseErr := dateparse.ParseAny(value)
+		if parseErr != nil {
+			a.pages.RemovePage("incidentForm")
+			a.SwitchToMainPage(fmt.Sprintf("Can't parse incident time '%s': %v", value, parseErr))
+			return
+		}
+		a.pages.RemovePage("incidentForm")
+		// scope the global range to the incident window so every drilled
+		// panel opens on the same data the report was built from
+		a.fromTime = incidentTime.Add(-incidentLeadTime)
+		a.toTime = incidentTime.Add(incidentTailTime)
+		a.assembleIncidentReport()
+	})
+	form.AddButton("Cancel", func() {
+		a.pages.RemovePage("incidentForm")
+		a.SwitchToMainPage("")
+	})
+	form.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle("Incident report | window: 1h before to 30m after the given time")
+
+	a.pages.AddPage("incidentForm", form, true, true)
+	a.pages.SwitchToPage("incidentForm")
+	a.tviewApp.SetFocus(form)
+}
+
+// assembleIncidentReport gathers all report sections in the background and
+// renders them as a drillable table
+func (a *App) assembleIncidentReport() {
+	a.SwitchToMainPage(fmt.Sprintf("Assembling incident report for %s - %s ...\n",
+		utils.FormatTimestampShort(a.fromTime), utils.FormatTimestampShort(a.toTime)))
+
+	go func() {
+		var findings []incidentFinding
+		findings = append(findings, a.incidentErrorSpikes()...)
+		findings = append(findings, a.incidentSlowHashes()...)
+		findings = append(findings, a.incidentAuditFindings()...)
+		findings = append(findings, a.incidentReplicationAnomalies()...)
+		findings = append(findings, a.incidentMergeAnomalies()...)
+		findings = append(findings, a.incidentLogExcerpts()...)
+
+		a.tviewApp.QueueUpdateDraw(func() {
+			a.renderIncidentReport(findings)
+		})
+	}()
+}
+
+// renderIncidentReport shows the assembled findings; Enter drills into the
+// panel named in the last column
+func (a *App) renderIncidentReport(findings []incidentFinding) {
+	filteredTable := widgets.NewFilteredTable()
+	filteredTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	filteredTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Incident report | %s - %s [yellow](Enter: drill into panel, Esc: return)[-]",
+			utils.FormatTimestampShort(a.fromTime), utils.FormatTimestampShort(a.toTime)))
+	filteredTable.SetupHeaders([]string{"Section", "Severity", "Finding", "Drill"})
+
+	for _, finding := range findings {
+		color := severityColor(finding.severity)
+		filteredTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(finding.section).SetTextColor(color),
+			tview.NewTableCell(finding.severity).SetTextColor(color),
+			tview.NewTableCell(finding.summary).SetTextColor(color),
+			tview.NewTableCell(finding.drillLabel).SetTextColor(tcell.ColorYellow),
+		})
+	}
+
+	existingHandler := filteredTable.GetInputCapture(a.tviewApp, a.pages)
+	filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if result := existingHandler(event); result == nil {
+			return nil
+		}
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.pages.RemovePage("incidentReport")
+			a.SwitchToMainPage("Returned from :incident")
+			return nil
+		case tcell.KeyEnter:
+			row, _ := filteredTable.Table.GetSelection()
+			if row >= 1 && row <= len(findings) && findings[row-1].drill != nil {
+				a.pages.RemovePage("incidentReport")
+				findings[row-1].drill()
+			}
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("incidentReport", filteredTable.Table, true, true)
+	a.pages.SwitchToPage("incidentReport")
+	a.tviewApp.SetFocus(filteredTable.Table)
+}
+
+// incidentErrorSpikes compares per-minute failed query counts inside the
+// window against the preceding window of the same length and reports minutes
+// where the rate rose incidentSpikeFactor times above that baseline
+func (a *App) incidentErrorSpikes() []incidentFinding {
+	drill := func() {
+		a.categoryType = CategoryError
+		a.ShowHeatmap()
+	}
+	windowLen := a.toTime.Sub(a.fromTime)
+	query := fmt.Sprintf(
+		"SELECT toStartOfMinute(event_time) AS minute, count() AS cnt "+
+			"FROM clusterAllReplicas('%s', merge(system,'^query_log$')) "+
+			"WHERE event_date >= toDate(?) AND event_date <= toDate(?) "+
+			"AND event_time >= ? AND event_time <= ? "+
+			"AND type != 'QueryStart' AND exception_code != 0 "+
+			"GROUP BY minute ORDER BY minute "+
+			"SETTINGS skip_unavailable_shards=1",
+		a.cluster)
+
+	baselineFrom := a.fromTime.Add(-windowLen)
+	counts := func(from, to time.Time) (map[time.Time]uint64, error) {
+		rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("incident"), query,
+			from, to, from, to)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msgf("can't close incidentErrorSpikes rows")
+			}
+		}()
+		perMinute := make(map[time.Time]uint64)
+		for rows.Next() {
+			var minute time.Time
+			var cnt uint64
+			if scanErr := rows.Scan(&minute, &cnt); scanErr != nil {
+				log.Error().Err(scanErr).Msg("can't scan error spike row")
+				continue
+			}
+			perMinute[minute] = cnt
+		}
+		return perMinute, nil
+	}
+
+	baseline, baselineErr := counts(baselineFrom, a.fromTime)
+	window, windowErr := counts(a.fromTime, a.toTime)
+	if baselineErr != nil || windowErr != nil {
+		return []incidentFinding{{section: "Errors", severity: "Minor",
+			summary: "can't query system.query_log for error rates", drillLabel: "errors heatmap", drill: drill}}
+	}
+
+	var baselineTotal uint64
+	for _, cnt := range baseline {
+		baselineTotal += cnt
+	}
+	baselinePerMinute := float64(baselineTotal) / windowLen.Minutes()
+
+	var worstMinute time.Time
+	var worstCount uint64
+	spikeMinutes := 0
+	for minute, cnt := range window {
+		if float64(cnt) > baselinePerMinute*incidentSpikeFactor && cnt >= 10 {
+			spikeMinutes++
+			if cnt > worstCount {
+				worstCount = cnt
+				worstMinute = minute
+			}
+		}
+	}
+
+	if spikeMinutes == 0 {
+		return []incidentFinding{{section: "Errors", severity: "",
+			summary:    fmt.Sprintf("no error-rate spike (baseline %.1f errors/min)", baselinePerMinute),
+			drillLabel: "errors heatmap", drill: drill}}
+	}
+	severity := "Major"
+	if baselinePerMinute > 0 && float64(worstCount) > baselinePerMinute*10 {
+		severity = "Critical"
+	}
+	return []incidentFinding{{section: "Errors", severity: severity,
+		summary: fmt.Sprintf("%d spike minute(s), worst %d errors/min at %s (baseline %.1f/min)",
+			spikeMinutes, worstCount, utils.FormatTimestampShort(worstMinute), baselinePerMinute),
+		drillLabel: "errors heatmap", drill: drill}}
+}
+
+// incidentSlowHashes lists the query hashes that consumed the most total
+// execution time inside the window; each drills into its ProfileEvents
+func (a *App) incidentSlowHashes() []incidentFinding {
+	query := fmt.Sprintf(
+		"SELECT toString(normalized_query_hash) AS hash, count() AS cnt, "+
+			"round(avg(query_duration_ms)) AS avg_ms, max(query_duration_ms) AS max_ms "+
+			"FROM clusterAllReplicas('%s', merge(system,'^query_log$')) "+
+			"WHERE event_date >= toDate(?) AND event_date <= toDate(?) "+
+			"AND event_time >= ? AND event_time <= ? AND type != 'QueryStart' "+
+			"GROUP BY hash ORDER BY sum(query_duration_ms) DESC LIMIT 5 "+
+			"SETTINGS skip_unavailable_shards=1",
+		a.cluster)
+
+	rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("incident"), query,
+		a.fromTime, a.toTime, a.fromTime, a.toTime)
+	if err != nil {
+		log.Error().Err(err).Msg("can't fetch slow query hashes for incident report")
+		return nil
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close incidentSlowHashes rows")
+		}
+	}()
+
+	var findings []incidentFinding
+	for rows.Next() {
+		var hash string
+		var cnt uint64
+		var avgMs, maxMs float64
+		if scanErr := rows.Scan(&hash, &cnt, &avgMs, &maxMs); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan slow hash row")
+			continue
+		}
+		severity := ""
+		if maxMs > 60000 {
+			severity = "Moderate"
+		}
+		drillHash := hash
+		findings = append(findings, incidentFinding{section: "Slow queries", severity: severity,
+			summary:    fmt.Sprintf("hash %s: %d runs, avg %.0fms, max %.0fms", hash, cnt, avgMs, maxMs),
+			drillLabel: "profile events",
+			drill: func() {
+				a.ShowProfileEvents(CategoryQueryHash, drillHash, a.fromTime, a.toTime, a.cluster)
+			}})
+	}
+	return findings
+}
+
+// incidentAuditFindings runs the audit and reports its worst findings; the
+// full result set is one drill away
+func (a *App) incidentAuditFindings() []incidentFinding {
+	auditor := audit.NewAuditor(a.clickHouse, a.cluster, a.cfg).WithQueryContext(a.queryContext)
+	results := auditor.RunChecks(nil)
+
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[result.Severity]++
+	}
+	var findings []incidentFinding
+	for _, result := range results {
+		if result.Severity != "Critical" && result.Severity != "Major" {
+			continue
+		}
+		findings = append(findings, incidentFinding{section: "Audit", severity: result.Severity,
+			summary:    fmt.Sprintf("%s %s: %s", result.ID, result.Object, result.Details),
+			drillLabel: "audit", drill: a.ShowAudit})
+	}
+	if len(findings) == 0 {
+		findings = append(findings, incidentFinding{section: "Audit", severity: "",
+			summary:    fmt.Sprintf("no critical/major findings (%d total)", len(results)),
+			drillLabel: "audit", drill: a.ShowAudit})
+	}
+	return findings
+}
+
+// incidentReplicationAnomalies reports replicas whose delay or queue size
+// crosses the audit thresholds, plus read-only replicas
+func (a *App) incidentReplicationAnomalies() []incidentFinding {
+	var findings []incidentFinding
+	for _, row := range a.fetchReplicationSnapshot() {
+		severity := replicationLagSeverity(row.absoluteDelay, row.queueSize)
+		if row.isReadonly != 0 && severityRank(severity) < severityRank("Major") {
+			severity = "Major"
+		}
+		if severity == "" {
+			continue
+		}
+		findings = append(findings, incidentFinding{section: "Replication", severity: severity,
+			summary: fmt.Sprintf("%s `%s`.`%s`: delay %.0fs, queue %d",
+				row.host, row.database, row.table, row.absoluteDelay, row.queueSize),
+			drillLabel: "replication lag", drill: a.ShowReplicationLag})
+	}
+	return findings
+}
+
+// incidentMergeAnomalies reports merges running longer than an hour and
+// mutations that are failing or stuck
+func (a *App) incidentMergeAnomalies() []incidentFinding {
+	var findings []incidentFinding
+	for _, row := range a.fetchMergesSnapshot() {
+		if row.elapsed <= 3600 {
+			continue
+		}
+		findings = append(findings, incidentFinding{section: "Merges", severity: "Moderate",
+			summary: fmt.Sprintf("%s `%s`.`%s`: merge running %.0fs at %.0f%%",
+				row.host, row.database, row.table, row.elapsed, row.progress*100),
+			drillLabel: "merges monitor", drill: a.ShowMergesMonitor})
+	}
+	for _, row := range a.fetchMutationsSnapshot() {
+		severity := ""
+		if row.failReason != "" {
+			severity = "Major"
+		} else if time.Since(row.createTime) > time.Hour {
+			severity = "Moderate"
+		}
+		if severity == "" {
+			continue
+		}
+		findings = append(findings, incidentFinding{section: "Mutations", severity: severity,
+			summary: fmt.Sprintf("%s `%s`.`%s` %s: %d parts to do %s",
+				row.host, row.database, row.table, row.mutationID, row.partsToDo, row.failReason),
+			drillLabel: "merges monitor", drill: a.ShowMergesMonitor})
+	}
+	return findings
+}
+
+// incidentLogExcerpts surfaces the most repeated error-level server log
+// messages inside the window; drilling opens the log explorer on
+// system.text_log scoped to the same range
+func (a *App) incidentLogExcerpts() []incidentFinding {
+	drill := func() {
+		a.logPanel = &LogPanel{
+			app:          a,
+			windowSize:   1000,
+			database:     "system",
+			table:        "text_log",
+			messageField: "message",
+			timeField:    "event_time",
+			levelField:   "level",
+		}
+		a.logPanel.Show()
+	}
+	query := fmt.Sprintf(
+		"SELECT left(message, 160) AS msg, count() AS cnt "+
+			"FROM clusterAllReplicas('%s', merge(system,'^text_log$')) "+
+			"WHERE event_date >= toDate(?) AND event_date <= toDate(?) "+
+			"AND event_time >= ? AND event_time <= ? "+
+			"AND level IN ('Fatal','Critical','Error') "+
+			"GROUP BY msg ORDER BY cnt DESC LIMIT 5 "+
+			"SETTINGS skip_unavailable_shards=1",
+		a.cluster)
+
+	rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("incident"), query,
+		a.fromTime, a.toTime, a.fromTime, a.toTime)
+	if err != nil {
+		// text_log is optional; note its absence instead of failing the report
+		log.Error().Err(err).Msg("can't fetch log excerpts for incident report")
+		return []incidentFinding{{section: "Logs", severity: "",
+			summary: "system.text_log not available", drillLabel: "log explorer", drill: drill}}
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close incidentLogExcerpts rows")
+		}
+	}()
+
+	var findings []incidentFinding
+	for rows.Next() {
+		var msg string
+		var cnt uint64
+		if scanErr := rows.Scan(&msg, &cnt); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan log excerpt row")
+			continue
+		}
+		findings = append(findings, incidentFinding{section: "Logs", severity: "Minor",
+			summary:    fmt.Sprintf("%d x %s", cnt, msg),
+			drillLabel: "log explorer", drill: drill})
+	}
+	if len(findings) == 0 {
+		findings = append(findings, incidentFinding{section: "Logs", severity: "",
+			summary: "no error-level server log messages in window", drillLabel: "log explorer", drill: drill})
+	}
+	return findings
+}
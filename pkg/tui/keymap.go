@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// keyBinding is one key and what it does on a page
+type keyBinding struct {
+	key    string
+	action string
+}
+
+// pageKeymap groups the bindings of one page; pages is the tview page names
+// the group applies to, an empty list means the bindings work everywhere
+type pageKeymap struct {
+	title    string
+	pages    []string
+	bindings []keyBinding
+}
+
+// keymaps is the central registry behind the '?' cheatsheet overlay; help
+// texts render from here so the key lists can't drift apart from each other.
+// Order is display order, the entry matching the current page is shown first.
+var keymaps = []pageKeymap{
+	{
+		title: "Global",
+		bindings: []keyBinding{
+			{":", "Open the command prompt (:help lists commands)"},
+			{"Ctrl+K", "Command palette (commands, connections, bookmarks)"},
+			{"?", "Toggle this cheatsheet"},
+			{"/", "Filter the focused list or table"},
+			{"Alt+Left/Right", "Back / forward through visited pages"},
+			{"Esc", "Close the current page"},
+		},
+	},
+	{
+		title: "Logs explorer",
+		pages: []string{"logExplorer"},
+		bindings: []keyBinding{
+			{"Enter", "Open the selected entry's details (double-click works too)"},
+			{"Ctrl+PgUp/PgDn", "Load older / newer rows"},
+			{"Ctrl+R", "Reload (required once in safe mode)"},
+			{"Ctrl+E", "Error code dashboard"},
+			{"Ctrl+Y", "Compare level counts with 24h earlier"},
+			{"Ctrl+L", "Edit the level-value mapping"},
+			{"Ctrl+T", "Toggle whole-range totals in the overview bar"},
+			{"Click overview", "Jump to the next entry of the clicked level"},
+			{"Ctrl+G", "Ship the loaded entries to the OTLP collector"},
+			{"Ctrl+B", "Return from a pivot to the panel it was opened from"},
+		},
+	},
+	{
+		title: "Log details",
+		pages: []string{"logDetails"},
+		bindings: []keyBinding{
+			{"Ctrl+J", "Explore JSON inside the message"},
+			{"Ctrl+Q", "Explain flow for this entry's query_id"},
+			{"Ctrl+G", "Ship this entry to the OTLP collector"},
+			{"Tab", "Switch between field list and message"},
+		},
+	},
+	{
+		title: "Audit",
+		pages: []string{"audit", "audit_detail"},
+		bindings: []keyBinding{
+			{"Enter", "Open the selected finding's detail"},
+			{"Esc", "Back to the findings list"},
+		},
+	},
+	{
+		title: "Explain",
+		pages: []string{"explain", "explain_queries", "explain_result", "explain_compare", "explain_profile"},
+		bindings: []keyBinding{
+			{"Tab/Shift+Tab", "Cycle hash field, table and kind lists, buttons"},
+			{"Space/Enter", "Toggle the highlighted table or kind"},
+			{"Enter", "Inspect the selected query (queries list)"},
+			{"Ctrl+L", "Logs for this run's query_id (explain result)"},
+			{"Ctrl+P", "ProfileEvents and Settings vs hash median (explain result)"},
+		},
+	},
+	{
+		title: "Flamegraph",
+		pages: []string{"flamegraph", "flamegraph_form"},
+		bindings: []keyBinding{
+			{"Enter", "Zoom into the selected frame"},
+			{"Esc", "Zoom out / close"},
+		},
+	},
+	{
+		title: "Heatmap",
+		pages: []string{"heatmap"},
+		bindings: []keyBinding{
+			{"Enter/double-click", "Drill into the selected cell"},
+			{"Arrows", "Move the selection"},
+		},
+	},
+}
+
+// keymapOverlayPage is registered/removed as the overlay toggles
+const keymapOverlayPage = "keymap"
+
+// renderKeymap writes the registry as colored text, putting the group of the
+// given front page right after the global one
+func renderKeymap(frontPage string) string {
+	ordered := make([]pageKeymap, 0, len(keymaps))
+	var current []pageKeymap
+	for _, keymap := range keymaps {
+		matched := false
+		for _, page := range keymap.pages {
+			if page == frontPage {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			current = append(current, keymap)
+		} else {
+			ordered = append(ordered, keymap)
+		}
+	}
+	if len(ordered) > 1 {
+		ordered = append(append(ordered[:1:1], current...), ordered[1:]...)
+	}
+
+	var builder strings.Builder
+	for _, keymap := range ordered {
+		builder.WriteString(fmt.Sprintf("[yellow::b]%s[-::-]\n", keymap.title))
+		for _, binding := range keymap.bindings {
+			builder.WriteString(fmt.Sprintf("  [aqua]%-18s[-] %s\n", binding.key, binding.action))
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// toggleKeymapOverlay shows the cheatsheet on top of the current page, or
+// hides it when it is already up
+func (a *App) toggleKeymapOverlay() {
+	if a.pages.HasPage(keymapOverlayPage) {
+		a.pages.RemovePage(keymapOverlayPage)
+		return
+	}
+	frontPage, _ := a.pages.GetFrontPage()
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(renderKeymap(frontPage))
+	view.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle("Keybindings [yellow](?, Esc: close)[-]")
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape || event.Rune() == '?' {
+			a.pages.RemovePage(keymapOverlayPage)
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage(keymapOverlayPage, view, true, true)
+	a.tviewApp.SetFocus(view)
+}
@@ -0,0 +1,269 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// logCompareBuckets is how many time buckets each range is split into
+const logCompareBuckets = 60
+
+// logCompareShift is how far back the comparison window is moved, so the
+// current range is compared against the same window one day earlier
+const logCompareShift = 24 * time.Hour
+
+// logCompareFineSplit is how many sub-buckets each display bucket is counted
+// in; the per-bucket maximum of those sub-counts feeds the peak heat stripe,
+// so a short burst inside a wide bucket isn't averaged away
+const logCompareFineSplit = 4
+
+// levelTrend holds per-bucket counts of one log level for both compared ranges
+type levelTrend struct {
+	level         string
+	current       []float64
+	previous      []float64
+	currentPeaks  []float64
+	currentTotal  uint64
+	previousTotal uint64
+}
+
+// showLevelComparison overlays the current range's per-level counts with the
+// same window shifted back by logCompareShift, so "is this error volume
+// normal?" can be answered without exporting data. Active filters apply to
+// both ranges.
+func (lp *LogPanel) showLevelComparison() {
+	if lp.database == "" || lp.table == "" || lp.timeField == "" {
+		return
+	}
+
+	bucketSeconds := int(lp.app.toTime.Sub(lp.app.fromTime).Seconds()) / logCompareBuckets
+	if bucketSeconds < 1 {
+		bucketSeconds = 1
+	}
+
+	go func() {
+		current, currentPeaks, currentErr := lp.fetchLevelBuckets(lp.app.fromTime, lp.app.toTime, bucketSeconds)
+		if currentErr != nil {
+			lp.app.tviewApp.QueueUpdateDraw(func() {
+				lp.app.SwitchToMainPage(fmt.Sprintf("showLevelComparison query failed: %v", currentErr))
+			})
+			return
+		}
+		previous, _, previousErr := lp.fetchLevelBuckets(lp.app.fromTime.Add(-logCompareShift), lp.app.toTime.Add(-logCompareShift), bucketSeconds)
+		if previousErr != nil {
+			lp.app.tviewApp.QueueUpdateDraw(func() {
+				lp.app.SwitchToMainPage(fmt.Sprintf("showLevelComparison query failed: %v", previousErr))
+			})
+			return
+		}
+
+		trends := make(map[string]*levelTrend)
+		trendFor := func(level string) *levelTrend {
+			trend, found := trends[level]
+			if !found {
+				trend = &levelTrend{
+					level:        level,
+					current:      make([]float64, logCompareBuckets),
+					previous:     make([]float64, logCompareBuckets),
+					currentPeaks: make([]float64, logCompareBuckets),
+				}
+				trends[level] = trend
+			}
+			return trend
+		}
+		for level, counts := range current {
+			trend := trendFor(level)
+			for i, cnt := range counts {
+				trend.current[i] = cnt
+				trend.currentTotal += uint64(cnt)
+			}
+		}
+		for level, counts := range previous {
+			trend := trendFor(level)
+			for i, cnt := range counts {
+				trend.previous[i] = cnt
+				trend.previousTotal += uint64(cnt)
+			}
+		}
+		for level, peaks := range currentPeaks {
+			trend := trendFor(level)
+			copy(trend.currentPeaks, peaks)
+		}
+
+		sorted := make([]*levelTrend, 0, len(trends))
+		for _, trend := range trends {
+			sorted = append(sorted, trend)
+		}
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].currentTotal > sorted[j].currentTotal
+		})
+
+		lp.app.tviewApp.QueueUpdateDraw(func() {
+			lp.renderLevelComparison(sorted, false, SparklineScaleLinear)
+		})
+	}()
+}
+
+// fetchLevelBuckets counts entries per level per time bucket in [from, to],
+// applying the active explorer filters; buckets without entries stay zero so
+// both compared ranges align index by index. Counting happens at
+// logCompareFineSplit times finer granularity, the second return value holds
+// the largest sub-bucket count per display bucket for the peak heat stripe.
+func (lp *LogPanel) fetchLevelBuckets(from, to time.Time, bucketSeconds int) (map[string][]float64, map[string][]float64, error) {
+	fineSeconds := bucketSeconds / logCompareFineSplit
+	if fineSeconds < 1 {
+		fineSeconds = 1
+	}
+
+	levelExpr := "'all'"
+	if lp.levelField != "" {
+		levelExpr = fmt.Sprintf("toString(%s)", chquery.QuoteID(lp.levelField))
+	}
+	timeCondition := fmt.Sprintf("%s >= ? AND %s <= ?", chquery.QuoteID(lp.timeField), chquery.QuoteID(lp.timeField))
+	whereClause, args := lp.buildWhereClause(timeCondition, []interface{}{from, to})
+
+	query := fmt.Sprintf(
+		"SELECT %s AS level, toStartOfInterval(%s, INTERVAL %d SECOND) AS bucket, count() AS cnt "+
+			"FROM %s WHERE %s GROUP BY level, bucket ORDER BY level, bucket",
+		levelExpr, chquery.QuoteID(lp.timeField), fineSeconds,
+		lp.sourceExpr(), whereClause)
+
+	rows, err := lp.app.clickHouse.QueryContext(lp.app.queryContext(), query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close fetchLevelBuckets rows")
+		}
+	}()
+
+	buckets := make(map[string][]float64)
+	peaks := make(map[string][]float64)
+	for rows.Next() {
+		var level string
+		var bucket time.Time
+		var cnt uint64
+		if scanErr := rows.Scan(&level, &bucket, &cnt); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan level bucket row")
+			continue
+		}
+		index := int(bucket.Sub(from).Seconds()) / bucketSeconds
+		if index < 0 || index >= logCompareBuckets {
+			continue
+		}
+		level = lp.canonicalLevel(level)
+		if _, found := buckets[level]; !found {
+			buckets[level] = make([]float64, logCompareBuckets)
+			peaks[level] = make([]float64, logCompareBuckets)
+		}
+		buckets[level][index] += float64(cnt)
+		if float64(cnt) > peaks[level][index] {
+			peaks[level][index] = float64(cnt)
+		}
+	}
+	return buckets, peaks, nil
+}
+
+// renderLevelComparison shows both ranges' sparklines per level with a
+// differential line marking buckets that grew (red) or shrank (green); with
+// showPeaks an extra heat stripe per level shows the largest sub-bucket count
+// as background intensity, keeping short bursts visible. The scale strategy
+// switches the sparkline y-axis, because on a linear scale one error burst
+// hides every low-volume level.
+func (lp *LogPanel) renderLevelComparison(trends []*levelTrend, showPeaks bool, scale string) {
+	var builder strings.Builder
+	for _, trend := range trends {
+		change := "new"
+		changeColor := "red"
+		if trend.previousTotal > 0 {
+			delta := 100 * (float64(trend.currentTotal) - float64(trend.previousTotal)) / float64(trend.previousTotal)
+			switch {
+			case delta > 20:
+				changeColor = "red"
+			case delta < -20:
+				changeColor = "green"
+			default:
+				changeColor = "white"
+			}
+			change = fmt.Sprintf("%+.1f%%", delta)
+		}
+		builder.WriteString(fmt.Sprintf("[%s::b]%s[-::-]  now: %d  prev: %d  [%s]%s[-]\n",
+			lp.levelCompareColor(trend.level), trend.level, trend.currentTotal, trend.previousTotal, changeColor, change))
+		builder.WriteString(fmt.Sprintf("  now:  [%s]%s[-]\n", lp.levelCompareColor(trend.level), lp.app.GenerateSparklineScaled(trend.current, scale)))
+		if showPeaks {
+			builder.WriteString(fmt.Sprintf("  peak: %s\n", lp.app.GenerateHeatStripe(trend.currentPeaks)))
+		}
+		builder.WriteString(fmt.Sprintf("  prev: [gray]%s[-]\n", lp.app.GenerateSparklineScaled(trend.previous, scale)))
+		builder.WriteString(fmt.Sprintf("  diff: %s\n\n", compareDiffLine(trend.current, trend.previous)))
+	}
+	if len(trends) == 0 {
+		builder.WriteString("No log entries in either range")
+	}
+
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(builder.String())
+	view.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Level comparison `%s`.`%s` | %s - %s vs 24h earlier | scale: %s [yellow](s: cycle scale, p: toggle peak stripes, Esc: return)[-]",
+			lp.database, lp.table, utils.FormatTimestampShort(lp.app.fromTime), utils.FormatTimestampShort(lp.app.toTime), scale))
+	view.SetDoneFunc(func(key tcell.Key) {
+		lp.app.pages.RemovePage("logCompare")
+		lp.app.pages.SwitchToPage("logExplorer")
+	})
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'p':
+			lp.renderLevelComparison(trends, !showPeaks, scale)
+			return nil
+		case 's':
+			lp.renderLevelComparison(trends, showPeaks, nextSparklineScale(scale))
+			return nil
+		}
+		return event
+	})
+
+	lp.app.pages.AddPage("logCompare", view, true, true)
+	lp.app.pages.SwitchToPage("logCompare")
+	lp.app.tviewApp.SetFocus(view)
+}
+
+// compareDiffLine marks each bucket where the current range grew by more than
+// 20% over the previous one in red, shrank by more than 20% in green, and
+// stayed about the same in gray
+func compareDiffLine(current, previous []float64) string {
+	var builder strings.Builder
+	for i := range current {
+		switch {
+		case current[i] > previous[i]*1.2:
+			builder.WriteString("[red]▲[-]")
+		case current[i] < previous[i]*0.8:
+			builder.WriteString("[green]▼[-]")
+		default:
+			builder.WriteString("[gray]·[-]")
+		}
+	}
+	return builder.String()
+}
+
+// levelCompareColor maps a level name to the color used in the overview bar
+func (lp *LogPanel) levelCompareColor(level string) string {
+	switch levelColor(lp.canonicalLevel(level)) {
+	case tcell.ColorRed:
+		return "red"
+	case tcell.ColorYellow:
+		return "yellow"
+	case tcell.ColorGreen:
+		return "green"
+	default:
+		return "cyan"
+	}
+}
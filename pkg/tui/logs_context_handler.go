@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"strings"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// logContextRows is how many rows are fetched on each side of the selected entry
+const logContextRows = 20
+
+// showLogContext fetches rows before and after the selected entry's timestamp
+// regardless of active filters (similar to grep -C) and renders them in a
+// split pane with the selected entry in the middle
+func (lp *LogPanel) showLogContext(entry LogEntry) {
+	ts := entry.Time
+	if !entry.TimeMs.IsZero() {
+		ts = entry.TimeMs
+	}
+	if ts.IsZero() {
+		return
+	}
+
+	go func() {
+		before := lp.fetchLogContext("<", "DESC", ts)
+		after := lp.fetchLogContext(">=", "ASC", ts)
+
+		// The DESC query returns newest-first, flip it back to natural order
+		for i, j := 0, len(before)-1; i < j; i, j = i+1, j-1 {
+			before[i], before[j] = before[j], before[i]
+		}
+
+		lp.app.tviewApp.QueueUpdateDraw(func() {
+			lp.renderLogContext(entry, ts, before, after)
+		})
+	}()
+}
+
+// fetchLogContext loads up to logContextRows one-line entries on one side of
+// the timestamp, deliberately without the active filters
+func (lp *LogPanel) fetchLogContext(comparison, order string, ts time.Time) []string {
+	messageSelect := chquery.QuoteID(lp.messageField)
+	if lp.maxMessageLength > 0 {
+		messageSelect = fmt.Sprintf("substring(%s, 1, %d)", chquery.QuoteID(lp.messageField), lp.maxMessageLength)
+	}
+	levelSelect := "''"
+	if lp.levelField != "" {
+		levelSelect = fmt.Sprintf("toString(%s)", chquery.QuoteID(lp.levelField))
+	}
+	query := fmt.Sprintf("SELECT %s, %s, %s FROM %s WHERE %s %s ? ORDER BY %s %s LIMIT %d",
+		chquery.QuoteID(lp.timeField), levelSelect, messageSelect,
+		lp.sourceExpr(),
+		chquery.QuoteID(lp.timeField), comparison, chquery.QuoteID(lp.timeField), order, logContextRows)
+
+	rows, err := lp.app.clickHouse.QueryContext(lp.app.queryContext(), query, ts)
+	if err != nil {
+		log.Error().Err(err).Msg("can't fetch log context rows")
+		return nil
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close fetchLogContext rows")
+		}
+	}()
+
+	var lines []string
+	for rows.Next() {
+		var t time.Time
+		var level, message string
+		if scanErr := rows.Scan(&t, &level, &message); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan log context row")
+			continue
+		}
+		lines = append(lines, formatLogContextLine(t, level, message))
+	}
+	return lines
+}
+
+// formatLogContextLine renders one context entry on a single line
+func formatLogContextLine(t time.Time, level, message string) string {
+	message = strings.ReplaceAll(message, "\n", " ")
+	if level != "" {
+		return fmt.Sprintf("%s [%s] %s", utils.FormatTimestampShort(t), level, message)
+	}
+	return fmt.Sprintf("%s %s", utils.FormatTimestampShort(t), message)
+}
+
+// renderLogContext shows the context split pane: rows before on top, the
+// selected entry highlighted in the middle, rows after below
+func (lp *LogPanel) renderLogContext(entry LogEntry, ts time.Time, before, after []string) {
+	beforeView := tview.NewTextView().
+		SetDynamicColors(false).
+		SetScrollable(true)
+	beforeView.SetBorder(true).SetTitle(fmt.Sprintf("%d rows before (unfiltered)", len(before)))
+	beforeView.SetText(strings.Join(before, "\n"))
+	beforeView.ScrollToEnd()
+
+	selectedView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true)
+	selectedView.SetBorder(true).SetTitle("Selected entry")
+	selectedView.SetText(fmt.Sprintf("[yellow::b]%s[-:-:-]", formatLogContextLine(ts, entry.Level, entry.Message)))
+
+	afterView := tview.NewTextView().
+		SetDynamicColors(false).
+		SetScrollable(true)
+	afterView.SetBorder(true).SetTitle(fmt.Sprintf("%d rows after (unfiltered)", len(after)))
+	afterView.SetText(strings.Join(after, "\n"))
+
+	contextFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(beforeView, 0, 2, true).
+		AddItem(selectedView, 4, 0, false).
+		AddItem(afterView, 0, 2, false)
+
+	navigate := func(view *tview.TextView, other *tview.TextView) func(event *tcell.EventKey) *tcell.EventKey {
+		return func(event *tcell.EventKey) *tcell.EventKey {
+			if event.Key() == tcell.KeyTab || event.Key() == tcell.KeyBacktab {
+				lp.app.tviewApp.SetFocus(other)
+				return nil
+			} else if event.Key() == tcell.KeyEscape {
+				lp.app.pages.RemovePage("logContext")
+				lp.app.pages.SwitchToPage("logDetails")
+				return nil
+			}
+			return event
+		}
+	}
+	beforeView.SetInputCapture(navigate(beforeView, afterView))
+	afterView.SetInputCapture(navigate(afterView, beforeView))
+
+	lp.app.pages.AddPage("logContext", contextFlex, true, true)
+	lp.app.pages.SwitchToPage("logContext")
+	lp.app.tviewApp.SetFocus(beforeView)
+}
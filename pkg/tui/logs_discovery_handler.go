@@ -0,0 +1,163 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// logTableCandidate is one table which looks like a log table: it has a
+// DateTime column, a String message column and optionally a level-like column
+type logTableCandidate struct {
+	database     string
+	table        string
+	timeField    string
+	timeMsField  string
+	dateField    string
+	messageField string
+	levelField   string
+	totalRows    uint64
+}
+
+const discoverLogTablesQuery = `
+WITH candidates AS (
+	SELECT
+		database,
+		table,
+		anyIf(name, type LIKE 'DateTime%' AND type NOT LIKE 'DateTime64%') AS time_field,
+		anyIf(name, type LIKE 'DateTime64%') AS time_ms_field,
+		anyIf(name, type IN ('Date', 'Date32')) AS date_field,
+		anyIf(name, type = 'String' AND match(lower(name), 'message|msg|text|body|log')) AS named_message_field,
+		anyIf(name, type = 'String') AS fallback_message_field,
+		anyIf(name, (type LIKE 'Enum%' OR type = 'LowCardinality(String)') AND match(lower(name), 'level|severity|priority')) AS level_field
+	FROM system.columns
+	WHERE database NOT IN ('INFORMATION_SCHEMA', 'information_schema')
+	GROUP BY database, table
+	HAVING (time_field != '' OR time_ms_field != '') AND (named_message_field != '' OR fallback_message_field != '')
+)
+SELECT
+	c.database,
+	c.table,
+	c.time_field,
+	c.time_ms_field,
+	c.date_field,
+	if(c.named_message_field != '', c.named_message_field, c.fallback_message_field) AS message_field,
+	c.level_field,
+	coalesce(t.total_rows, 0) AS total_rows,
+	(c.named_message_field != '') * 2 + (c.level_field != '') AS score
+FROM candidates AS c
+LEFT JOIN system.tables AS t ON t.database = c.database AND t.name = c.table
+ORDER BY score DESC, total_rows DESC
+LIMIT 100
+`
+
+// discoverLogTables scans system.columns for tables looking like log tables
+// and presents ranked candidates, so users unfamiliar with the schema can
+// start exploring in seconds. Runs outside the UI goroutine.
+func (lp *LogPanel) discoverLogTables() {
+	rows, err := lp.app.clickHouse.QueryContext(lp.app.queryContext(), discoverLogTablesQuery)
+	if err != nil {
+		lp.app.tviewApp.QueueUpdateDraw(func() {
+			lp.app.SwitchToMainPage(fmt.Sprintf("discoverLogTables Query failed: %v", err))
+		})
+		return
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close discoverLogTables rows")
+		}
+	}()
+
+	var candidates []logTableCandidate
+	for rows.Next() {
+		var c logTableCandidate
+		var score uint8
+		if scanErr := rows.Scan(&c.database, &c.table, &c.timeField, &c.timeMsField, &c.dateField, &c.messageField, &c.levelField, &c.totalRows, &score); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan discoverLogTables row")
+			continue
+		}
+		candidates = append(candidates, c)
+	}
+
+	lp.app.tviewApp.QueueUpdateDraw(func() {
+		if len(candidates) == 0 {
+			lp.app.SwitchToMainPage("No log table candidates found in system.columns")
+			return
+		}
+		lp.showLogTableCandidates(candidates)
+	})
+}
+
+// showLogTableCandidates renders the ranked discovery results, selecting a
+// candidate pre-fills the log explorer form with the detected fields
+func (lp *LogPanel) showLogTableCandidates(candidates []logTableCandidate) {
+	candidatesTList := tview.NewList().ShowSecondaryText(false)
+	candidatesTList.SetMainTextColor(tcell.ColorWhite)
+	candidatesFL := widgets.NewFilteredList(candidatesTList, "Log table candidates (Enter to explore)", []string{}, "log_tables_filter")
+	candidatesList := candidatesFL.List
+	candidatesList.SetBorder(true).SetTitle("Log table candidates (Enter to explore)")
+
+	displayMap := make(map[string]logTableCandidate)
+	var items []string
+	for _, c := range candidates {
+		level := ""
+		if c.levelField != "" {
+			level = fmt.Sprintf(", level: %s", c.levelField)
+		}
+		display := fmt.Sprintf("%s.%s (%s rows) message: %s, time: %s%s",
+			c.database, c.table, utils.FormatReadable(float64(c.totalRows), 0), c.messageField, c.timeField, level)
+		items = append(items, display)
+		displayMap[display] = c
+	}
+	candidatesFL.Items = items
+
+	candidatesFL.RenderList = func(list *tview.List, items []string) {
+		list.Clear()
+		for _, display := range items {
+			if c, ok := displayMap[display]; ok {
+				candidate := c
+				list.AddItem(display, "", 0, func() {
+					lp.database = candidate.database
+					lp.table = candidate.table
+					lp.messageField = candidate.messageField
+					lp.timeField = candidate.timeField
+					lp.timeMsField = candidate.timeMsField
+					lp.dateField = candidate.dateField
+					lp.levelField = candidate.levelField
+					if lp.timeField == "" {
+						lp.timeField = candidate.timeMsField
+					}
+					// Re-show the form with the detected fields preselected
+					lp.autoSubmitted = false
+					lp.Show()
+				})
+			} else {
+				list.AddItem(display, "", 0, nil)
+			}
+		}
+	}
+	candidatesFL.ResetList()
+
+	candidatesList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event == nil {
+			return event
+		}
+		if event.Rune() == '/' {
+			candidatesFL.ShowFilterInput(lp.app.tviewApp, lp.app.pages)
+			return nil
+		}
+		if event.Key() == tcell.KeyEscape {
+			lp.app.pages.SwitchToPage("logs")
+			return nil
+		}
+		return event
+	})
+
+	lp.app.pages.AddPage("logTableCandidates", candidatesList, true, true)
+	lp.app.pages.SwitchToPage("logTableCandidates")
+	lp.app.tviewApp.SetFocus(candidatesList)
+}
@@ -0,0 +1,171 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"sort"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// errorCodeBuckets is how many time buckets the trend sparkline is built from
+const errorCodeBuckets = 60
+
+// errorCodeStat aggregates one "Code: NNN" exception code over the time range
+type errorCodeStat struct {
+	code   uint32
+	name   string
+	total  uint64
+	counts []float64 // per-bucket counts in chronological order
+}
+
+// showErrorCodeDashboard aggregates ClickHouse exception codes parsed out of
+// the message field (the "Code: NNN" prefix used by text_log and application
+// logs alike) over the whole application time range, and shows top codes with
+// their symbolic names and a per-bucket trend; Enter filters the log explorer
+// by the selected code
+func (lp *LogPanel) showErrorCodeDashboard() {
+	if lp.database == "" || lp.table == "" || lp.messageField == "" || lp.timeField == "" {
+		return
+	}
+
+	bucketSeconds := int(lp.app.toTime.Sub(lp.app.fromTime).Seconds()) / errorCodeBuckets
+	if bucketSeconds < 1 {
+		bucketSeconds = 1
+	}
+
+	query := fmt.Sprintf(
+		"WITH toUInt32OrZero(extract(%s, 'Code:\\\\s*(\\\\d+)')) AS code "+
+			"SELECT code, errorCodeToName(code) AS name, toStartOfInterval(%s, INTERVAL %d SECOND) AS bucket, count() AS cnt "+
+			"FROM %s "+
+			"WHERE code > 0 AND %s >= ? AND %s <= ? "+
+			"GROUP BY code, name, bucket ORDER BY code, bucket",
+		chquery.QuoteID(lp.messageField), chquery.QuoteID(lp.timeField), bucketSeconds,
+		lp.sourceExpr(),
+		chquery.QuoteID(lp.timeField), chquery.QuoteID(lp.timeField))
+
+	go func() {
+		rows, err := lp.app.clickHouse.QueryContext(lp.app.queryContext(), query, lp.app.fromTime, lp.app.toTime)
+		if err != nil {
+			lp.app.tviewApp.QueueUpdateDraw(func() {
+				lp.app.SwitchToMainPage(fmt.Sprintf("showErrorCodeDashboard query failed: %v", err))
+			})
+			return
+		}
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msgf("can't close showErrorCodeDashboard rows")
+			}
+		}()
+
+		stats := make(map[uint32]*errorCodeStat)
+		for rows.Next() {
+			var code uint32
+			var name string
+			var bucket time.Time
+			var cnt uint64
+			if scanErr := rows.Scan(&code, &name, &bucket, &cnt); scanErr != nil {
+				log.Error().Err(scanErr).Msg("can't scan error code row")
+				continue
+			}
+			stat, found := stats[code]
+			if !found {
+				stat = &errorCodeStat{code: code, name: name}
+				stats[code] = stat
+			}
+			stat.total += cnt
+			stat.counts = append(stat.counts, float64(cnt))
+		}
+
+		sorted := make([]*errorCodeStat, 0, len(stats))
+		for _, stat := range stats {
+			sorted = append(sorted, stat)
+		}
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].total > sorted[j].total
+		})
+
+		lp.app.tviewApp.QueueUpdateDraw(func() {
+			lp.renderErrorCodeDashboard(sorted)
+		})
+	}()
+}
+
+// renderErrorCodeDashboard shows the aggregated codes in a filtered table,
+// Enter pivots the log explorer to the selected code
+func (lp *LogPanel) renderErrorCodeDashboard(stats []*errorCodeStat) {
+	filteredTable := widgets.NewFilteredTable()
+	filteredTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	filteredTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Error codes in `%s`.`%s` [yellow](Enter: filter logs by code, Esc: return)[-]",
+			lp.database, lp.table))
+	filteredTable.SetupHeaders([]string{"Code", "Name", "Count", "Trend"})
+
+	for _, stat := range stats {
+		color := tcell.ColorWhite
+		switch {
+		case stat.total >= 1000:
+			color = tcell.ColorRed
+		case stat.total >= 100:
+			color = tcell.ColorYellow
+		}
+		filteredTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(fmt.Sprintf("%d", stat.code)).
+				SetTextColor(color).
+				SetAlign(tview.AlignRight),
+			tview.NewTableCell(stat.name).
+				SetTextColor(color).
+				SetAlign(tview.AlignLeft),
+			tview.NewTableCell(fmt.Sprintf("%d", stat.total)).
+				SetTextColor(color).
+				SetAlign(tview.AlignRight),
+			tview.NewTableCell(lp.app.GenerateSparkline(stat.counts)).
+				SetTextColor(color).
+				SetAlign(tview.AlignLeft),
+		})
+	}
+
+	existingHandler := filteredTable.GetInputCapture(lp.app.tviewApp, lp.app.pages)
+	filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if result := existingHandler(event); result == nil {
+			return nil
+		}
+		switch event.Key() {
+		case tcell.KeyEnter:
+			row, _ := filteredTable.Table.GetSelection()
+			if row > 0 && row <= len(stats) {
+				lp.filterByErrorCode(stats[row-1].code)
+			}
+			return nil
+		case tcell.KeyEscape:
+			lp.app.pages.RemovePage("logErrorCodes")
+			lp.app.pages.SwitchToPage("logExplorer")
+			return nil
+		}
+		return event
+	})
+
+	lp.app.pages.AddPage("logErrorCodes", filteredTable.Table, true, true)
+	lp.app.pages.SwitchToPage("logErrorCodes")
+	lp.app.tviewApp.SetFocus(filteredTable.Table)
+}
+
+// filterByErrorCode narrows the log explorer to messages carrying the given
+// exception code and reloads it
+func (lp *LogPanel) filterByErrorCode(code uint32) {
+	lp.filters = append(lp.filters, LogFilter{
+		Field:    lp.messageField,
+		Operator: "MATCH",
+		Value:    fmt.Sprintf("Code:\\s*%d\\b", code),
+	})
+	lp.app.pages.RemovePage("logErrorCodes")
+	lp.app.pages.SwitchToPage("logExplorer")
+	lp.updateFilterDisplay(lp.filterPanel)
+	go lp.loadLogs()
+}
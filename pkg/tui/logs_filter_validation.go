@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/araddon/dateparse"
+	"github.com/rs/zerolog/log"
+)
+
+var enumValueRegexp = regexp.MustCompile(`'((?:[^'\\]|\\.)*)'\s*=`)
+
+// hasToken() only accepts a single token, i.e. a needle without separators
+var tokenRegexp = regexp.MustCompile(`^[0-9a-zA-Z_]+$`)
+
+// unwrapFieldType strips Nullable(...) and LowCardinality(...) wrappers so the
+// validation looks at the underlying ClickHouse type
+func unwrapFieldType(fieldType string) string {
+	for {
+		switch {
+		case strings.HasPrefix(fieldType, "Nullable(") && strings.HasSuffix(fieldType, ")"):
+			fieldType = fieldType[len("Nullable(") : len(fieldType)-1]
+		case strings.HasPrefix(fieldType, "LowCardinality(") && strings.HasSuffix(fieldType, ")"):
+			fieldType = fieldType[len("LowCardinality(") : len(fieldType)-1]
+		default:
+			return fieldType
+		}
+	}
+}
+
+// enumValues parses allowed values out of an Enum8/Enum16 type definition
+func enumValues(fieldType string) []string {
+	var values []string
+	for _, match := range enumValueRegexp.FindAllStringSubmatch(fieldType, -1) {
+		values = append(values, strings.ReplaceAll(match[1], "\\'", "'"))
+	}
+	return values
+}
+
+// fetchFilterValueSuggestions returns known values for Enum fields and the most
+// frequent values (topK) for LowCardinality(String) fields, so the user doesn't
+// need to remember exact level strings or status codes
+func (lp *LogPanel) fetchFilterValueSuggestions(field string) []string {
+	fieldType, found := lp.fieldTypes[field]
+	if !found {
+		return nil
+	}
+	baseType := unwrapFieldType(fieldType)
+	if strings.HasPrefix(baseType, "Enum") {
+		return enumValues(baseType)
+	}
+	if !strings.Contains(fieldType, "LowCardinality") || !strings.Contains(baseType, "String") {
+		return nil
+	}
+
+	query := fmt.Sprintf("SELECT arrayJoin(topK(100)(%s)) AS v FROM %s", chquery.QuoteID(field), lp.sourceExpr())
+	rows, err := lp.app.clickHouse.QueryContext(lp.app.queryContext(), query)
+	if err != nil {
+		log.Error().Err(err).Msg("can't fetch filter value suggestions")
+		return nil
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close fetchFilterValueSuggestions rows")
+		}
+	}()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if scanErr := rows.Scan(&value); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan filter value suggestion")
+			continue
+		}
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// validateFilterValue checks a filter value against the ClickHouse type of the
+// selected field before the filter is added, so avoidable query errors are
+// caught in the form instead of failing on the server
+func (lp *LogPanel) validateFilterValue(field, operator, value string) error {
+	// match() and hasToken() take the value as-is regardless of the field type
+	switch operator {
+	case "MATCH":
+		if _, err := regexp.Compile(value); err != nil {
+			return fmt.Errorf("'%s' is not a valid re2 regular expression: %v", value, err)
+		}
+		return nil
+	case "HAS TOKEN":
+		if !tokenRegexp.MatchString(value) {
+			return fmt.Errorf("hasToken needs a single token without separators, '%s' doesn't qualify", value)
+		}
+		return nil
+	case "JSON =", "JSON !=":
+		if !strings.Contains(value, "=") {
+			return fmt.Errorf("JSON filters need '<path>=<value>', e.g. 'request.status=500', got '%s'", value)
+		}
+		return nil
+	}
+
+	fieldType, found := lp.fieldTypes[field]
+	if !found {
+		return nil // unknown type, let the server decide
+	}
+	baseType := unwrapFieldType(fieldType)
+
+	switch {
+	case strings.HasPrefix(baseType, "Enum"):
+		allowed := enumValues(baseType)
+		// LIKE patterns against enums are matched as strings by ClickHouse
+		if strings.Contains(operator, "LIKE") {
+			return nil
+		}
+		for _, v := range allowed {
+			if v == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("'%s' is not a value of %s, allowed values: %s", value, baseType, strings.Join(allowed, ", "))
+	case strings.HasPrefix(baseType, "Int") || strings.HasPrefix(baseType, "UInt") ||
+		strings.HasPrefix(baseType, "Float") || strings.HasPrefix(baseType, "Decimal"):
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("field '%s' is %s, but '%s' is not a number", field, fieldType, value)
+		}
+	case strings.HasPrefix(baseType, "Date"):
+		if _, err := dateparse.ParseAny(value); err != nil {
+			return fmt.Errorf("field '%s' is %s, but '%s' is not a parsable date (e.g. 2006-01-02 15:04:05)", field, fieldType, value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// jsonTreeEntry is one leaf of a flattened JSON document: a dotted path as
+// JSON_VALUE understands it (without the "$." prefix) and its scalar value
+type jsonTreeEntry struct {
+	path  string
+	value string
+}
+
+// extractJSON finds a JSON document in a log message: either the whole
+// message or, for "prefix {json}" lines, the outermost braced substring
+func extractJSON(text string) (interface{}, bool) {
+	text = strings.TrimSpace(text)
+	var node interface{}
+	if unmarshalErr := json.Unmarshal([]byte(text), &node); unmarshalErr == nil {
+		switch node.(type) {
+		case map[string]interface{}, []interface{}:
+			return node, true
+		}
+		return nil, false
+	}
+	start := strings.IndexByte(text, '{')
+	end := strings.LastIndexByte(text, '}')
+	if start < 0 || end <= start {
+		return nil, false
+	}
+	if unmarshalErr := json.Unmarshal([]byte(text[start:end+1]), &node); unmarshalErr != nil {
+		return nil, false
+	}
+	return node, true
+}
+
+// flattenJSON walks a decoded JSON document and collects every leaf with its
+// dotted path, array elements addressed by index
+func flattenJSON(prefix string, node interface{}, entries []jsonTreeEntry) []jsonTreeEntry {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			entries = flattenJSON(path, v[key], entries)
+		}
+	case []interface{}:
+		for i, item := range v {
+			entries = flattenJSON(fmt.Sprintf("%s[%d]", prefix, i), item, entries)
+		}
+	case nil:
+		entries = append(entries, jsonTreeEntry{path: prefix, value: "null"})
+	case string:
+		entries = append(entries, jsonTreeEntry{path: prefix, value: v})
+	default:
+		entries = append(entries, jsonTreeEntry{path: prefix, value: fmt.Sprintf("%v", v)})
+	}
+	return entries
+}
+
+// showJSONTree pretty-prints the JSON found in a message and lists every
+// nested key; Enter adds a JSON_VALUE filter on the selected path, turning a
+// structured log line into a filter without writing the extraction by hand
+func (lp *LogPanel) showJSONTree(field, text string) {
+	node, found := extractJSON(text)
+	if !found {
+		return
+	}
+
+	pretty, marshalErr := json.MarshalIndent(node, "", "  ")
+	if marshalErr != nil {
+		return
+	}
+	prettyText := tview.NewTextView().
+		SetWordWrap(false).
+		SetScrollable(true)
+	prettyText.SetBorder(true).SetTitle(fmt.Sprintf("JSON in %s", field))
+	prettyText.SetText(string(pretty))
+
+	entries := flattenJSON("", node, nil)
+	keysList := tview.NewList()
+	keysList.SetBorder(true).
+		SetTitle("Nested keys (Enter: filter on key, Tab: scroll JSON, Esc: back)")
+	keysList.ShowSecondaryText(false)
+	keysList.SetSelectedBackgroundColor(tcell.ColorBlue)
+	for _, entry := range entries {
+		keysList.AddItem(fmt.Sprintf("[yellow]%s:[-] %s", entry.path, entry.value), "", 0, nil)
+	}
+
+	keysList.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		if index < 0 || index >= len(entries) {
+			return
+		}
+		entry := entries[index]
+		lp.filters = append(lp.filters, LogFilter{
+			Field:    field,
+			Operator: "JSON =",
+			Value:    entry.path + "=" + entry.value,
+		})
+		lp.updateFilterDisplay(lp.filterPanel)
+		lp.app.pages.RemovePage("logJSONTree")
+		lp.app.pages.RemovePage("logDetails")
+		lp.app.pages.SwitchToPage("logExplorer")
+		go lp.loadLogs()
+	})
+
+	closeTree := func() {
+		lp.app.pages.RemovePage("logJSONTree")
+		lp.app.pages.SwitchToPage("logDetails")
+	}
+	keysList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeTree()
+			return nil
+		case tcell.KeyTab:
+			lp.app.tviewApp.SetFocus(prettyText)
+			return nil
+		}
+		return event
+	})
+	prettyText.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeTree()
+			return nil
+		case tcell.KeyTab, tcell.KeyBacktab:
+			lp.app.tviewApp.SetFocus(keysList)
+			return nil
+		}
+		return event
+	})
+
+	treeFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(keysList, 0, 1, true).
+		AddItem(prettyText, 0, 1, false)
+
+	lp.app.pages.AddPage("logJSONTree", treeFlex, true, true)
+	lp.app.pages.SwitchToPage("logJSONTree")
+	lp.app.tviewApp.SetFocus(keysList)
+}
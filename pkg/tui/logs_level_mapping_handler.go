@@ -0,0 +1,302 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// canonicalLevels are the target values raw level values can be mapped to;
+// they match the names getColorForLevel and the overview bar understand
+var canonicalLevels = []string{"error", "warning", "info", "debug", "trace", "unknown"}
+
+// logLevelMapping persists how raw level-field values of one table translate
+// into canonical levels, so tables with numeric or unconventional level values
+// still get correct colors and sparklines
+type logLevelMapping struct {
+	Database string            `yaml:"database"`
+	Table    string            `yaml:"table"`
+	Field    string            `yaml:"field"`
+	Mapping  map[string]string `yaml:"mapping"`
+}
+
+// levelMappingsPath returns ~/.clickhouse-timeline/log-level-mappings.yaml
+func levelMappingsPath() string {
+	home, homeErr := os.UserHomeDir()
+	if homeErr != nil {
+		log.Error().Err(homeErr).Msg("can't detect home directory for level mappings")
+		return ""
+	}
+	return filepath.Join(home, ".clickhouse-timeline", "log-level-mappings.yaml")
+}
+
+// loadLevelMappings reads all persisted mappings, a missing file is not an error
+func loadLevelMappings() []logLevelMapping {
+	path := levelMappingsPath()
+	if path == "" {
+		return nil
+	}
+	data, readErr := os.ReadFile(path)
+	if readErr != nil {
+		if !os.IsNotExist(readErr) {
+			log.Error().Err(readErr).Str("path", path).Msg("can't read level mappings")
+		}
+		return nil
+	}
+	var mappings []logLevelMapping
+	if unmarshalErr := yaml.Unmarshal(data, &mappings); unmarshalErr != nil {
+		log.Error().Err(unmarshalErr).Str("path", path).Msg("can't parse level mappings")
+		return nil
+	}
+	return mappings
+}
+
+// restoreLevelMapping loads the persisted mapping for the current table;
+// editor-saved mappings win, config-file entries seed tables the editor never
+// touched
+func (lp *LogPanel) restoreLevelMapping() {
+	lp.levelMapping = nil
+	for _, mapping := range loadLevelMappings() {
+		if mapping.Database == lp.database && mapping.Table == lp.table && mapping.Field == lp.levelField {
+			lp.levelMapping = mapping.Mapping
+			return
+		}
+	}
+	if lp.app == nil || lp.app.cfg == nil {
+		return
+	}
+	for _, mapping := range lp.app.cfg.LogLevelMappings {
+		if mapping.Database == lp.database && mapping.Table == lp.table && mapping.Field == lp.levelField {
+			lp.levelMapping = mapping.Mapping
+			return
+		}
+	}
+}
+
+// saveLevelMapping persists the current table's mapping, replacing an earlier
+// entry for the same table and field
+func (lp *LogPanel) saveLevelMapping(mapping map[string]string) {
+	path := levelMappingsPath()
+	if path == "" {
+		return
+	}
+	mappings := loadLevelMappings()
+	kept := make([]logLevelMapping, 0, len(mappings)+1)
+	for _, m := range mappings {
+		if m.Database == lp.database && m.Table == lp.table && m.Field == lp.levelField {
+			continue
+		}
+		kept = append(kept, m)
+	}
+	kept = append(kept, logLevelMapping{Database: lp.database, Table: lp.table, Field: lp.levelField, Mapping: mapping})
+
+	data, marshalErr := yaml.Marshal(kept)
+	if marshalErr != nil {
+		log.Error().Err(marshalErr).Msg("can't marshal level mappings")
+		return
+	}
+	if writeErr := os.WriteFile(path, data, 0644); writeErr != nil {
+		log.Error().Err(writeErr).Str("path", path).Msg("can't write level mappings")
+	}
+}
+
+// canonicalLevel translates a raw level-field value through the configured
+// mapping; unmapped values pass through unchanged
+func (lp *LogPanel) canonicalLevel(level string) string {
+	if mapped, found := lp.levelMapping[level]; found && mapped != "" {
+		return mapped
+	}
+	return level
+}
+
+// levelColor maps a level to its display color; callers pass values through
+// canonicalLevel first so custom mappings color consistently everywhere
+func levelColor(level string) tcell.Color {
+	switch strings.ToLower(level) {
+	case "error", "exception", "fatal", "critical":
+		return currentTheme.critical
+	case "warning", "warn", "debug", "trace":
+		return currentTheme.warning
+	case "info", "information", "notice":
+		return currentTheme.ok
+	default:
+		return currentTheme.text
+	}
+}
+
+// levelColorTag is levelColor for dynamic-color text markup
+func levelColorTag(level string) string {
+	switch levelColor(level) {
+	case tcell.ColorRed:
+		return "[red]"
+	case tcell.ColorYellow:
+		return "[yellow]"
+	case tcell.ColorGreen:
+		return "[green]"
+	}
+	return "[white]"
+}
+
+// levelMultiIfExpr renders the configured mapping as a multiIf over levelExpr,
+// so aggregation queries group by canonical level server-side instead of
+// re-mapping rows in Go
+func (lp *LogPanel) levelMultiIfExpr(levelExpr string) string {
+	if len(lp.levelMapping) == 0 {
+		return levelExpr
+	}
+	raws := make([]string, 0, len(lp.levelMapping))
+	for raw := range lp.levelMapping {
+		raws = append(raws, raw)
+	}
+	sort.Strings(raws)
+
+	var builder strings.Builder
+	builder.WriteString("multiIf(")
+	for _, raw := range raws {
+		builder.WriteString(fmt.Sprintf("%s = '%s', '%s', ",
+			levelExpr, chquery.EscapeString(raw), chquery.EscapeString(lp.levelMapping[raw])))
+	}
+	builder.WriteString(levelExpr + ")")
+	return builder.String()
+}
+
+// guessCanonicalLevel proposes a default mapping for one raw value: name-based
+// matching first, then the numeric text_log level enum (1=Fatal .. 8=Trace)
+func guessCanonicalLevel(value string) string {
+	lower := strings.ToLower(value)
+	switch {
+	case strings.Contains(lower, "fatal"), strings.Contains(lower, "crit"), strings.Contains(lower, "err"), strings.Contains(lower, "exception"):
+		return "error"
+	case strings.Contains(lower, "warn"):
+		return "warning"
+	case strings.Contains(lower, "info"), strings.Contains(lower, "notice"):
+		return "info"
+	case strings.Contains(lower, "debug"):
+		return "debug"
+	case strings.Contains(lower, "trace"):
+		return "trace"
+	}
+	switch value {
+	case "1", "2", "3":
+		return "error"
+	case "4":
+		return "warning"
+	case "5", "6":
+		return "info"
+	case "7":
+		return "debug"
+	case "8":
+		return "trace"
+	}
+	return "unknown"
+}
+
+// showLevelMappingEditor queries the distinct values of the level field and
+// presents one dropdown per value preselected with a guessed canonical level;
+// Save persists the mapping and reloads the explorer
+func (lp *LogPanel) showLevelMappingEditor() {
+	if lp.database == "" || lp.table == "" || lp.levelField == "" {
+		return
+	}
+
+	query := fmt.Sprintf("SELECT toString(%s) AS value, count() AS cnt FROM %s GROUP BY value ORDER BY cnt DESC LIMIT 50",
+		chquery.QuoteID(lp.levelField), lp.sourceExpr())
+
+	go func() {
+		rows, err := lp.app.clickHouse.QueryContext(lp.app.queryContext(), query)
+		if err != nil {
+			lp.app.tviewApp.QueueUpdateDraw(func() {
+				lp.app.SwitchToMainPage(fmt.Sprintf("showLevelMappingEditor query failed: %v", err))
+			})
+			return
+		}
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msgf("can't close showLevelMappingEditor rows")
+			}
+		}()
+
+		var values []string
+		counts := make(map[string]uint64)
+		for rows.Next() {
+			var value string
+			var cnt uint64
+			if scanErr := rows.Scan(&value, &cnt); scanErr != nil {
+				log.Error().Err(scanErr).Msg("can't scan distinct level value")
+				continue
+			}
+			values = append(values, value)
+			counts[value] = cnt
+		}
+
+		lp.app.tviewApp.QueueUpdateDraw(func() {
+			lp.renderLevelMappingEditor(values, counts)
+		})
+	}()
+}
+
+// renderLevelMappingEditor builds the value → canonical level form
+func (lp *LogPanel) renderLevelMappingEditor(values []string, counts map[string]uint64) {
+	if len(values) == 0 {
+		lp.overview.SetText(fmt.Sprintf("No values found in level field `%s`", lp.levelField))
+		return
+	}
+
+	mapping := make(map[string]string, len(values))
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Level mapping for `%s`.`%s`.`%s` [yellow](Esc: cancel)[-]", lp.database, lp.table, lp.levelField))
+
+	for _, value := range values {
+		current := lp.canonicalLevel(value)
+		if current == value {
+			current = guessCanonicalLevel(value)
+		}
+		selected := 0
+		for i, level := range canonicalLevels {
+			if level == current {
+				selected = i
+				break
+			}
+		}
+		mapping[value] = canonicalLevels[selected]
+
+		mappedValue := value
+		form.AddDropDown(fmt.Sprintf("%s (%d rows): ", value, counts[value]), canonicalLevels, selected,
+			func(option string, optionIndex int) {
+				mapping[mappedValue] = option
+			})
+	}
+
+	form.AddButton("Save", func() {
+		lp.levelMapping = mapping
+		lp.saveLevelMapping(mapping)
+		lp.app.pages.RemovePage("logLevelMapping")
+		lp.app.pages.SwitchToPage("logExplorer")
+		go lp.loadLogs()
+	})
+	form.AddButton("Cancel", func() {
+		lp.app.pages.RemovePage("logLevelMapping")
+		lp.app.pages.SwitchToPage("logExplorer")
+	})
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			lp.app.pages.RemovePage("logLevelMapping")
+			lp.app.pages.SwitchToPage("logExplorer")
+			return nil
+		}
+		return event
+	})
+
+	lp.app.pages.AddPage("logLevelMapping", form, true, true)
+	lp.app.pages.SwitchToPage("logLevelMapping")
+	lp.app.tviewApp.SetFocus(form)
+}
@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LogSource is one database/table pair merged into the log stream, used when
+// an incident spans clickhouse-server logs and application log tables
+type LogSource struct {
+	Database string
+	Table    string
+}
+
+// logSourceColumn is the synthetic column tagging each merged row with the
+// source it came from
+const logSourceColumn = "_source"
+
+// parseLogSources turns "database.table" strings (--extra-source values) into
+// LogSource pairs, malformed entries are logged and skipped
+func parseLogSources(raw []string) []LogSource {
+	var sources []LogSource
+	for _, item := range raw {
+		parts := strings.SplitN(item, ".", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			log.Warn().Msgf("ignore extra log source '%s', expected database.table", item)
+			continue
+		}
+		sources = append(sources, LogSource{Database: parts[0], Table: parts[1]})
+	}
+	return sources
+}
+
+// allLogSources returns the primary table followed by the extra sources
+func (lp *LogPanel) allLogSources() []LogSource {
+	return append([]LogSource{{Database: lp.database, Table: lp.table}}, lp.extraSources...)
+}
+
+// buildMergedQuery merges all sources by timestamp with UNION ALL; only the
+// configured columns are selected because the source schemas usually differ,
+// and every row is tagged with the database.table it came from
+func (lp *LogPanel) buildMergedQuery(whereClause, orderBy string) string {
+	columns := []string{chquery.QuoteID(lp.timeField)}
+	if lp.timeMsField != "" {
+		columns = append(columns, chquery.QuoteID(lp.timeMsField))
+	}
+	if lp.dateField != "" {
+		columns = append(columns, chquery.QuoteID(lp.dateField))
+	}
+	if lp.levelField != "" {
+		columns = append(columns, chquery.QuoteID(lp.levelField))
+	}
+	messageColumn := chquery.QuoteID(lp.messageField)
+	if lp.maxMessageLength > 0 {
+		messageColumn = fmt.Sprintf("substring(%s, 1, %d) AS %s", chquery.QuoteID(lp.messageField), lp.maxMessageLength, chquery.QuoteID(lp.messageField))
+	}
+	columns = append(columns, messageColumn)
+
+	var subQueries []string
+	for _, source := range lp.allLogSources() {
+		tagged := append(append([]string{}, columns...),
+			fmt.Sprintf("'%s' AS %s", chquery.EscapeString(source.Database+"."+source.Table), chquery.QuoteID(logSourceColumn)))
+		subQueries = append(subQueries, fmt.Sprintf("SELECT %s FROM %s WHERE %s",
+			strings.Join(tagged, ", "), chquery.QualifiedName(source.Database, source.Table), whereClause))
+	}
+
+	return fmt.Sprintf(`
+		SELECT * FROM (
+			%s
+		)
+		ORDER BY %s
+		LIMIT ?`,
+		strings.Join(subQueries, "\n\t\t\tUNION ALL\n\t\t\t"),
+		orderBy)
+}
+
+// repeatArgsPerSource duplicates the WHERE clause arguments once per merged
+// source, the clause is repeated verbatim inside every UNION ALL branch
+func (lp *LogPanel) repeatArgsPerSource(args []interface{}) []interface{} {
+	if len(lp.extraSources) == 0 {
+		return args
+	}
+	repeated := make([]interface{}, 0, len(args)*(len(lp.extraSources)+1))
+	for range lp.allLogSources() {
+		repeated = append(repeated, args...)
+	}
+	return repeated
+}
+
+// displayMessage prefixes merged rows with the source they came from
+func (lp *LogPanel) displayMessage(entry LogEntry) string {
+	if source, found := entry.AllFields[logSourceColumn]; found {
+		return fmt.Sprintf("%v | %s", source, entry.Message)
+	}
+	return entry.Message
+}
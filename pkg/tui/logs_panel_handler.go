@@ -0,0 +1,1891 @@
+package tui
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"github.com/Slach/clickhouse-timeline/pkg/otlp"
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rs/zerolog/log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rivo/tview"
+)
+
+type LogPanel struct {
+	app      *App
+	database string
+	table    string
+	// additional sources merged into the stream by timestamp
+	extraSources []LogSource
+	messageField string
+	timeField    string
+	timeMsField  string
+	dateField    string
+	levelField   string
+	windowSize   int
+	// fetch only substring(message,1,N) for the list view when > 0,
+	// the full message is fetched lazily when details are opened
+	maxMessageLength int
+	filters          []LogFilter
+	firstEntryTime   time.Time
+	lastEntryTime    time.Time
+	totalRows        int
+	logDetails       *widgets.FilteredTable
+	overview         *tview.TextView
+	// overviewSegments maps columns of the rendered overview bar back to the
+	// level each segment shows, so clicking a segment can select by level
+	overviewSegments []overviewSegment
+	filterPanel      *tview.Flex
+	mainFlex         *tview.Flex // Reference to the main flex container for resizing
+	databases        []string
+	tables           []string
+	allFields        []string          // Stores all field names from current table
+	fieldTypes       map[string]string // ClickHouse type per field name, used for filter validation
+	autoSubmitted    bool              // Flag to track if auto-submit has been done
+	levelMapping     map[string]string // Raw level value -> canonical level (error, warning, ...) for colors and stats
+	rangeTotals      bool              // Overview bar shows filtered totals over the whole range instead of the loaded page
+	previewSQL       bool              // When set, Add Filter shows the generated WHERE clause and scan estimate first
+	rowSeq           int               // Sequential id assigned to entries as they are loaded into the page
+	tieCounters      map[string]int    // Position per (timestamp, message) so identical rows stay distinguishable
+	// Keyset cursors for Ctrl+PageUp/PageDown paging: the most precise
+	// timestamp at each edge of the loaded page plus how many loaded rows
+	// share it, so the next page seeks past the edge instead of paging with
+	// a deepening OFFSET, and timestamp collisions are neither skipped nor
+	// loaded twice
+	oldestCursor logCursor
+	newestCursor logCursor
+	// matchingRows is the count() of rows matching the active filters over
+	// the whole selected range, -1 until the parallel count query returned;
+	// matchSeq drops a stale count when filters changed while it ran
+	matchingRows int64
+	matchSeq     int
+}
+
+// logCursor is one edge of the loaded page for keyset pagination
+type logCursor struct {
+	ts   time.Time
+	ties int
+}
+
+// overviewSegment is one level's span of the overview bar, in columns
+// relative to the overview's inner rect; end is exclusive
+type overviewSegment struct {
+	level string
+	start int
+	end   int
+}
+
+type LogFilter struct {
+	Field    string
+	Operator string
+	Value    string
+	// Subquery filters render Value as an inline SELECT, e.g. query_id IN (SELECT ...)
+	IsSubquery bool
+}
+
+type LogEntry struct {
+	Time      time.Time
+	TimeMs    time.Time
+	Date      string
+	Level     string
+	Message   string
+	AllFields map[string]interface{} // Stores all fields not in the main display
+	// RowID is a synthetic per-page identifier, TieBreak is the entry's
+	// position among rows sharing the same timestamp and message so lookups
+	// resolve to the exact row even when timestamps collide
+	RowID    int
+	TieBreak int
+}
+
+// tieKey groups entries that are indistinguishable by their most precise
+// timestamp and message; TieBreak numbers entries within one group
+func (lp *LogPanel) tieKey(entry LogEntry) string {
+	ts := entry.Time
+	if !entry.TimeMs.IsZero() {
+		ts = entry.TimeMs
+	}
+	return ts.Format(time.RFC3339Nano) + "\x00" + entry.Message
+}
+
+// entryQueryID extracts the query_id field of an entry when the source table
+// carries one (system.text_log does), "" otherwise
+func entryQueryID(entry LogEntry) string {
+	value, found := entry.AllFields["query_id"]
+	if !found {
+		return ""
+	}
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// entryFieldString renders any scanned field value as a string for export
+// attributes; []byte is what the driver returns for most string columns
+func entryFieldString(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// otlpEndpoint returns the OTLP/HTTP collector of the connected context,
+// empty when none is configured
+func (a *App) otlpEndpoint() string {
+	if a.selectedContext == nil {
+		return ""
+	}
+	return a.selectedContext.OTLPEndpoint
+}
+
+// loadedEntries collects the LogEntry references of every loaded row, i.e.
+// the slice of the selected time range currently paged in
+func (lp *LogPanel) loadedEntries() []LogEntry {
+	var entries []LogEntry
+	for row := 1; row <= lp.totalRows; row++ {
+		cell := lp.logDetails.Table.GetCell(row, 0)
+		if cell == nil || cell.Reference == nil {
+			continue
+		}
+		if entry, ok := cell.Reference.(LogEntry); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// exportEntriesToOTLP converts entries into OTLP LogRecords and ships them to
+// the collector configured in the context (otlp_endpoint); the outcome goes
+// through report so the explorer and the details modal each show it in place
+func (lp *LogPanel) exportEntriesToOTLP(entries []LogEntry, report func(string)) {
+	endpoint := lp.app.otlpEndpoint()
+	if endpoint == "" {
+		report("[red]No otlp_endpoint configured for this context[-]")
+		return
+	}
+	if len(entries) == 0 {
+		report("[red]No log entries loaded to export[-]")
+		return
+	}
+
+	records := make([]otlp.LogRecord, 0, len(entries))
+	for _, entry := range entries {
+		recordTime := entry.Time
+		if !entry.TimeMs.IsZero() {
+			recordTime = entry.TimeMs
+		}
+		attributes := make(map[string]string, len(entry.AllFields))
+		for field, value := range entry.AllFields {
+			attributes[field] = entryFieldString(value)
+		}
+		records = append(records, otlp.LogRecord{
+			Time:         recordTime,
+			SeverityText: entry.Level,
+			Body:         entry.Message,
+			Attributes:   attributes,
+		})
+	}
+
+	go func() {
+		err := otlp.ExportLogs(endpoint, "clickhouse-timeline", records)
+		lp.app.tviewApp.QueueUpdateDraw(func() {
+			if err != nil {
+				report(fmt.Sprintf("[red]OTLP export failed: %v[-]", err))
+				return
+			}
+			report(fmt.Sprintf("[green]Shipped %d log entries to %s[-]", len(records), endpoint))
+		})
+	}()
+}
+
+func (lp *LogPanel) Show() {
+	if lp.app.clickHouse == nil {
+		lp.app.SwitchToMainPage("Error: Please connect to ClickHouse first")
+		return
+	}
+
+	// Apply CLI params if available
+	if lp.app.CLI != nil {
+		if lp.app.CLI.LogsParams.Database != "" {
+			lp.database = lp.app.CLI.LogsParams.Database
+		}
+		if lp.app.CLI.LogsParams.Table != "" {
+			lp.table = lp.app.CLI.LogsParams.Table
+		}
+		if lp.app.CLI.LogsParams.Message != "" {
+			lp.messageField = lp.app.CLI.LogsParams.Message
+		}
+		if lp.app.CLI.LogsParams.Time != "" {
+			lp.timeField = lp.app.CLI.LogsParams.Time
+		}
+		if lp.app.CLI.LogsParams.TimeMs != "" {
+			lp.timeMsField = lp.app.CLI.LogsParams.TimeMs
+		}
+		if lp.app.CLI.LogsParams.Date != "" {
+			lp.dateField = lp.app.CLI.LogsParams.Date
+		}
+		if lp.app.CLI.LogsParams.Level != "" {
+			lp.levelField = lp.app.CLI.LogsParams.Level
+		}
+		if lp.app.CLI.LogsParams.Window > 0 {
+			lp.windowSize = lp.app.CLI.LogsParams.Window
+		}
+		if lp.app.CLI.LogsParams.MessageLength > 0 {
+			lp.maxMessageLength = lp.app.CLI.LogsParams.MessageLength
+		}
+	}
+
+	// Query ClickHouse for available databases (cached, the form reopens often)
+	databaseRows, err := lp.app.clickHouse.QueryStringsCached(lp.app.queryContext(), "SELECT name FROM system.databases")
+	if err != nil {
+		lp.app.SwitchToMainPage(fmt.Sprintf("Error getting databases: %v", err))
+		return
+	}
+
+	lp.databases = []string{lp.database}
+	for _, row := range databaseRows {
+		lp.databases = append(lp.databases, row[0])
+	}
+
+	// Detect renamed or dropped columns before the first query fails with an
+	// obscure error: compare the restored field configuration against the
+	// current table schema and suggest the closest matching names.
+	var schemaProblems []string
+	if lp.database != "" && lp.table != "" {
+		schemaProblems = lp.checkLogFieldsAgainstSchema()
+	}
+
+	// Create form with all fields
+	form := lp.createForm()
+
+	// Main flex layout
+	logsFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+	if len(schemaProblems) > 0 {
+		warning := tview.NewTextView().
+			SetDynamicColors(true).
+			SetText(fmt.Sprintf("[red]Saved log configuration doesn't match `%s`.`%s` schema:[-]\n%s",
+				lp.database, lp.table, strings.Join(schemaProblems, "\n")))
+		logsFlex.AddItem(warning, len(schemaProblems)+1, 0, false)
+	}
+	logsFlex.AddItem(form, 0, 1, true)
+
+	lp.app.pages.AddPage("logs", logsFlex, true, true)
+	lp.app.pages.SwitchToPage("logs")
+
+	// If all required fields are set via CLI, auto-submit the form (only once)
+	// In safe mode the user has to press "Explore Logs" themselves,
+	// and a schema mismatch always requires fixing the form fields first
+	if !lp.app.safeMode && !lp.autoSubmitted && len(schemaProblems) == 0 && lp.database != "" && lp.table != "" && lp.messageField != "" && lp.timeField != "" {
+		lp.autoSubmitted = true
+		go func() {
+			time.Sleep(500 * time.Millisecond) // Small delay to let UI render
+			lp.app.tviewApp.QueueUpdateDraw(func() {
+				lp.showLogExplorer()
+			})
+		}()
+	}
+}
+
+// checkLogFieldsAgainstSchema compares configured field names with the actual
+// columns of the selected table and returns one problem line per missing field,
+// including the closest-name suggestion. Missing fields are reset so the form
+// doesn't silently keep a column that no longer exists.
+func (lp *LogPanel) checkLogFieldsAgainstSchema() []string {
+	query := "SELECT name, type FROM system.columns WHERE database=? AND table=?"
+	columnRows, err := lp.app.clickHouse.QueryStringsCached(lp.app.queryContext(), query, lp.database, lp.table)
+	if err != nil {
+		log.Error().Err(err).Msg("can't check log fields against schema")
+		return nil
+	}
+
+	columns := make(map[string]bool)
+	var columnNames []string
+	if lp.fieldTypes == nil {
+		lp.fieldTypes = make(map[string]string)
+	}
+	for _, row := range columnRows {
+		name, fieldType := row[0], row[1]
+		columns[name] = true
+		columnNames = append(columnNames, name)
+		lp.fieldTypes[name] = fieldType
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	var problems []string
+	checkField := func(label string, field *string) {
+		if *field == "" || columns[*field] {
+			return
+		}
+		problem := fmt.Sprintf("  %s field [red]'%s'[-] not found", label, *field)
+		if suggestion := utils.ClosestName(*field, columnNames); suggestion != "" {
+			problem += fmt.Sprintf(", did you mean [green]'%s'[-]?", suggestion)
+		}
+		problems = append(problems, problem)
+		*field = ""
+	}
+	checkField("message", &lp.messageField)
+	checkField("time", &lp.timeField)
+	checkField("time-ms", &lp.timeMsField)
+	checkField("date", &lp.dateField)
+	checkField("level", &lp.levelField)
+	return problems
+}
+
+func (lp *LogPanel) createForm() *tview.Form {
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle("Log Explorer")
+
+	// Database dropdown - preselect if CLI param exists
+	dbIndex := 0
+	if lp.database != "" {
+		for i, db := range lp.databases {
+			if db == lp.database {
+				dbIndex = i
+				break
+			}
+		}
+	}
+	form.AddDropDown("Database", lp.databases, dbIndex, func(db string, index int) {
+		lp.database = db
+		lp.updateTableDropdown(form)
+	})
+
+	// Table dropdown - preselect if CLI param exists
+	form.AddDropDown("Table", []string{lp.table}, 0, func(table string, index int) {
+		lp.table = table
+		lp.updateFieldDropdowns(form)
+	})
+
+	// Ad-hoc table function source, e.g. remote('host:9000', system.text_log)
+	// or s3('https://...', 'Parquet'); overrides the table dropdown, the field
+	// dropdowns load via DESCRIBE once the expression is complete
+	tableFuncInitial := ""
+	if chquery.IsTableFunction(lp.table) {
+		tableFuncInitial = lp.table
+	}
+	form.AddInputField("Table Function (optional)", tableFuncInitial, 60, nil,
+		func(text string) {
+			expr := strings.TrimSpace(text)
+			if chquery.IsTableFunction(expr) && expr != lp.table {
+				lp.table = expr
+				lp.updateFieldDropdowns(form)
+			}
+		})
+
+	// Field dropdowns - preselect if CLI params exist
+	// Create dropdowns for each log field type
+	form.AddDropDown("Message Field: ", []string{lp.messageField}, 0, func(field string, index int) {
+		lp.messageField = field
+	})
+
+	form.AddDropDown("Time Field: ", []string{lp.timeField}, 0, func(field string, index int) {
+		lp.timeField = field
+	})
+
+	form.AddDropDown("TimeMs Field (optional): ", []string{lp.timeMsField}, 0, func(field string, index int) {
+		lp.timeMsField = field
+	})
+
+	form.AddDropDown("Date Field (optional): ", []string{lp.dateField}, 0, func(field string, index int) {
+		lp.dateField = field
+	})
+
+	form.AddDropDown("Level Field (optional): ", []string{lp.levelField}, 0, func(field string, index int) {
+		lp.levelField = field
+	})
+
+	// Window size input - use CLI param if available
+	form.AddInputField("Window Size (rows)", fmt.Sprint(lp.windowSize), 10,
+		func(text string, lastRune rune) bool { return unicode.IsDigit(lastRune) },
+		func(text string) { lp.windowSize, _ = strconv.Atoi(text) })
+
+	// Maximum message length for the list view, 0 fetches the full message
+	form.AddInputField("Max Message Length (0 = unlimited)", fmt.Sprint(lp.maxMessageLength), 10,
+		func(text string, lastRune rune) bool { return unicode.IsDigit(lastRune) },
+		func(text string) { lp.maxMessageLength, _ = strconv.Atoi(text) })
+
+	// Buttons
+	form.AddButton("Explore Logs", func() { lp.showLogExplorer() })
+	form.AddButton("Find Log Tables", func() { go lp.discoverLogTables() })
+	form.AddButton("Cancel", func() { lp.app.SwitchToMainPage("Returned from :logs") })
+
+	return form
+}
+
+func (lp *LogPanel) updateTableDropdown(form *tview.Form) {
+	if lp.database == "" {
+		return
+	}
+
+	// Query ClickHouse for tables in selected database (cached)
+	query := "SHOW TABLES FROM " + chquery.QuoteID(lp.database)
+	tableRows, err := lp.app.clickHouse.QueryStringsCached(lp.app.queryContext(), query)
+	if err != nil {
+		lp.app.SwitchToMainPage(fmt.Sprintf("Error getting tables: %v", err))
+		return
+	}
+
+	lp.tables = []string{""}
+	for _, row := range tableRows {
+		lp.tables = append(lp.tables, row[0])
+	}
+
+	// Update the table dropdown if exists
+	if tableItem := form.GetFormItemByLabel("Table"); tableItem != nil {
+		if tableDropdown, ok := tableItem.(*tview.DropDown); ok {
+			tableDropdown.SetOptions(lp.tables, func(table string, index int) {
+				lp.table = table
+				lp.updateFieldDropdowns(form)
+			})
+		}
+	}
+}
+
+// sourceExpr renders the panel's FROM source. A credential-less remote() or
+// remoteSecure() source gets the selected context's user and password
+// injected, so remote sources reuse the connection's stored login instead of
+// needing it typed inline (and later journaled with the query text).
+func (lp *LogPanel) sourceExpr() string {
+	table := lp.table
+	if chquery.IsTableFunction(table) && lp.app != nil && lp.app.selectedContext != nil {
+		table = chquery.InjectRemoteCredentials(table, lp.app.selectedContext.Username, lp.app.selectedContext.Password)
+	}
+	return chquery.TableExpr(lp.database, table)
+}
+
+func (lp *LogPanel) updateFieldDropdowns(form *tview.Form) {
+	if lp.table == "" || (lp.database == "" && !chquery.IsTableFunction(lp.table)) {
+		return
+	}
+
+	// Query ClickHouse for columns in selected table; system.columns only
+	// covers real tables, table function sources are described directly
+	query := "SELECT name, type FROM system.columns WHERE database=? AND table=?"
+	args := []interface{}{lp.database, lp.table}
+	if chquery.IsTableFunction(lp.table) {
+		query = "DESCRIBE TABLE " + lp.sourceExpr()
+		args = nil
+	}
+	fieldRows, err := lp.app.clickHouse.QueryStringsCached(lp.app.queryContext(), query, args...)
+	if err != nil {
+		lp.app.SwitchToMainPage(fmt.Sprintf("Error getting columns: %v", err))
+		return
+	}
+
+	var columns, timeMsColumns, timeColumns, dateColumns []string
+	lp.allFields = []string{} // Reset stored fields
+	lp.fieldTypes = make(map[string]string)
+	for _, row := range fieldRows {
+		// DESCRIBE returns extra columns after name and type, discard them
+		fieldName, fieldType := row[0], row[1]
+		// Store all field names and types
+		lp.allFields = append(lp.allFields, fieldName)
+		lp.fieldTypes[fieldName] = fieldType
+
+		if !strings.Contains(fieldType, "Date") && !strings.Contains(fieldType, "Array") && !strings.Contains(fieldType, "Tuple") && !strings.Contains(fieldType, "Map") {
+			columns = append(columns, fieldName)
+		}
+		if fieldType == "Date" || fieldType == "Date32" || strings.HasPrefix(fieldType, "Date(") || strings.HasPrefix(fieldType, "Date32(") || strings.HasPrefix(fieldType, "Nullable(Date)") || strings.HasPrefix(fieldType, "Nullable(Date(") || strings.HasPrefix(fieldType, "Nullable(Date32") {
+			dateColumns = append(dateColumns, fieldName)
+		}
+		if fieldType == "DateTime" || fieldType == "Nullable(DateTime)" || strings.HasPrefix(fieldType, "DateTime(") || strings.HasPrefix(fieldType, "Nullable(DateTime(") {
+			timeColumns = append(timeColumns, fieldName)
+		}
+		if fieldType == "DateTime64" || strings.HasPrefix(fieldType, "DateTime64(") || strings.HasPrefix(fieldType, "Nullable(DateTime64") {
+			timeMsColumns = append(timeMsColumns, fieldName)
+		}
+	}
+
+	lp.updateDropdownOptions(form, "Message Field", columns, func(field string, index int) {
+		lp.messageField = field
+	})
+	lp.updateDropdownOptions(form, "Time Field", timeColumns, func(field string, index int) {
+		lp.timeField = field
+	})
+	lp.updateDropdownOptions(form, "TimeMs Field (optional)", timeMsColumns, func(field string, index int) {
+		lp.timeMsField = field
+	})
+	lp.updateDropdownOptions(form, "Date Field (optional)", dateColumns, func(field string, index int) {
+		lp.dateField = field
+	})
+	lp.updateDropdownOptions(form, "Level Field (optional)", columns, func(field string, index int) {
+		lp.levelField = field
+	})
+}
+
+// updateDropdownOptions updates a dropdown's options and selects the current value if set
+func (lp *LogPanel) updateDropdownOptions(form *tview.Form, label string, options []string, selectedFunc func(option string, optionIndex int)) {
+	for i := 0; i < form.GetFormItemCount(); i++ {
+		item := form.GetFormItem(i)
+		if dropdown, ok := item.(*tview.DropDown); ok && dropdown.GetLabel() == label+": " {
+			dropdown.SetOptions(options, selectedFunc)
+
+			// Get current value for this field type
+			var currentValue string
+			switch label {
+			case "Message Field":
+				currentValue = lp.messageField
+			case "Time Field":
+				currentValue = lp.timeField
+			case "TimeMs Field (optional)":
+				currentValue = lp.timeMsField
+			case "Date Field (optional)":
+				currentValue = lp.dateField
+			case "Level Field (optional)":
+				currentValue = lp.levelField
+			}
+
+			// Select matching option if current value exists
+			for idx, opt := range options {
+				if opt == currentValue {
+					dropdown.SetCurrentOption(idx)
+					break
+				}
+			}
+			if currentValue == "" {
+				dropdown.SetCurrentOption(0)
+			}
+			break
+		}
+	}
+}
+
+func (lp *LogPanel) showLogExplorer() {
+	// Create main layout with 3 panels
+	// Store mainFlex in LogPanel struct for later access (e.g., resizing children)
+	lp.mainFlex = tview.NewFlex().SetDirection(tview.FlexRow)
+
+	// 1. AdHoc Filter Panel (1 line height)
+	lp.filterPanel = tview.NewFlex().SetDirection(tview.FlexRow)
+	lp.filterPanel.SetBorder(true).SetTitle("Filters").SetTitleAlign(tview.AlignLeft)
+
+	// Filter input components
+	// For Enum and LowCardinality(String) fields known values are offered as
+	// autocomplete suggestions in the value input
+	var valueSuggestions []string
+	filterField := tview.NewDropDown().
+		SetLabel("Field: ").
+		SetOptions(lp.getAvailableFilterFields(), func(field string, index int) {
+			valueSuggestions = nil
+			go func() {
+				suggestions := lp.fetchFilterValueSuggestions(field)
+				lp.app.tviewApp.QueueUpdateDraw(func() {
+					valueSuggestions = suggestions
+				})
+			}()
+		})
+	filterOp := tview.NewDropDown().
+		SetLabel("Operator: ").
+		SetOptions([]string{"=", "!=", ">", "<", ">=", "<=", "LIKE", "NOT LIKE", "ILIKE", "NOT ILIKE", "MATCH", "HAS TOKEN", "JSON =", "JSON !=", "IN", "NOT IN"}, nil)
+	filterValue := tview.NewInputField().
+		SetLabel("Value: ")
+	filterValue.SetAutocompleteFunc(func(currentText string) []string {
+		var matches []string
+		for _, suggestion := range valueSuggestions {
+			if currentText == "" || strings.Contains(strings.ToLower(suggestion), strings.ToLower(currentText)) {
+				matches = append(matches, suggestion)
+			}
+		}
+		return matches
+	})
+
+	addFilterBtn := tview.NewButton("Add Filter").
+		SetSelectedFunc(func() {
+			_, field := filterField.GetCurrentOption()
+			_, op := filterOp.GetCurrentOption()
+			value := filterValue.GetText()
+
+			// IN/NOT IN take a subquery as value, edited and validated separately
+			if (op == "IN" || op == "NOT IN") && field != "" {
+				lp.showSubqueryEditor(field, op, value)
+				return
+			}
+
+			if field != "" && op != "" && value != "" {
+				// Validate against the column type before adding the filter
+				if validateErr := lp.validateFilterValue(field, op, value); validateErr != nil {
+					lp.overview.SetText(fmt.Sprintf("[red]Invalid filter value: %v[-]", validateErr))
+					return
+				}
+				candidate := LogFilter{
+					Field:    field,
+					Operator: op,
+					Value:    value,
+				}
+				applyFilter := func() {
+					lp.filters = append(lp.filters, candidate)
+					lp.updateFilterDisplay(lp.filterPanel)
+					go lp.loadLogs()
+				}
+				if lp.previewSQL {
+					lp.previewFilterSQL(candidate, applyFilter)
+					return
+				}
+				applyFilter()
+			}
+		})
+
+	previewCheck := tview.NewCheckbox().
+		SetLabel("Preview SQL ").
+		SetChecked(lp.previewSQL).
+		SetChangedFunc(func(checked bool) {
+			lp.previewSQL = checked
+		})
+
+	filterFlex := tview.NewFlex().
+		AddItem(filterField, 0, 1, false).
+		AddItem(filterOp, 0, 1, false).
+		AddItem(filterValue, 0, 1, false).
+		AddItem(addFilterBtn, 10, 1, false).
+		AddItem(previewCheck, 16, 1, false)
+
+	// Ensure filterFlex (input row) is 1 row high, and does not take proportional space.
+	lp.filterPanel.AddItem(filterFlex, 1, 0, false)
+
+	// Add filterPanel to mainFlex. Height = 1 (input row) + num_filters + 2 (panel border).
+	// Proportion is 1, consistent with other elements.
+	initialFilterPanelHeight := 1 + len(lp.filters) + 2
+	lp.mainFlex.AddItem(lp.filterPanel, initialFilterPanelHeight, 1, false)
+
+	// 2. Overview Panel (20% height)
+	lp.overview = tview.NewTextView().SetDynamicColors(true)
+	lp.overview.SetBorder(true).SetTitle("Overview").SetTitleAlign(tview.AlignLeft)
+	// Clicking a level segment of the bar jumps the selection to the next
+	// entry of that level
+	lp.overview.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		if action == tview.MouseLeftClick {
+			x, _ := event.Position()
+			innerX, _, _, _ := lp.overview.GetInnerRect()
+			lp.selectNextEntryOfLevel(lp.overviewLevelAt(x - innerX))
+		}
+		return action, event
+	})
+	lp.mainFlex.AddItem(lp.overview, 3, 1, false)
+
+	// 3. Log Details Panel (60% height)
+	// Apply a previously saved level-value mapping for this table
+	lp.restoreLevelMapping()
+
+	// Create filtered table with Time and Message columns
+	lp.logDetails = widgets.NewFilteredTable()
+	lp.logDetails.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	lp.logDetails.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(lp.logTableTitle())
+
+	// Setup headers
+	lp.logDetails.SetupHeaders([]string{"Time", "Message"})
+
+	// Handle keyboard navigation by combining table's input capture with filtered table's
+	existingHandler := lp.logDetails.GetInputCapture(lp.app.tviewApp, lp.app.pages)
+	lp.logDetails.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// Let filtered table handle its own keybindings first (like / for filter)
+		if result := existingHandler(event); result == nil {
+			return nil // Event was handled by filtered table
+		}
+
+		rowNumber, _ := lp.logDetails.Table.GetSelection()
+		if event.Key() == tcell.KeyEnter {
+			if rowNumber > 0 && rowNumber <= lp.totalRows {
+				lp.showLogDetailsModal(rowNumber)
+			}
+		} else if event.Key() == tcell.KeyPgUp && event.Modifiers()&tcell.ModCtrl != 0 {
+			go lp.loadMoreLogs(false) // Load older logs
+		} else if event.Key() == tcell.KeyPgDn && event.Modifiers()&tcell.ModCtrl != 0 {
+			go lp.loadMoreLogs(true) // Load newer logs
+		} else if event.Key() == tcell.KeyCtrlR {
+			go lp.loadLogs() // Explicit (re)load, used in safe mode
+		} else if event.Key() == tcell.KeyCtrlB {
+			lp.returnFromPivot() // Back to the panel the pivot was opened from
+		} else if event.Key() == tcell.KeyCtrlE {
+			lp.showErrorCodeDashboard()
+		} else if event.Key() == tcell.KeyCtrlY {
+			lp.showLevelComparison()
+		} else if event.Key() == tcell.KeyCtrlL {
+			lp.showLevelMappingEditor()
+		} else if event.Key() == tcell.KeyCtrlT {
+			lp.toggleRangeTotals()
+		} else if event.Key() == tcell.KeyCtrlG {
+			// Ship everything currently loaded to the configured OTLP collector
+			lp.exportEntriesToOTLP(lp.loadedEntries(), func(message string) {
+				lp.overview.SetText(message)
+			})
+		}
+
+		return event
+	})
+
+	// Mouse is enabled app-wide, a single click already selects the row;
+	// double-click opens the details modal like Enter does
+	lp.logDetails.Table.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		if action == tview.MouseLeftDoubleClick {
+			rowNumber, _ := lp.logDetails.Table.GetSelection()
+			if rowNumber > 0 && rowNumber <= lp.totalRows {
+				lp.showLogDetailsModal(rowNumber)
+			}
+		}
+		return action, event
+	})
+
+	lp.mainFlex.AddItem(lp.logDetails.Table, 0, 1, false)
+
+	// Set up tab navigation between all components
+	lp.setupTabNavigation(filterField, filterOp, filterValue, addFilterBtn, previewCheck)
+
+	lp.app.pages.AddPage("logExplorer", lp.mainFlex, true, true)
+	lp.app.pages.SwitchToPage("logExplorer")
+
+	// Execute initial query, in safe mode wait for explicit Ctrl+R
+	if lp.app.safeMode {
+		lp.overview.SetText("Safe mode: press Ctrl+R to load logs")
+	} else {
+		go lp.loadLogs()
+	}
+}
+
+// logTableTitle renders the log table title; once the parallel count()
+// finished it also shows how much of the matching rows the loaded page covers
+func (lp *LogPanel) logTableTitle() string {
+	matchInfo := ""
+	// matchSeq is 0 until the first load kicked off a count query
+	if lp.matchSeq > 0 && lp.matchingRows >= 0 {
+		matchInfo = fmt.Sprintf(" | loaded %d of %d matching rows", lp.totalRows, lp.matchingRows)
+	}
+	return fmt.Sprintf("Log Entries [yellow](Ctrl+PageUp/Ctlr+PageDown to load more, Ctrl+E: error codes, Ctrl+Y: compare 24h)[-] | From: %s To: %s%s",
+		utils.FormatTimestamp(lp.firstEntryTime),
+		utils.FormatTimestamp(lp.lastEntryTime),
+		matchInfo)
+}
+
+// loadMatchingCount counts the rows matching the active filters over the whole
+// selected range in parallel with the page load and refreshes the table title;
+// the result is dropped when seq no longer matches because filters or the
+// range changed while the count ran
+func (lp *LogPanel) loadMatchingCount(seq int) {
+	timeCondition := fmt.Sprintf("%s >= ? AND %s <= ?", chquery.QuoteID(lp.timeField), chquery.QuoteID(lp.timeField))
+	whereClause, args := lp.buildWhereClause(timeCondition, []interface{}{lp.app.fromTime, lp.app.toTime})
+
+	query := fmt.Sprintf("SELECT count() FROM %s WHERE %s", lp.sourceExpr(), whereClause)
+
+	var cnt uint64
+	if err := lp.app.clickHouse.QueryRowContext(lp.app.queryContext(), query, args...).Scan(&cnt); err != nil {
+		log.Error().Err(err).Msg("can't count matching log rows")
+		return
+	}
+
+	lp.app.tviewApp.QueueUpdateDraw(func() {
+		if seq != lp.matchSeq {
+			return
+		}
+		lp.matchingRows = int64(cnt)
+		lp.logDetails.Table.SetTitle(lp.logTableTitle())
+	})
+}
+
+func (lp *LogPanel) loadLogs() {
+	if lp.database == "" || lp.table == "" || lp.messageField == "" || lp.timeField == "" {
+		return
+	}
+
+	lp.overview.SetText(fmt.Sprintf("Loading %d log rows from `%s`.`%s`...", lp.windowSize, lp.database, lp.table))
+
+	// Count the full match in parallel so the title can show page coverage
+	lp.matchSeq++
+	lp.matchingRows = -1
+	go lp.loadMatchingCount(lp.matchSeq)
+
+	// Build WHERE clause with filters
+	timeCondition := fmt.Sprintf("%s >= ?", lp.timeField)
+	whereClause, queryArgs := lp.buildWhereClause(timeCondition, []interface{}{lp.app.fromTime})
+
+	// Build query
+	logsQuery := lp.buildQuery(whereClause, lp.timeField)
+	queryArgs = append(lp.repeatArgsPerSource(queryArgs), lp.windowSize)
+
+	logComment := newPanelLogComment("logs")
+	rows, err := lp.app.clickHouse.QueryWithLogComment(lp.app.queryContext(), logComment, logsQuery, queryArgs...)
+	if err != nil {
+		lp.app.tviewApp.QueueUpdateDraw(func() {
+			lp.app.SwitchToMainPage(fmt.Sprintf("loadLogs Query failed: %v", err))
+		})
+		return
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close loadLogs rows")
+		}
+	}()
+
+	// Stream directly to table while storing full entry data
+	lp.streamRowsToTable(rows, true, false)
+
+	// Show what this panel query cost the server once query_log is flushed
+	go lp.app.showPanelQueryCost(logComment, func(cost string) {
+		lp.overview.SetText(lp.overview.GetText(false) + " | [yellow]" + cost + "[-]")
+	})
+}
+
+func (lp *LogPanel) getAvailableFilterFields() []string {
+	// Return stored fields if we have them
+	if len(lp.allFields) > 0 {
+		return lp.allFields
+	}
+	// Fallback to basic fields if no fields stored yet
+	return []string{lp.messageField, lp.timeField}
+}
+
+func (lp *LogPanel) updateFilterDisplay(panel *tview.Flex) {
+	// Clear existing filter displays (buttons), keeping the first item (filterFlex)
+	// The first item (index 0) is filterFlex, which should not be removed.
+	for panel.GetItemCount() > 1 {
+		panel.RemoveItem(panel.GetItem(1)) // Repeatedly remove the item at index 1
+	}
+
+	// Add current filters
+	for _, filter := range lp.filters {
+		filterText := fmt.Sprintf("%s %s %s", filter.Field, filter.Operator, filter.Value)
+		// Capture the filter value to avoid closure issues
+		currentFilter := filter
+		filterBtn := tview.NewButton(filterText).
+			SetSelectedFunc(func() {
+				// Remove this specific filter by value comparison
+				for i, f := range lp.filters {
+					if f.Field == currentFilter.Field && f.Operator == currentFilter.Operator && f.Value == currentFilter.Value {
+						lp.filters = append(lp.filters[:i], lp.filters[i+1:]...)
+						break
+					}
+				}
+				lp.updateFilterDisplay(panel)
+				go lp.loadLogs()
+			}).
+			SetStyle(tcell.StyleDefault.Background(tcell.ColorDarkBlue)).
+			SetActivatedStyle(tcell.StyleDefault.Background(tcell.ColorRed))
+
+		// Add tab navigation for filter buttons
+		filterBtn.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			if event.Key() == tcell.KeyTab {
+				lp.app.tviewApp.SetFocus(lp.logDetails.Table)
+				return nil
+			} else if event.Key() == tcell.KeyBacktab {
+				// Focus back to the filter input area
+				if filterFlex := panel.GetItem(0); filterFlex != nil {
+					if flex, ok := filterFlex.(*tview.Flex); ok && flex.GetItemCount() > 0 {
+						lp.app.tviewApp.SetFocus(flex.GetItem(0))
+					}
+				}
+				return nil
+			}
+			return event
+		})
+
+		// Ensure filterBtn is 1 row high, and does not take proportional space.
+		panel.AddItem(filterBtn, 1, 0, false)
+		lp.app.tviewApp.SetFocus(filterBtn)
+	}
+
+	// Dynamically adjust the height of the filterPanel in mainFlex
+	// Height = 1 (for filterFlex row) + number of filters + 2 (for filterPanel's border)
+	newHeight := 1 + len(lp.filters) + 2
+	if lp.mainFlex != nil && lp.filterPanel != nil {
+		// Use proportion 1, consistent with how it was added initially and with other items.
+		lp.mainFlex.ResizeItem(lp.filterPanel, newHeight, 1)
+	}
+}
+
+func (lp *LogPanel) updateOverviewWithStats(levelCounts map[string]int, totalItems int, wholeRange bool) {
+	lp.overviewSegments = nil
+	if totalItems == 0 {
+		lp.overview.SetText("No log entries to display")
+		return
+	}
+
+	if lp.levelField == "" {
+		lp.overview.SetText(fmt.Sprintf("Total log entries: %d (no level field selected for breakdown)", totalItems))
+		return
+	}
+
+	// Get all actual levels found in data, sorted by count (descending)
+	type levelCount struct {
+		level string
+		count int
+	}
+	var sortedLevels []levelCount
+	for level, count := range levelCounts {
+		sortedLevels = append(sortedLevels, levelCount{level, count})
+	}
+
+	// Sort by count descending
+	for i := 0; i < len(sortedLevels)-1; i++ {
+		for j := i + 1; j < len(sortedLevels); j++ {
+			if sortedLevels[j].count > sortedLevels[i].count {
+				sortedLevels[i], sortedLevels[j] = sortedLevels[j], sortedLevels[i]
+			}
+		}
+	}
+
+	// Get available width from the overview TextView
+	_, _, viewWidth, _ := lp.overview.GetInnerRect()
+	if viewWidth <= 0 {
+		viewWidth = 80 // fallback width
+	}
+
+	// Reserve space for "Total: XXXX | " prefix and some padding
+	prefixText := fmt.Sprintf("Total: %d | ", totalItems)
+	if wholeRange {
+		prefixText = fmt.Sprintf("Range: %d | ", totalItems)
+	}
+	availableWidth := viewWidth - len(prefixText) - 5 // 5 chars padding
+
+	if availableWidth < 20 {
+		availableWidth = 20 // minimum bar width
+	}
+
+	// Build the bar with embedded legend
+	var builder strings.Builder
+	builder.WriteString(prefixText)
+
+	// Create segments with embedded labels
+	currentPos := 0
+	for _, lc := range sortedLevels {
+		if lc.count == 0 {
+			continue
+		}
+
+		proportion := float64(lc.count) / float64(totalItems)
+		segmentWidth := int(proportion * float64(availableWidth))
+		if segmentWidth == 0 && lc.count > 0 {
+			segmentWidth = 1 // Ensure at least 1 character for non-zero counts
+		}
+
+		var bgColor string
+		switch lc.level {
+		case "error", "exception", "fatal", "critical":
+			bgColor = "red"
+		case "warning", "warn", "debug", "trace":
+			bgColor = "yellow"
+		case "info", "information":
+			bgColor = "green"
+		case "unknown":
+			bgColor = "gray"
+		default:
+			bgColor = "cyan" // For any other levels
+		}
+
+		// Create label text for this segment
+		labelText := fmt.Sprintf("%s:%d", lc.level, lc.count)
+
+		// If segment is wide enough to fit the label, embed it
+		if segmentWidth >= len(labelText) {
+			// Calculate padding to center the label
+			padding := (segmentWidth - len(labelText)) / 2
+			leftPad := strings.Repeat(" ", padding)
+			rightPad := strings.Repeat(" ", segmentWidth-padding-len(labelText))
+			builder.WriteString(fmt.Sprintf("[black:%s]%s%s%s[-]", bgColor, leftPad, labelText, rightPad))
+		} else {
+			// Segment too small for label, just fill with blocks
+			builder.WriteString(fmt.Sprintf("[black:%s]%s[-]", bgColor, strings.Repeat(" ", segmentWidth)))
+		}
+
+		lp.overviewSegments = append(lp.overviewSegments, overviewSegment{
+			level: lc.level,
+			start: len(prefixText) + currentPos,
+			end:   len(prefixText) + currentPos + segmentWidth,
+		})
+		currentPos += segmentWidth
+	}
+
+	// Fill remaining space if any
+	if currentPos < availableWidth {
+		builder.WriteString(strings.Repeat(" ", availableWidth-currentPos))
+	}
+
+	lp.overview.SetText(builder.String())
+}
+
+// overviewLevelAt returns the level of the overview bar segment covering
+// column x of the overview's inner rect, "" when x hits no segment
+func (lp *LogPanel) overviewLevelAt(x int) string {
+	for _, segment := range lp.overviewSegments {
+		if x >= segment.start && x < segment.end {
+			return segment.level
+		}
+	}
+	return ""
+}
+
+// selectNextEntryOfLevel moves the log table selection to the next loaded
+// entry of the given canonical level, wrapping around the page
+func (lp *LogPanel) selectNextEntryOfLevel(level string) {
+	if level == "" || lp.totalRows == 0 {
+		return
+	}
+	selected, _ := lp.logDetails.Table.GetSelection()
+	for offset := 1; offset <= lp.totalRows; offset++ {
+		row := (selected-1+offset)%lp.totalRows + 1
+		cell := lp.logDetails.Table.GetCell(row, 0)
+		if cell == nil || cell.Reference == nil {
+			continue
+		}
+		entry, ok := cell.Reference.(LogEntry)
+		if !ok {
+			continue
+		}
+		entryLevel := "unknown"
+		if entry.Level != "" {
+			entryLevel = strings.ToLower(lp.canonicalLevel(entry.Level))
+		}
+		if entryLevel == level {
+			lp.logDetails.Table.Select(row, 0)
+			lp.app.tviewApp.SetFocus(lp.logDetails.Table)
+			return
+		}
+	}
+}
+
+func (lp *LogPanel) loadMoreLogs(newer bool) {
+	if lp.totalRows == 0 {
+		lp.app.tviewApp.QueueUpdateDraw(func() {
+			lp.overview.SetText("No logs loaded yet")
+		})
+		return
+	}
+	if newer && lp.matchingRows >= 0 && int64(lp.totalRows) >= lp.matchingRows {
+		lp.app.tviewApp.QueueUpdateDraw(func() {
+			lp.overview.SetText(fmt.Sprintf("All %d matching rows are loaded", lp.matchingRows))
+		})
+		return
+	}
+	lp.app.tviewApp.QueueUpdateDraw(func() {
+		lp.overview.SetText(fmt.Sprintf(ternary(newer, "Loading next %d rows...", "Loading previous %d rows..."), lp.windowSize))
+	})
+
+	// Keyset pagination: seek on the most precise timestamp at the page edge
+	// and order away from the loaded page. The OFFSET only skips rows already
+	// loaded at the edge timestamp itself, so paging cost stays flat no matter
+	// how deep the page is, and rows inserted meanwhile can't shift entries
+	// between pages.
+	preciseField := lp.timeField
+	if lp.timeMsField != "" {
+		preciseField = lp.timeMsField
+	}
+
+	var whereClause, orderBy string
+	var queryArgs []interface{}
+	var cursor logCursor
+
+	if newer {
+		cursor = lp.newestCursor
+		if cursor.ts.IsZero() {
+			cursor = logCursor{ts: lp.lastEntryTime}
+		}
+		timeConditionStr := fmt.Sprintf("%s >= ? AND %s <= ?", chquery.QuoteID(preciseField), chquery.QuoteID(lp.timeField))
+		whereClause, queryArgs = lp.buildWhereClause(timeConditionStr, []interface{}{cursor.ts, lp.app.toTime})
+		orderBy = chquery.QuoteID(preciseField) + " ASC"
+	} else {
+		cursor = lp.oldestCursor
+		if cursor.ts.IsZero() {
+			cursor = logCursor{ts: lp.firstEntryTime}
+		}
+		timeConditionStr := fmt.Sprintf("%s <= ? AND %s >= ?", chquery.QuoteID(preciseField), chquery.QuoteID(lp.timeField))
+		whereClause, queryArgs = lp.buildWhereClause(timeConditionStr, []interface{}{cursor.ts, lp.app.fromTime})
+		orderBy = chquery.QuoteID(preciseField) + " DESC"
+	}
+
+	query := lp.buildQuery(whereClause, orderBy) + " OFFSET ?"
+	queryArgs = append(lp.repeatArgsPerSource(queryArgs), lp.windowSize, cursor.ties)
+
+	rows, err := lp.app.clickHouse.QueryContext(lp.app.queryContext(), query, queryArgs...)
+	if err != nil {
+		lp.app.tviewApp.QueueUpdateDraw(func() {
+			lp.overview.SetText(fmt.Sprintf("Error loading more logs: %v", err))
+		})
+		return
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close loadMoreLogs rows")
+		}
+	}()
+
+	// Stream additional rows (don't clear table)
+	lp.streamRowsToTable(rows, false, !newer)
+}
+
+func (lp *LogPanel) showLogDetailsModal(rowIndex int) {
+	if rowIndex <= 0 || rowIndex > lp.totalRows {
+		return
+	}
+
+	// Get the stored LogEntry from the row's reference
+	cell := lp.logDetails.Table.GetCell(rowIndex, 0)
+	if cell == nil || cell.Reference == nil {
+		return
+	}
+
+	if entry, ok := cell.Reference.(LogEntry); ok {
+		lp.showLogDetailsModalWithEntry(entry)
+	}
+}
+
+func (lp *LogPanel) showLogDetailsModalWithEntry(entry LogEntry) {
+	// Create a flex layout for the details window
+	detailsFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+
+	// Header section with time and level info
+	headerText := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true)
+	headerText.SetBorder(true).SetTitle("Log Entry Info")
+
+	// Build header content with standard fields
+	var headerBuilder strings.Builder
+	if !entry.Time.IsZero() {
+		headerBuilder.WriteString(fmt.Sprintf("[yellow]%s:[-] %s\n", lp.timeField, utils.FormatTimestamp(entry.Time)))
+	}
+	if !entry.TimeMs.IsZero() {
+		headerBuilder.WriteString(fmt.Sprintf("[yellow]%s:[-] %s\n", lp.timeMsField, entry.TimeMs))
+	}
+	if entry.Date != "" {
+		headerBuilder.WriteString(fmt.Sprintf("[yellow]%s:[-] %s\n", lp.dateField, entry.Date))
+	}
+	if entry.Level != "" {
+		headerBuilder.WriteString(fmt.Sprintf("[yellow]%s:[-] %s%s[-]\n",
+			lp.levelField, levelColorTag(lp.canonicalLevel(entry.Level)), entry.Level))
+	}
+	headerText.SetText(headerBuilder.String())
+
+	// Create a list for additional fields
+	fieldsList := tview.NewList()
+	fieldsList.SetBorder(true).
+		SetTitle("Additional Fields (press Enter to filter)")
+	fieldsList.ShowSecondaryText(false)
+	fieldsList.SetSelectedBackgroundColor(tcell.ColorBlue)
+
+	// Store field data for filtering
+	var fieldData []struct {
+		field string
+		value string
+	}
+
+	// Add all additional fields to the list
+	if len(entry.AllFields) > 0 {
+		// Sort field names for consistent display
+		fields := make([]string, 0, len(entry.AllFields))
+		for field := range entry.AllFields {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			value := entry.AllFields[field]
+			var valueStr string
+
+			switch v := value.(type) {
+			case []byte:
+				valueStr = string(v)
+			case time.Time:
+				valueStr = utils.FormatTimestamp(v)
+			case nil:
+				valueStr = "NULL"
+			default:
+				valueStr = fmt.Sprintf("%v", v)
+			}
+
+			// Store field data
+			fieldData = append(fieldData, struct {
+				field string
+				value string
+			}{field, valueStr})
+
+			// Add to list with yellow field name and normal background
+			listText := fmt.Sprintf("[yellow]%s:[-] %s", field, valueStr)
+			fieldsList.AddItem(listText, "", 0, nil)
+		}
+	}
+
+	// Set up list selection handler
+	fieldsList.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		if index >= 0 && index < len(fieldData) {
+			selectedField := fieldData[index]
+
+			// Add this field/value pair as a filter
+			lp.filters = append(lp.filters, LogFilter{
+				Field:    selectedField.field,
+				Operator: "=",
+				Value:    selectedField.value,
+			})
+			lp.updateFilterDisplay(lp.filterPanel)
+			lp.app.pages.RemovePage("logDetails")
+			lp.app.pages.SwitchToPage("logExplorer")
+			go lp.loadLogs()
+		}
+	})
+
+	formPrimitive := fieldsList // Store as primitive for navigation
+
+	// Message section with scrolling
+	messageText := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWordWrap(true).
+		SetScrollable(true)
+	messageText.SetBorder(true).SetTitle(fmt.Sprintf("Message (%s)", lp.messageField))
+	messageText.SetText(entry.Message)
+
+	// The list view may contain only a truncated message, fetch the full value lazily
+	if lp.maxMessageLength > 0 && len(entry.Message) >= lp.maxMessageLength {
+		go func() {
+			fullMessage := lp.fetchFullMessage(entry)
+			if fullMessage != "" {
+				lp.app.tviewApp.QueueUpdateDraw(func() {
+					messageText.SetText(fullMessage)
+				})
+			}
+		}()
+	}
+
+	// Instructions
+	instructionsText := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("[yellow]Navigation:[-] Tab/Shift+Tab to move, Enter to filter, Ctrl+O to show context, Ctrl+P to pivot on field, Ctrl+Q to explain by query_id, Ctrl+G to ship to OTLP, Ctrl+J to expand JSON, Ctrl+Y to copy message, Esc to close")
+	instructionsText.SetTextAlign(tview.AlignCenter)
+
+	// Add components to flex layout
+	detailsFlex.AddItem(headerText, 0, 1, false)       // Header takes minimum space needed
+	detailsFlex.AddItem(fieldsList, 0, 2, false)       // Fields list
+	detailsFlex.AddItem(messageText, 0, 6, true)       // Message takes most space
+	detailsFlex.AddItem(instructionsText, 1, 0, false) // Instructions take 1 line
+
+	// Setup tab navigation between form and message
+	copyMessage := func() {
+		text := messageText.GetText(true)
+		if text == "" {
+			text = entry.Message
+		}
+		instructionsText.SetText(fmt.Sprintf("[yellow]%s[-]", lp.app.copyToClipboard(text)))
+	}
+
+	// Ctrl+Q jumps from a log line carrying a query_id into the explain flow
+	// for that query's normalized hash
+	openExplain := func() {
+		queryID := entryQueryID(entry)
+		if queryID == "" {
+			instructionsText.SetText("[red]This entry has no query_id field[-]")
+			return
+		}
+		lp.app.pages.RemovePage("logDetails")
+		lp.app.showExplainForQueryID(queryID)
+	}
+
+	// Ctrl+G ships this single entry to the OTLP collector of the context
+	shipToOTLP := func() {
+		lp.exportEntriesToOTLP([]LogEntry{entry}, func(message string) {
+			instructionsText.SetText(message)
+		})
+	}
+
+	// Ctrl+J expands JSON carried by the message (or the selected field) into
+	// a browsable tree of nested keys
+	expandJSON := func() {
+		field := lp.messageField
+		text := messageText.GetText(true)
+		if index := fieldsList.GetCurrentItem(); lp.app.tviewApp.GetFocus() == fieldsList &&
+			index >= 0 && index < len(fieldData) {
+			if _, found := extractJSON(fieldData[index].value); found {
+				field = fieldData[index].field
+				text = fieldData[index].value
+			}
+		}
+		lp.showJSONTree(field, text)
+	}
+
+	formPrimitive.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyCtrlO {
+			lp.showLogContext(entry)
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlQ {
+			openExplain()
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlG {
+			shipToOTLP()
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlJ {
+			expandJSON()
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlY {
+			copyMessage()
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlP {
+			// Pivot: open a new explorer filtered to the selected field=value
+			if index := fieldsList.GetCurrentItem(); index >= 0 && index < len(fieldData) {
+				lp.pivotToField(fieldData[index].field, fieldData[index].value)
+			}
+			return nil
+		}
+		if event.Key() == tcell.KeyTab {
+			lp.app.tviewApp.SetFocus(messageText)
+			return nil
+		} else if event.Key() == tcell.KeyEscape {
+			lp.app.pages.RemovePage("logDetails")
+			lp.app.pages.SwitchToPage("logExplorer")
+			lp.app.tviewApp.SetFocus(lp.logDetails.Table)
+			return nil
+		}
+		return event
+	})
+
+	messageText.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyCtrlO {
+			lp.showLogContext(entry)
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlQ {
+			openExplain()
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlG {
+			shipToOTLP()
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlJ {
+			expandJSON()
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlY {
+			copyMessage()
+			return nil
+		}
+		if event.Key() == tcell.KeyTab || event.Key() == tcell.KeyBacktab {
+			lp.app.tviewApp.SetFocus(formPrimitive)
+			return nil
+		} else if event.Key() == tcell.KeyEscape {
+			lp.app.pages.RemovePage("logDetails")
+			lp.app.pages.SwitchToPage("logExplorer")
+			lp.app.tviewApp.SetFocus(lp.logDetails.Table)
+			return nil
+		}
+		return event
+	})
+
+	// Set initial focus to fields list
+	lp.app.tviewApp.SetFocus(fieldsList)
+
+	lp.app.pages.AddPage("logDetails", detailsFlex, true, true)
+	lp.app.pages.SwitchToPage("logDetails")
+}
+
+// fetchFullMessage loads the untruncated message for a single entry, matching
+// it by the most precise timestamp available and truncated prefix; TieBreak
+// picks the exact row when several entries share both
+func (lp *LogPanel) fetchFullMessage(entry LogEntry) string {
+	timeCondition := fmt.Sprintf("%s = ?", chquery.QuoteID(lp.timeField))
+	args := []interface{}{entry.Time, entry.Message}
+	if lp.timeMsField != "" && !entry.TimeMs.IsZero() {
+		timeCondition = fmt.Sprintf("%s = parseDateTime64BestEffort('%s')",
+			chquery.QuoteID(lp.timeMsField), entry.TimeMs.Format("2006-01-02 15:04:05.000000000 MST"))
+		args = []interface{}{entry.Message}
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s AND startsWith(%s, ?) LIMIT 1 OFFSET %d",
+		chquery.QuoteID(lp.messageField), lp.sourceExpr(),
+		timeCondition, chquery.QuoteID(lp.messageField), entry.TieBreak)
+	var fullMessage string
+	row := lp.app.clickHouse.QueryRowContext(lp.app.queryContext(), query, args...)
+	if row == nil {
+		return ""
+	}
+	if err := row.Scan(&fullMessage); err != nil {
+		log.Error().Err(err).Msg("can't fetch full log message")
+		return ""
+	}
+	return fullMessage
+}
+
+func (lp *LogPanel) getSelectedFields() []string {
+	fields := []string{lp.messageField, lp.timeField}
+	if lp.timeMsField != "" {
+		fields = append(fields, lp.timeMsField)
+	}
+	if lp.dateField != "" {
+		fields = append(fields, lp.dateField)
+	}
+	if lp.levelField != "" {
+		fields = append(fields, lp.levelField)
+	}
+	return fields
+}
+
+func (lp *LogPanel) buildWhereClause(timeCondition string, args []interface{}) (string, []interface{}) {
+	whereConditions := make([]string, 0)
+	queryArgs := args
+
+	// Optimize time filtering by using dateField if available
+	if len(args) > 0 && lp.dateField != "" {
+		if len(args) >= 1 {
+			if t, ok := args[0].(time.Time); ok {
+				whereConditions = append(whereConditions, fmt.Sprintf("%s >= '%s'", chquery.QuoteID(lp.dateField), t.Format("2006-01-02")))
+			}
+		}
+		if len(args) >= 2 {
+			if t, ok := args[1].(time.Time); ok {
+				whereConditions = append(whereConditions, fmt.Sprintf("%s <= '%s'", chquery.QuoteID(lp.dateField), t.Format("2006-01-02")))
+			}
+		}
+	}
+	// Precise time filtering by using dateField if available
+	if len(args) > 0 && lp.timeMsField != "" {
+		if len(args) >= 1 {
+			if t, ok := args[0].(time.Time); ok {
+				whereConditions = append(whereConditions, fmt.Sprintf("%s >= parseDateTime64BestEffort('%s')", chquery.QuoteID(lp.timeMsField), t.Format("2006-01-02 15:04:05.000 MST")))
+			}
+		}
+		if len(args) >= 2 {
+			if t, ok := args[1].(time.Time); ok {
+				whereConditions = append(whereConditions, fmt.Sprintf("%s <= parseDateTime64BestEffort('%s')", chquery.QuoteID(lp.timeMsField), t.Format("2006-01-02 15:04:05.000 MST")))
+			}
+		}
+	}
+	// Add the main time condition
+	whereConditions = append(whereConditions, timeCondition)
+
+	// Add filter conditions
+	for _, filter := range lp.filters {
+		if filter.IsSubquery {
+			whereConditions = append(whereConditions, fmt.Sprintf("%s %s (%s)", chquery.QuoteID(filter.Field), filter.Operator, filter.Value))
+			continue
+		}
+		// Function-style operators for regex and full-text token search
+		switch filter.Operator {
+		case "MATCH":
+			whereConditions = append(whereConditions, fmt.Sprintf("match(%s, ?)", chquery.QuoteID(filter.Field)))
+		case "HAS TOKEN":
+			whereConditions = append(whereConditions, fmt.Sprintf("hasToken(%s, ?)", chquery.QuoteID(filter.Field)))
+		case "JSON =", "JSON !=":
+			// Value is "<path>=<expected>", the path addresses a nested key
+			// inside JSON carried by the field, e.g. from the details JSON tree
+			path, expected, _ := strings.Cut(filter.Value, "=")
+			whereConditions = append(whereConditions, fmt.Sprintf("JSON_VALUE(%s, '$.%s') %s ?",
+				chquery.QuoteID(filter.Field), chquery.EscapeString(path), strings.TrimPrefix(filter.Operator, "JSON ")))
+			queryArgs = append(queryArgs, expected)
+			continue
+		default:
+			whereConditions = append(whereConditions, fmt.Sprintf("%s %s ?", chquery.QuoteID(filter.Field), filter.Operator))
+		}
+		queryArgs = append(queryArgs, filter.Value)
+	}
+
+	return strings.Join(whereConditions, " AND "), queryArgs
+}
+
+func (lp *LogPanel) buildQuery(whereClause, orderBy string) string {
+	if len(lp.extraSources) > 0 {
+		return lp.buildMergedQuery(whereClause, orderBy)
+	}
+	// Truncate huge message payloads for the list view, details fetch the full value lazily
+	selectClause := "*"
+	if lp.maxMessageLength > 0 && lp.messageField != "" {
+		selectClause = fmt.Sprintf("* REPLACE (substring(%s, 1, %d) AS %s)", chquery.QuoteID(lp.messageField), lp.maxMessageLength, chquery.QuoteID(lp.messageField))
+	}
+	return fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE %s
+		ORDER BY %s
+		LIMIT ?`,
+		selectClause,
+		lp.sourceExpr(),
+		whereClause,
+		orderBy)
+}
+
+func (lp *LogPanel) streamRowsToTable(rows *sql.Rows, clearFirst bool, insertAtTop bool) {
+	// Calculate dynamic batch size based on windowSize
+	batchSize := lp.windowSize / 10
+	if batchSize < 100 {
+		batchSize = 100 // Minimum batch size
+	}
+
+	if clearFirst {
+		lp.rowSeq = 0
+		lp.tieCounters = make(map[string]int)
+		lp.oldestCursor = logCursor{}
+		lp.newestCursor = logCursor{}
+	} else if lp.tieCounters == nil {
+		lp.tieCounters = make(map[string]int)
+	}
+
+	lp.app.tviewApp.QueueUpdateDraw(func() {
+		if clearFirst {
+			lp.logDetails.Table.Clear()
+			// Re-add headers
+			lp.logDetails.SetupHeaders([]string{"Time", "Message"})
+			lp.totalRows = 0
+		}
+	})
+
+	colTypes, _ := rows.ColumnTypes()
+	scanArgs := make([]interface{}, len(colTypes))
+
+	// For overview statistics - collect from all displayed rows, not just new batch
+	levelCounts := make(map[string]int)
+
+	// If not clearing first, collect existing level counts from displayed rows
+	if !clearFirst {
+		for r := 1; r <= lp.totalRows; r++ {
+			if cell := lp.logDetails.Table.GetCell(r, 0); cell != nil && cell.Reference != nil {
+				if entry, ok := cell.Reference.(LogEntry); ok && lp.levelField != "" {
+					if entry.Level != "" {
+						levelCounts[strings.ToLower(lp.canonicalLevel(entry.Level))]++
+					} else {
+						levelCounts["unknown"]++
+					}
+				}
+			}
+		}
+	}
+
+	rowIndex := lp.totalRows
+	var batch []LogEntry
+
+	for rows.Next() {
+		var entry LogEntry
+
+		// Initialize scan args and field storage
+		entry.AllFields = make(map[string]interface{})
+		fieldValues := make([]interface{}, len(colTypes))
+		for i, col := range colTypes {
+			fieldName := col.Name()
+			switch fieldName {
+			case lp.timeField:
+				scanArgs[i] = &entry.Time
+			case lp.timeMsField:
+				scanArgs[i] = &entry.TimeMs
+			case lp.dateField:
+				scanArgs[i] = &entry.Date
+			case lp.messageField:
+				scanArgs[i] = &entry.Message
+			case lp.levelField:
+				scanArgs[i] = &entry.Level
+			default:
+				// Store all other fields in AllFields map
+				var val interface{}
+				fieldValues[i] = &val
+				scanArgs[i] = fieldValues[i]
+			}
+		}
+
+		if err := rows.Scan(scanArgs...); err != nil {
+			log.Error().Err(err).Send()
+			continue
+		}
+
+		// Store all additional fields
+		for i, col := range colTypes {
+			fieldName := col.Name()
+			switch fieldName {
+			case lp.timeField, lp.timeMsField, lp.dateField, lp.messageField, lp.levelField:
+				// Skip fields we already handle specially
+			default:
+				if fieldValues[i] != nil {
+					val := *fieldValues[i].(*interface{})
+					entry.AllFields[fieldName] = val
+				}
+			}
+		}
+
+		// Assign the synthetic row identity before the entry is handed out
+		lp.rowSeq++
+		entry.RowID = lp.rowSeq
+		key := lp.tieKey(entry)
+		entry.TieBreak = lp.tieCounters[key]
+		lp.tieCounters[key]++
+
+		// Advance the keyset cursors on the entry's most precise timestamp;
+		// ties counts loaded rows sharing the edge timestamp so the next
+		// page skips exactly those
+		precise := entry.Time
+		if !entry.TimeMs.IsZero() {
+			precise = entry.TimeMs
+		}
+		if !precise.IsZero() {
+			if lp.oldestCursor.ts.IsZero() || precise.Before(lp.oldestCursor.ts) {
+				lp.oldestCursor = logCursor{ts: precise, ties: 1}
+			} else if precise.Equal(lp.oldestCursor.ts) {
+				lp.oldestCursor.ties++
+			}
+			if lp.newestCursor.ts.IsZero() || precise.After(lp.newestCursor.ts) {
+				lp.newestCursor = logCursor{ts: precise, ties: 1}
+			} else if precise.Equal(lp.newestCursor.ts) {
+				lp.newestCursor.ties++
+			}
+		}
+
+		// Track time bounds for display titles
+		if rowIndex == 0 || (!entry.Time.IsZero() && entry.Time.Before(lp.firstEntryTime)) {
+			lp.firstEntryTime = entry.Time
+			if !entry.TimeMs.IsZero() && entry.TimeMs.Before(lp.firstEntryTime) {
+				lp.firstEntryTime = entry.TimeMs
+			}
+		}
+		if rowIndex == 0 || (!entry.Time.IsZero() && entry.Time.After(lp.lastEntryTime)) {
+			lp.lastEntryTime = entry.Time
+			if !entry.TimeMs.IsZero() && entry.TimeMs.After(lp.lastEntryTime) {
+				lp.lastEntryTime = entry.TimeMs
+			}
+		}
+
+		// Update level counts for overview
+		if lp.levelField != "" {
+			if entry.Level != "" {
+				levelCounts[strings.ToLower(lp.canonicalLevel(entry.Level))]++
+			} else {
+				levelCounts["unknown"]++
+			}
+		}
+
+		// Add to batch
+		batch = append(batch, entry)
+		rowIndex++
+
+		// Process batch when full
+		if len(batch) >= batchSize {
+			if insertAtTop {
+				// Older pages arrive newest-first from the keyset query;
+				// reverse so each batch renders oldest-first, and stacking
+				// batches on top keeps the whole page in ascending order
+				reverseEntries(batch)
+			}
+			lp.processBatch(batch, rowIndex-len(batch), insertAtTop)
+			batch = batch[:0] // Clear batch while keeping capacity
+
+			// Update title with current time range
+			lp.app.tviewApp.QueueUpdateDraw(func() {
+				lp.logDetails.Table.SetTitle(lp.logTableTitle())
+			})
+		}
+	}
+
+	// Process any remaining entries in the batch
+	if len(batch) > 0 {
+		if insertAtTop {
+			reverseEntries(batch)
+		}
+		lp.processBatch(batch, rowIndex-len(batch), insertAtTop)
+
+		// Update title with final time range
+		lp.app.tviewApp.QueueUpdateDraw(func() {
+			lp.logDetails.Table.SetTitle(lp.logTableTitle())
+		})
+	}
+
+	lp.totalRows = rowIndex
+
+	// Update overview with collected statistics
+	lp.app.tviewApp.QueueUpdateDraw(func() {
+		lp.updateOverviewWithStats(levelCounts, lp.totalRows, false)
+		// Set focus to logDetails table after logs are loaded
+		lp.app.tviewApp.SetFocus(lp.logDetails.Table)
+	})
+
+	// Range totals are recomputed asynchronously and overwrite the page stats
+	if lp.rangeTotals {
+		go lp.loadRangeTotals()
+	}
+}
+
+// reverseEntries flips a batch in place
+func reverseEntries(batch []LogEntry) {
+	for i, j := 0, len(batch)-1; i < j; i, j = i+1, j-1 {
+		batch[i], batch[j] = batch[j], batch[i]
+	}
+}
+
+func (lp *LogPanel) processBatch(batch []LogEntry, startRow int, insertAtTop bool) {
+	lp.app.tviewApp.QueueUpdateDraw(func() {
+		lp.renderBatch(batch, startRow, insertAtTop)
+	})
+}
+
+// renderBatch fills the log table rows from a batch of entries. Split from
+// processBatch so rendering can run on the UI goroutine in the app and be
+// driven directly with fixture data in tests.
+func (lp *LogPanel) renderBatch(batch []LogEntry, startRow int, insertAtTop bool) {
+	if insertAtTop {
+		// Insert at top: shift existing rows down and insert new ones at the beginning
+		// First, collect all existing rows (excluding header)
+		existingRows := make([][]*tview.TableCell, 0)
+		for r := 1; r <= lp.totalRows; r++ {
+			row := make([]*tview.TableCell, 2)
+			row[0] = lp.logDetails.Table.GetCell(r, 0)
+			row[1] = lp.logDetails.Table.GetCell(r, 1)
+			existingRows = append(existingRows, row)
+		}
+
+		// Clear table and re-add headers
+		lp.logDetails.Table.Clear()
+		lp.logDetails.SetupHeaders([]string{"Time", "Message"})
+
+		// Add new batch entries first (at top)
+		for i, entry := range batch {
+			row := i + 1 // +1 for header row
+			timeStr := lp.formatTimeForDisplay(entry)
+
+			timeCell := tview.NewTableCell(timeStr).
+				SetReference(entry)
+			messageCell := tview.NewTableCell(lp.levelPrefix(entry) + lp.displayMessage(entry))
+			if entry.Level != "" {
+				messageCell.SetTextColor(lp.getColorForLevel(entry.Level))
+			}
+
+			lp.logDetails.SetRow(row, []*tview.TableCell{timeCell, messageCell})
+		}
+
+		// Add existing rows after the new ones
+		for i, existingRow := range existingRows {
+			row := len(batch) + i + 1 // +1 for header row
+			lp.logDetails.SetRow(row, existingRow)
+		}
+	} else {
+		// Insert at bottom (original behavior)
+		for i, entry := range batch {
+			row := startRow + i + 1 // +1 for header row
+			timeStr := lp.formatTimeForDisplay(entry)
+
+			// Store full entry in first cell's reference
+			// Create cells with proper styling
+			timeCell := tview.NewTableCell(timeStr).
+				SetReference(entry)
+			messageCell := tview.NewTableCell(lp.levelPrefix(entry) + lp.displayMessage(entry))
+			if entry.Level != "" {
+				messageCell.SetTextColor(lp.getColorForLevel(entry.Level))
+			}
+
+			// Set row in filtered table
+			lp.logDetails.SetRow(row, []*tview.TableCell{timeCell, messageCell})
+		}
+	}
+}
+
+func (lp *LogPanel) formatTimeForDisplay(entry LogEntry) string {
+	if !entry.TimeMs.IsZero() {
+		return utils.FormatTimestampTable(entry.TimeMs)
+	} else if !entry.Time.IsZero() {
+		return utils.FormatTimestampTable(entry.Time)
+	} else if entry.Date != "" {
+		return entry.Date
+	}
+	return ""
+}
+
+// levelPrefix marks an entry's level with a symbol in themes that don't rely
+// on color alone; other themes add nothing
+func (lp *LogPanel) levelPrefix(entry LogEntry) string {
+	if entry.Level == "" {
+		return ""
+	}
+	return levelMarker(lp.canonicalLevel(entry.Level))
+}
+
+func (lp *LogPanel) getColorForLevel(level string) tcell.Color {
+	return levelColor(lp.canonicalLevel(level))
+}
+
+func (lp *LogPanel) setupTabNavigation(filterField *tview.DropDown, filterOp *tview.DropDown, filterValue *tview.InputField, addFilterBtn *tview.Button, previewCheck *tview.Checkbox) {
+	// Create a list of all focusableItems components in order
+	focusableItems := []tview.Primitive{
+		filterField,
+		filterOp,
+		filterValue,
+		addFilterBtn,
+		previewCheck,
+		lp.logDetails.Table,
+	}
+
+	// Helper function to create tab navigation handler
+	createTabHandler := func(currentIndex int) func(event *tcell.EventKey) *tcell.EventKey {
+		return func(event *tcell.EventKey) *tcell.EventKey {
+			if event.Key() == tcell.KeyTab {
+				// Move to next component
+				nextIndex := (currentIndex + 1) % len(focusableItems)
+				lp.app.tviewApp.SetFocus(focusableItems[nextIndex])
+				return nil
+			} else if event.Key() == tcell.KeyBacktab {
+				// Move to previous component
+				prevIndex := (currentIndex - 1 + len(focusableItems)) % len(focusableItems)
+				lp.app.tviewApp.SetFocus(focusableItems[prevIndex])
+				return nil
+			}
+			return event
+		}
+	}
+
+	// Set up tab navigation for each component type
+	filterField.SetInputCapture(createTabHandler(0))
+	filterOp.SetInputCapture(createTabHandler(1))
+	filterValue.SetInputCapture(createTabHandler(2))
+	addFilterBtn.SetInputCapture(createTabHandler(3))
+	previewCheck.SetInputCapture(createTabHandler(4))
+
+	// For logDetails, we need to preserve existing input capture and add tab navigation
+	existingHandler := lp.logDetails.Table.GetInputCapture()
+	lp.logDetails.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// Handle tab navigation first
+		if event.Key() == tcell.KeyTab {
+			lp.app.tviewApp.SetFocus(focusableItems[0]) // Go back to first component
+			return nil
+		} else if event.Key() == tcell.KeyBacktab {
+			// If we have filters, focus the last one added
+			if len(lp.filters) > 0 {
+				if filterFlex := lp.filterPanel.GetItem(0); filterFlex != nil {
+					if _, ok := filterFlex.(*tview.Flex); ok {
+						// The last filter button is at index len(lp.filters) in filterPanel
+						lastFilterIndex := len(lp.filters)
+						if lastFilterIndex < lp.filterPanel.GetItemCount() {
+							lp.app.tviewApp.SetFocus(lp.filterPanel.GetItem(lastFilterIndex))
+						}
+					}
+				}
+			} else {
+				// No filters, focus the filter field
+				lp.app.tviewApp.SetFocus(filterField)
+			}
+			return nil
+		}
+		// Pass to existing handler if not tab navigation
+		if existingHandler != nil {
+			if result := existingHandler(event); result == nil {
+				return nil // Event was handled
+			}
+		}
+		return event
+	})
+
+	// Set initial focus to the first filter field
+	lp.app.tviewApp.SetFocus(filterField)
+}
+
+func ternary(condition bool, trueVal, falseVal string) string {
+	if condition {
+		return trueVal
+	}
+	return falseVal
+}
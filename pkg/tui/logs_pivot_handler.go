@@ -0,0 +1,44 @@
+package tui
+
+// pivotToField opens a fresh log explorer filtered to field=value over the
+// original application time range (not the currently loaded window),
+// preserving the current panel on a stack so Ctrl+B returns to it —
+// mirroring the pivot workflow known from Kibana
+func (lp *LogPanel) pivotToField(field, value string) {
+	pivot := &LogPanel{
+		app:              lp.app,
+		database:         lp.database,
+		table:            lp.table,
+		messageField:     lp.messageField,
+		timeField:        lp.timeField,
+		timeMsField:      lp.timeMsField,
+		dateField:        lp.dateField,
+		levelField:       lp.levelField,
+		windowSize:       lp.windowSize,
+		maxMessageLength: lp.maxMessageLength,
+		allFields:        lp.allFields,
+		fieldTypes:       lp.fieldTypes,
+		filters: []LogFilter{
+			{Field: field, Operator: "=", Value: value},
+		},
+	}
+
+	lp.app.logPanelStack = append(lp.app.logPanelStack, lp)
+	lp.app.logPanel = pivot
+	lp.app.pages.RemovePage("logDetails")
+	pivot.showLogExplorer()
+}
+
+// returnFromPivot restores the panel the last pivot was opened from,
+// returns false when there is nothing to return to
+func (lp *LogPanel) returnFromPivot() bool {
+	stack := lp.app.logPanelStack
+	if len(stack) == 0 {
+		return false
+	}
+	previous := stack[len(stack)-1]
+	lp.app.logPanelStack = stack[:len(stack)-1]
+	lp.app.logPanel = previous
+	previous.showLogExplorer()
+	return true
+}
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"github.com/rs/zerolog/log"
+)
+
+// toggleRangeTotals switches the overview bar between statistics of the loaded
+// page and filtered totals computed over the whole selected time range
+func (lp *LogPanel) toggleRangeTotals() {
+	lp.rangeTotals = !lp.rangeTotals
+	if lp.rangeTotals {
+		lp.overview.SetText("Computing totals over the whole time range...")
+		go lp.loadRangeTotals()
+	} else {
+		lp.overview.SetText("Range totals off, statistics reflect the loaded rows again (reload with Ctrl+R)")
+	}
+}
+
+// loadRangeTotals counts entries per level over the whole selected range with
+// the active filters applied (a separate aggregating query, the row stream
+// only ever sees one page), and updates the overview bar with the result
+func (lp *LogPanel) loadRangeTotals() {
+	if lp.database == "" || lp.table == "" || lp.timeField == "" {
+		return
+	}
+
+	levelExpr := "''"
+	if lp.levelField != "" {
+		// the configured mapping is applied inside the query, so the GROUP BY
+		// already yields canonical levels
+		levelExpr = lp.levelMultiIfExpr(fmt.Sprintf("toString(%s)", chquery.QuoteID(lp.levelField)))
+	}
+	timeCondition := fmt.Sprintf("%s >= ? AND %s <= ?", chquery.QuoteID(lp.timeField), chquery.QuoteID(lp.timeField))
+	whereClause, args := lp.buildWhereClause(timeCondition, []interface{}{lp.app.fromTime, lp.app.toTime})
+
+	query := fmt.Sprintf("SELECT %s AS level, count() AS cnt FROM %s WHERE %s GROUP BY level",
+		levelExpr, lp.sourceExpr(), whereClause)
+
+	rows, err := lp.app.clickHouse.QueryContext(lp.app.queryContext(), query, args...)
+	if err != nil {
+		lp.app.tviewApp.QueueUpdateDraw(func() {
+			lp.overview.SetText(fmt.Sprintf("Error computing range totals: %v", err))
+		})
+		return
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close loadRangeTotals rows")
+		}
+	}()
+
+	levelCounts := make(map[string]int)
+	total := 0
+	for rows.Next() {
+		var level string
+		var cnt uint64
+		if scanErr := rows.Scan(&level, &cnt); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan range totals row")
+			continue
+		}
+		if level == "" {
+			level = "unknown"
+		} else {
+			level = strings.ToLower(lp.canonicalLevel(level))
+		}
+		levelCounts[level] += int(cnt)
+		total += int(cnt)
+	}
+
+	lp.app.tviewApp.QueueUpdateDraw(func() {
+		lp.updateOverviewWithStats(levelCounts, total, true)
+	})
+}
@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// previewFilterSQL shows the WHERE clause the filters translate to plus an
+// EXPLAIN ESTIMATE of the scan size before the candidate filter is applied, so
+// expensive filter combinations can be cancelled instead of executed
+func (lp *LogPanel) previewFilterSQL(candidate LogFilter, apply func()) {
+	// buildWhereClause renders lp.filters, swap the candidate in temporarily
+	savedFilters := lp.filters
+	lp.filters = append(append([]LogFilter{}, savedFilters...), candidate)
+	timeCondition := fmt.Sprintf("%s >= ?", lp.timeField)
+	whereClause, queryArgs := lp.buildWhereClause(timeCondition, []interface{}{lp.app.fromTime})
+	lp.filters = savedFilters
+
+	go func() {
+		estimate := lp.fetchScanEstimate(whereClause, queryArgs)
+		lp.app.tviewApp.QueueUpdateDraw(func() {
+			lp.showFilterSQLModal(whereClause, queryArgs, estimate, apply)
+		})
+	}()
+}
+
+// fetchScanEstimate runs EXPLAIN ESTIMATE for the WHERE clause and sums the
+// parts/rows/marks that would be read
+func (lp *LogPanel) fetchScanEstimate(whereClause string, queryArgs []interface{}) string {
+	query := fmt.Sprintf("EXPLAIN ESTIMATE SELECT * FROM %s WHERE %s",
+		lp.sourceExpr(), whereClause)
+	rows, err := lp.app.clickHouse.QueryContext(lp.app.queryContext(), query, queryArgs...)
+	if err != nil {
+		log.Error().Err(err).Msg("can't fetch scan estimate")
+		return fmt.Sprintf("estimate unavailable: %v", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close fetchScanEstimate rows")
+		}
+	}()
+
+	var totalParts, totalRows, totalMarks uint64
+	for rows.Next() {
+		var database, table string
+		var parts, rowCount, marks uint64
+		if scanErr := rows.Scan(&database, &table, &parts, &rowCount, &marks); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan scan estimate row")
+			continue
+		}
+		totalParts += parts
+		totalRows += rowCount
+		totalMarks += marks
+	}
+	return fmt.Sprintf("%d rows in %d parts (%d marks)", totalRows, totalParts, totalMarks)
+}
+
+// showFilterSQLModal asks for confirmation, Apply runs the provided callback
+func (lp *LogPanel) showFilterSQLModal(whereClause string, queryArgs []interface{}, estimate string, apply func()) {
+	// Inline the placeholder values so the clause reads like the final SQL
+	rendered := whereClause
+	for _, arg := range queryArgs {
+		rendered = strings.Replace(rendered, "?", fmt.Sprintf("'%v'", arg), 1)
+	}
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("WHERE %s\n\nEstimated scan: %s", rendered, estimate)).
+		AddButtons([]string{"Apply", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			lp.app.pages.RemovePage("sqlPreview")
+			lp.app.pages.SwitchToPage("logExplorer")
+			if buttonLabel == "Apply" {
+				apply()
+			}
+		})
+	lp.app.pages.AddPage("sqlPreview", modal, true, true)
+	lp.app.tviewApp.SetFocus(modal)
+}
@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// showSubqueryEditor opens a small editor for IN/NOT IN subquery filters,
+// e.g. query_id IN (SELECT query_id FROM system.query_log WHERE ...).
+// The subquery is validated with EXPLAIN SYNTAX before the filter is added,
+// enabling cross-table investigations directly from the logs filter form.
+func (lp *LogPanel) showSubqueryEditor(field, operator, initialValue string) {
+	editorFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+	editorFlex.SetBorder(true).SetTitle(fmt.Sprintf("Subquery for `%s` %s (...)", field, operator))
+
+	statusText := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("Enter a SELECT returning one column, e.g. SELECT query_id FROM system.query_log WHERE exception != ''")
+
+	subqueryArea := tview.NewTextArea().
+		SetPlaceholder("SELECT ... FROM ... WHERE ...")
+	subqueryArea.SetText(initialValue, true)
+	subqueryArea.SetBorder(true)
+
+	closeEditor := func() {
+		lp.app.pages.RemovePage("logSubqueryEditor")
+		lp.app.pages.SwitchToPage("logExplorer")
+		lp.app.tviewApp.SetFocus(lp.logDetails.Table)
+	}
+
+	addBtn := tview.NewButton("Validate & Add").SetSelectedFunc(func() {
+		subquery := strings.TrimSpace(subqueryArea.GetText())
+		if subquery == "" {
+			statusText.SetText("[red]Subquery is empty[-]")
+			return
+		}
+		if !strings.HasPrefix(strings.ToUpper(subquery), "SELECT") {
+			statusText.SetText("[red]Subquery must start with SELECT[-]")
+			return
+		}
+		statusText.SetText("[yellow]Validating subquery...[-]")
+		go func() {
+			rows, err := lp.app.clickHouse.QueryContext(lp.app.queryContext(), "EXPLAIN SYNTAX "+subquery)
+			if err != nil {
+				lp.app.tviewApp.QueueUpdateDraw(func() {
+					statusText.SetText(fmt.Sprintf("[red]Subquery validation failed: %v[-]", err))
+				})
+				return
+			}
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close subquery validation rows")
+			}
+			lp.app.tviewApp.QueueUpdateDraw(func() {
+				lp.filters = append(lp.filters, LogFilter{
+					Field:      field,
+					Operator:   operator,
+					Value:      subquery,
+					IsSubquery: true,
+				})
+				lp.updateFilterDisplay(lp.filterPanel)
+				closeEditor()
+				go lp.loadLogs()
+			})
+		}()
+	})
+	cancelBtn := tview.NewButton("Cancel").SetSelectedFunc(closeEditor)
+
+	buttonsFlex := tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(addBtn, 0, 1, false).
+		AddItem(nil, 1, 0, false).
+		AddItem(cancelBtn, 0, 1, false)
+
+	editorFlex.AddItem(statusText, 2, 0, false)
+	editorFlex.AddItem(subqueryArea, 0, 1, true)
+	editorFlex.AddItem(buttonsFlex, 1, 0, false)
+
+	// Tab cycles editor -> add -> cancel -> editor, Esc cancels
+	subqueryArea.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			lp.app.tviewApp.SetFocus(addBtn)
+			return nil
+		} else if event.Key() == tcell.KeyEscape {
+			closeEditor()
+			return nil
+		}
+		return event
+	})
+	addBtn.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			lp.app.tviewApp.SetFocus(cancelBtn)
+			return nil
+		} else if event.Key() == tcell.KeyBacktab {
+			lp.app.tviewApp.SetFocus(subqueryArea)
+			return nil
+		}
+		return event
+	})
+	cancelBtn.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			lp.app.tviewApp.SetFocus(subqueryArea)
+			return nil
+		} else if event.Key() == tcell.KeyBacktab {
+			lp.app.tviewApp.SetFocus(addBtn)
+			return nil
+		}
+		return event
+	})
+
+	lp.app.pages.AddPage("logSubqueryEditor", editorFlex, true, true)
+	lp.app.pages.SwitchToPage("logSubqueryEditor")
+	lp.app.tviewApp.SetFocus(subqueryArea)
+}
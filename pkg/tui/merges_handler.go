@@ -0,0 +1,323 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// mergesRefreshInterval is how often system.merges and system.mutations are
+// re-polled while the monitor is open
+const mergesRefreshInterval = 5 * time.Second
+
+// mergeProgressBarWidth is how many cells the textual progress bar occupies
+const mergeProgressBarWidth = 20
+
+// mergeRow is one ongoing merge from one poll of system.merges
+type mergeRow struct {
+	host      string
+	database  string
+	table     string
+	elapsed   float64
+	progress  float64
+	numParts  uint64
+	totalSize uint64
+	mergeType string
+}
+
+// mutationRow is one unfinished mutation from system.mutations
+type mutationRow struct {
+	host       string
+	database   string
+	table      string
+	mutationID string
+	command    string
+	createTime time.Time
+	partsToDo  int64
+	failReason string
+}
+
+// mergeProgressBar renders a textual progress bar for a 0..1 progress value
+func mergeProgressBar(progress float64) string {
+	if progress < 0 {
+		progress = 0
+	}
+	if progress > 1 {
+		progress = 1
+	}
+	filled := int(progress * mergeProgressBarWidth)
+	bar := ""
+	for i := 0; i < mergeProgressBarWidth; i++ {
+		if i < filled {
+			bar += "█"
+		} else {
+			bar += "░"
+		}
+	}
+	return fmt.Sprintf("%s %3.0f%%", bar, progress*100)
+}
+
+// mergeETA estimates the remaining time of a merge from its elapsed time and
+// progress; returns "?" while progress is too small to extrapolate from
+func mergeETA(elapsed, progress float64) string {
+	if progress < 0.01 {
+		return "?"
+	}
+	remaining := elapsed / progress * (1 - progress)
+	return fmt.Sprintf("%ss", utils.FormatReadable(remaining, 0))
+}
+
+// ShowMergesMonitor opens a periodically refreshing page with ongoing merges
+// (progress bar, elapsed, estimated completion) on top and unfinished
+// mutations below; Enter on a mutation offers KILL MUTATION behind a
+// confirmation dialog
+func (a *App) ShowMergesMonitor() {
+	mergesTable := widgets.NewFilteredTable()
+	mergesTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	mergesTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Merges | refresh every %s [yellow](Tab: switch table, Esc: return)[-]", mergesRefreshInterval))
+	mergesTable.SetupHeaders([]string{"Host", "Table", "Progress", "Elapsed", "ETA", "Parts", "Size", "Type"})
+
+	mutationsTable := widgets.NewFilteredTable()
+	mutationsTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	mutationsTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle("Mutations [yellow](Enter: kill mutation)[-]")
+	mutationsTable.SetupHeaders([]string{"Host", "Table", "Mutation", "Age", "Parts left", "Command", "Last fail"})
+
+	monitorFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(mergesTable.Table, 0, 1, true).
+		AddItem(mutationsTable.Table, 0, 1, false)
+
+	stop := make(chan struct{})
+	stopped := false
+	// latest mutation rows shown in the bottom table, updated on the UI thread
+	var mutations []mutationRow
+
+	navigate := func(table *widgets.FilteredTable, other *widgets.FilteredTable) func(event *tcell.EventKey) *tcell.EventKey {
+		existingHandler := table.GetInputCapture(a.tviewApp, a.pages)
+		return func(event *tcell.EventKey) *tcell.EventKey {
+			if result := existingHandler(event); result == nil {
+				return nil
+			}
+			if event.Key() == tcell.KeyTab || event.Key() == tcell.KeyBacktab {
+				a.tviewApp.SetFocus(other.Table)
+				return nil
+			} else if event.Key() == tcell.KeyEscape {
+				if !stopped {
+					stopped = true
+					close(stop)
+				}
+				a.pages.RemovePage("mergesMonitor")
+				a.SwitchToMainPage("Returned from :merges")
+				return nil
+			}
+			return event
+		}
+	}
+	mergesTable.Table.SetInputCapture(navigate(mergesTable, mutationsTable))
+	mutationsHandler := navigate(mutationsTable, mergesTable)
+	mutationsTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEnter {
+			row, _ := mutationsTable.Table.GetSelection()
+			if row > 0 && row <= len(mutations) {
+				a.confirmKillMutation(mutationsTable, mutations[row-1])
+			}
+			return nil
+		}
+		return mutationsHandler(event)
+	})
+
+	a.pages.AddPage("mergesMonitor", monitorFlex, true, true)
+	a.pages.SwitchToPage("mergesMonitor")
+	a.tviewApp.SetFocus(mergesTable.Table)
+
+	go func() {
+		for {
+			merges := a.fetchMergesSnapshot()
+			mutationsSnapshot := a.fetchMutationsSnapshot()
+			a.tviewApp.QueueUpdateDraw(func() {
+				mutations = mutationsSnapshot
+				a.renderMergesMonitor(mergesTable, mutationsTable, merges, mutationsSnapshot)
+			})
+			select {
+			case <-stop:
+				return
+			case <-time.After(mergesRefreshInterval):
+			}
+		}
+	}()
+}
+
+// fetchMergesSnapshot loads the currently running merges across the cluster
+func (a *App) fetchMergesSnapshot() []mergeRow {
+	query := fmt.Sprintf(
+		"SELECT hostName() AS host, database, table, elapsed, progress, "+
+			"toUInt64(num_parts) AS num_parts, total_size_bytes_compressed, toString(merge_type) AS merge_type "+
+			"FROM clusterAllReplicas('%s', merge(system,'^merges$')) "+
+			"SETTINGS skip_unavailable_shards=1",
+		a.cluster)
+
+	rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("mergesMonitor"), query)
+	if err != nil {
+		log.Error().Err(err).Msg("can't fetch merges snapshot")
+		return nil
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close fetchMergesSnapshot rows")
+		}
+	}()
+
+	var merges []mergeRow
+	for rows.Next() {
+		var row mergeRow
+		if scanErr := rows.Scan(&row.host, &row.database, &row.table, &row.elapsed, &row.progress,
+			&row.numParts, &row.totalSize, &row.mergeType); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan merges snapshot row")
+			continue
+		}
+		merges = append(merges, row)
+	}
+	sort.Slice(merges, func(i, j int) bool {
+		return merges[i].elapsed > merges[j].elapsed
+	})
+	return merges
+}
+
+// fetchMutationsSnapshot loads the unfinished mutations across the cluster
+func (a *App) fetchMutationsSnapshot() []mutationRow {
+	query := fmt.Sprintf(
+		"SELECT hostName() AS host, database, table, mutation_id, command, create_time, "+
+			"toInt64(parts_to_do) AS parts_to_do, latest_fail_reason "+
+			"FROM clusterAllReplicas('%s', merge(system,'^mutations$')) "+
+			"WHERE NOT is_done "+
+			"SETTINGS skip_unavailable_shards=1",
+		a.cluster)
+
+	rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("mergesMonitor"), query)
+	if err != nil {
+		log.Error().Err(err).Msg("can't fetch mutations snapshot")
+		return nil
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close fetchMutationsSnapshot rows")
+		}
+	}()
+
+	var mutations []mutationRow
+	for rows.Next() {
+		var row mutationRow
+		if scanErr := rows.Scan(&row.host, &row.database, &row.table, &row.mutationID, &row.command,
+			&row.createTime, &row.partsToDo, &row.failReason); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan mutations snapshot row")
+			continue
+		}
+		mutations = append(mutations, row)
+	}
+	sort.Slice(mutations, func(i, j int) bool {
+		return mutations[i].createTime.Before(mutations[j].createTime)
+	})
+	return mutations
+}
+
+// renderMergesMonitor repopulates both tables from the latest poll, keeping
+// the current selections where possible
+func (a *App) renderMergesMonitor(mergesTable *widgets.FilteredTable, mutationsTable *widgets.FilteredTable, merges []mergeRow, mutations []mutationRow) {
+	repopulate := func(filteredTable *widgets.FilteredTable, fill func()) {
+		selectedRow, selectedCol := filteredTable.Table.GetSelection()
+		filteredTable.Table.Clear()
+		filteredTable.OriginalRows = nil
+		filteredTable.SetupHeaders(filteredTable.Headers)
+		fill()
+		if selectedRow > 0 && selectedRow < filteredTable.Table.GetRowCount() {
+			filteredTable.Table.Select(selectedRow, selectedCol)
+		} else if filteredTable.Table.GetRowCount() > 1 {
+			filteredTable.Table.Select(1, 0)
+		}
+	}
+
+	repopulate(mergesTable, func() {
+		for _, row := range merges {
+			color := tcell.ColorWhite
+			if row.elapsed > 3600 {
+				color = tcell.ColorRed
+			} else if row.elapsed > 600 {
+				color = tcell.ColorYellow
+			}
+			mergesTable.AddRow([]*tview.TableCell{
+				tview.NewTableCell(row.host).SetTextColor(color).SetAlign(tview.AlignLeft),
+				tview.NewTableCell(row.database + "." + row.table).SetTextColor(color).SetAlign(tview.AlignLeft),
+				tview.NewTableCell(mergeProgressBar(row.progress)).SetTextColor(color).SetAlign(tview.AlignLeft),
+				tview.NewTableCell(fmt.Sprintf("%.0fs", row.elapsed)).SetTextColor(color).SetAlign(tview.AlignRight),
+				tview.NewTableCell(mergeETA(row.elapsed, row.progress)).SetTextColor(color).SetAlign(tview.AlignRight),
+				tview.NewTableCell(fmt.Sprintf("%d", row.numParts)).SetTextColor(color).SetAlign(tview.AlignRight),
+				tview.NewTableCell(fmt.Sprintf("%sB", utils.FormatReadable(float64(row.totalSize), 1))).SetTextColor(color).SetAlign(tview.AlignRight),
+				tview.NewTableCell(row.mergeType).SetTextColor(color).SetAlign(tview.AlignLeft),
+			})
+		}
+	})
+
+	repopulate(mutationsTable, func() {
+		for _, row := range mutations {
+			color := tcell.ColorWhite
+			if row.failReason != "" {
+				color = tcell.ColorRed
+			} else if time.Since(row.createTime) > time.Hour {
+				color = tcell.ColorYellow
+			}
+			mutationsTable.AddRow([]*tview.TableCell{
+				tview.NewTableCell(row.host).SetTextColor(color).SetAlign(tview.AlignLeft),
+				tview.NewTableCell(row.database + "." + row.table).SetTextColor(color).SetAlign(tview.AlignLeft),
+				tview.NewTableCell(row.mutationID).SetTextColor(color).SetAlign(tview.AlignLeft),
+				tview.NewTableCell(fmt.Sprintf("%ss", utils.FormatReadable(time.Since(row.createTime).Seconds(), 0))).SetTextColor(color).SetAlign(tview.AlignRight),
+				tview.NewTableCell(fmt.Sprintf("%d", row.partsToDo)).SetTextColor(color).SetAlign(tview.AlignRight),
+				tview.NewTableCell(row.command).SetTextColor(color).SetAlign(tview.AlignLeft),
+				tview.NewTableCell(row.failReason).SetTextColor(color).SetAlign(tview.AlignLeft),
+			})
+		}
+	})
+}
+
+// confirmKillMutation asks for confirmation and then issues KILL MUTATION for
+// the selected mutation
+func (a *App) confirmKillMutation(mutationsTable *widgets.FilteredTable, mutation mutationRow) {
+	killQuery := fmt.Sprintf("KILL MUTATION WHERE database = '%s' AND table = '%s' AND mutation_id = '%s'",
+		chquery.EscapeString(mutation.database), chquery.EscapeString(mutation.table), chquery.EscapeString(mutation.mutationID))
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("%s\n\nMutation command:\n%s\n\nKill this mutation?", killQuery, mutation.command)).
+		AddButtons([]string{"Kill", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("killMutation")
+			a.pages.SwitchToPage("mergesMonitor")
+			a.tviewApp.SetFocus(mutationsTable.Table)
+			if buttonLabel != "Kill" {
+				return
+			}
+			go func() {
+				_, killErr := a.clickHouse.Exec(killQuery)
+				a.tviewApp.QueueUpdateDraw(func() {
+					if killErr != nil {
+						log.Error().Err(killErr).Str("mutation_id", mutation.mutationID).Msg("can't kill mutation")
+						mutationsTable.Table.SetTitle(fmt.Sprintf("Mutations [red](kill %s failed: %v)[-]", mutation.mutationID, killErr))
+					} else {
+						mutationsTable.Table.SetTitle(fmt.Sprintf("Mutations [green](killed %s)[-]", mutation.mutationID))
+					}
+				})
+			}()
+		})
+	a.pages.AddPage("killMutation", modal, true, true)
+	a.tviewApp.SetFocus(modal)
+}
@@ -0,0 +1,61 @@
+package tui
+
+// navigationHistory records which pages the user has visited so Alt+Left and
+// Alt+Right walk back and forward through them. Handlers keep their pages
+// registered via AddPage, so returning to a page through history restores it
+// with its state intact instead of rebuilding it from scratch.
+type navigationHistory struct {
+	entries []string
+	// pos points at the entry of the page currently in front, -1 before the
+	// first page is shown
+	pos int
+	// suppress is set while history itself switches pages, so the page change
+	// callback doesn't record the jump as a new visit
+	suppress bool
+}
+
+// navigationSkip lists transient pages that are not worth returning to
+var navigationSkip = map[string]bool{
+	keymapOverlayPage: true,
+	"explain_loading": true,
+}
+
+// initNavigationHistory hooks the pages container, must run right after
+// a.pages is created so the initial main page is the first history entry
+func (a *App) initNavigationHistory() {
+	a.nav.pos = -1
+	a.pages.SetChangedFunc(func() {
+		a.recordPageVisit()
+	})
+}
+
+// recordPageVisit appends the current front page to the history; visiting a
+// new page truncates the forward branch, like a browser does
+func (a *App) recordPageVisit() {
+	name, _ := a.pages.GetFrontPage()
+	if name == "" || navigationSkip[name] || a.nav.suppress {
+		return
+	}
+	if a.nav.pos >= 0 && a.nav.pos < len(a.nav.entries) && a.nav.entries[a.nav.pos] == name {
+		return
+	}
+	a.nav.entries = append(a.nav.entries[:a.nav.pos+1:a.nav.pos+1], name)
+	a.nav.pos = len(a.nav.entries) - 1
+}
+
+// navigateHistory moves through the visited pages, -1 is back and +1 is
+// forward; entries whose page has been removed in the meantime are skipped
+func (a *App) navigateHistory(delta int) {
+	for pos := a.nav.pos + delta; pos >= 0 && pos < len(a.nav.entries); pos += delta {
+		name := a.nav.entries[pos]
+		if !a.pages.HasPage(name) {
+			continue
+		}
+		a.nav.pos = pos
+		a.nav.suppress = true
+		a.pages.SwitchToPage(name)
+		a.nav.suppress = false
+		a.tviewApp.SetFocus(a.pages)
+		return
+	}
+}
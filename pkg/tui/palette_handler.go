@@ -0,0 +1,167 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// paletteEntry is one actionable item in the command palette: a ':' command,
+// a connection or a saved bookmark, all searchable from one place
+type paletteEntry struct {
+	title  string
+	detail string
+	run    func()
+}
+
+// commandDescriptions parses helpText into a command -> one-liner map, so the
+// palette reuses the descriptions without maintaining a second list
+func commandDescriptions() map[string]string {
+	descriptions := make(map[string]string)
+	for _, line := range strings.Split(helpText, "\n") {
+		line = strings.TrimRight(strings.TrimSpace(line), "\r")
+		if !strings.HasPrefix(line, ":") {
+			continue
+		}
+		name, description, found := strings.Cut(line[1:], "-")
+		if !found {
+			continue
+		}
+		descriptions[strings.TrimSpace(name)] = strings.TrimSpace(description)
+	}
+	return descriptions
+}
+
+// buildPaletteEntries assembles everything the palette can run: every ':'
+// command, the configured connections and the saved time-range bookmarks
+func (a *App) buildPaletteEntries() []paletteEntry {
+	descriptions := commandDescriptions()
+
+	entries := make([]paletteEntry, 0, len(availableCommands))
+	for _, cmd := range availableCommands {
+		command := cmd
+		entries = append(entries, paletteEntry{
+			title:  ":" + command,
+			detail: descriptions[command],
+			run:    func() { a.dispatchCommand(command) },
+		})
+	}
+
+	if a.cfg != nil {
+		for i, ctx := range a.cfg.Contexts {
+			index := i
+			entries = append(entries, paletteEntry{
+				title:  "connect " + ctx.Name,
+				detail: fmt.Sprintf("%s:%d", ctx.Host, ctx.Port),
+				run:    func() { a.handleContextSelection(index) },
+			})
+		}
+	}
+
+	for _, bookmark := range loadRangeBookmarks() {
+		applied := bookmark
+		entries = append(entries, paletteEntry{
+			title:  "bookmark " + bookmark.Name,
+			detail: fmt.Sprintf("%s - %s", utils.FormatTimestamp(bookmark.From), utils.FormatTimestamp(bookmark.To)),
+			run: func() {
+				a.fromTime = applied.From
+				a.toTime = applied.To
+				a.SwitchToMainPage(fmt.Sprintf("Time range set to bookmark '%s'", applied.Name))
+			},
+		})
+	}
+	return entries
+}
+
+// fuzzyMatch reports whether all runes of pattern appear in text in order,
+// case-insensitively, the usual palette matching ("hmap" finds ":heatmap")
+func fuzzyMatch(pattern, text string) bool {
+	pattern = strings.ToLower(pattern)
+	text = strings.ToLower(text)
+	for _, r := range pattern {
+		index := strings.IndexRune(text, r)
+		if index < 0 {
+			return false
+		}
+		text = text[index+1:]
+	}
+	return true
+}
+
+// showCommandPalette opens a fuzzy-searchable list of commands, connections
+// and bookmarks, so functionality is discoverable without memorizing ':'
+// command names
+func (a *App) showCommandPalette() {
+	entries := a.buildPaletteEntries()
+
+	input := tview.NewInputField().SetLabel("> ").SetFieldWidth(0)
+	list := tview.NewList().ShowSecondaryText(true)
+	list.SetSecondaryTextColor(tcell.ColorGray)
+
+	var visible []paletteEntry
+	render := func(pattern string) {
+		list.Clear()
+		visible = visible[:0]
+		for _, entry := range entries {
+			if pattern != "" && !fuzzyMatch(pattern, entry.title+" "+entry.detail) {
+				continue
+			}
+			visible = append(visible, entry)
+			list.AddItem(entry.title, entry.detail, 0, nil)
+		}
+	}
+	render("")
+
+	input.SetChangedFunc(func(text string) {
+		render(strings.TrimSpace(text))
+	})
+
+	runSelected := func() {
+		index := list.GetCurrentItem()
+		if index < 0 || index >= len(visible) {
+			return
+		}
+		entry := visible[index]
+		a.pages.RemovePage("palette")
+		a.pages.SwitchToPage("main")
+		entry.run()
+	}
+	list.SetSelectedFunc(func(int, string, string, rune) {
+		runSelected()
+	})
+
+	closePalette := func() {
+		a.pages.RemovePage("palette")
+		a.pages.SwitchToPage("main")
+	}
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closePalette()
+			return nil
+		case tcell.KeyEnter:
+			runSelected()
+			return nil
+		case tcell.KeyDown, tcell.KeyUp, tcell.KeyPgDn, tcell.KeyPgUp:
+			// arrows move the selection while typing continues in the input
+			if handler := list.InputHandler(); handler != nil {
+				handler(event, func(tview.Primitive) {})
+			}
+			return nil
+		}
+		return event
+	})
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(list, 0, 1, false)
+	flex.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle("Command palette [yellow](type to search, Enter: run, Esc: close)[-]")
+
+	a.pages.AddPage("palette", flex, true, true)
+	a.pages.SwitchToPage("palette")
+	a.tviewApp.SetFocus(input)
+}
@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/rs/zerolog/log"
+)
+
+// newPanelLogComment returns a unique log_comment for one panel query, so the
+// panel can find its own system.query_log entry afterwards
+func newPanelLogComment(panel string) string {
+	return fmt.Sprintf("clickhouse-timeline:%s:%d", panel, time.Now().UnixNano())
+}
+
+// fetchPanelQueryCost reads the query_log entry written for the given
+// log_comment and formats its cost (elapsed, read rows/bytes, memory), so
+// users learn which panel actions are expensive on the server. Best effort:
+// returns "" until the server flushes system.query_log.
+func (a *App) fetchPanelQueryCost(logComment string) string {
+	query := fmt.Sprintf(
+		"SELECT query_duration_ms, read_rows, read_bytes, memory_usage FROM system.query_log WHERE log_comment='%s' AND type != 'QueryStart' ORDER BY event_time_microseconds DESC LIMIT 1",
+		logComment)
+
+	var durationMs, readRows, readBytes uint64
+	var memoryUsage int64
+	row := a.clickHouse.QueryRow(query)
+	if row == nil {
+		return ""
+	}
+	if err := row.Scan(&durationMs, &readRows, &readBytes, &memoryUsage); err != nil {
+		log.Debug().Err(err).Str("log_comment", logComment).Msg("panel query cost not in query_log yet")
+		return ""
+	}
+	return fmt.Sprintf("query cost: %d ms, read %s rows / %sB, mem %sB",
+		durationMs,
+		utils.FormatReadable(float64(readRows), 1),
+		utils.FormatReadable(float64(readBytes), 1),
+		utils.FormatReadable(float64(memoryUsage), 1))
+}
+
+// showPanelQueryCost polls query_log for the panel query cost and hands the
+// formatted string to display when it becomes available
+func (a *App) showPanelQueryCost(logComment string, display func(cost string)) {
+	// system.query_log is flushed with a delay, poll a few times before giving up
+	for _, delay := range []time.Duration{1 * time.Second, 4 * time.Second, 8 * time.Second} {
+		time.Sleep(delay)
+		if cost := a.fetchPanelQueryCost(logComment); cost != "" {
+			a.tviewApp.QueueUpdateDraw(func() {
+				display(cost)
+			})
+			return
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// processListRefreshInterval is how often system.processes is re-polled while
+// the live list is open
+const processListRefreshInterval = 5 * time.Second
+
+// processListRow is one running query in one poll of system.processes
+type processListRow struct {
+	host      string
+	queryID   string
+	user      string
+	elapsed   float64
+	memory    int64
+	readBytes uint64
+	query     string
+}
+
+// ShowProcessList opens a live view of system.processes across the cluster
+// with elapsed time, memory and read volume per query, refreshed every few
+// seconds; Enter offers KILL QUERY behind a confirmation dialog. Unlike the
+// :processes watch report this is meant for acting during an incident, not
+// for collecting a summary afterwards.
+func (a *App) ShowProcessList() {
+	filteredTable := widgets.NewFilteredTable()
+	filteredTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	filteredTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Processes | refresh every %s [yellow](Enter: kill query, Esc: return)[-]", processListRefreshInterval))
+	filteredTable.SetupHeaders([]string{"Host", "User", "Elapsed", "Memory", "Read", "Query ID", "Query"})
+
+	stop := make(chan struct{})
+	stopped := false
+	// latest rows shown in the table, updated on the UI thread
+	var processes []processListRow
+
+	existingHandler := filteredTable.GetInputCapture(a.tviewApp, a.pages)
+	filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if result := existingHandler(event); result == nil {
+			return nil
+		}
+		switch event.Key() {
+		case tcell.KeyEnter:
+			row, _ := filteredTable.Table.GetSelection()
+			if row > 0 && row <= len(processes) {
+				a.confirmKillQuery(filteredTable, processes[row-1])
+			}
+			return nil
+		case tcell.KeyEscape:
+			if !stopped {
+				stopped = true
+				close(stop)
+			}
+			a.pages.RemovePage("processList")
+			a.SwitchToMainPage("Returned from :top")
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("processList", filteredTable.Table, true, true)
+	a.pages.SwitchToPage("processList")
+	a.tviewApp.SetFocus(filteredTable.Table)
+
+	go func() {
+		for {
+			snapshot := a.fetchProcessList()
+			a.tviewApp.QueueUpdateDraw(func() {
+				processes = snapshot
+				a.renderProcessList(filteredTable, snapshot)
+			})
+			select {
+			case <-stop:
+				return
+			case <-time.After(processListRefreshInterval):
+			}
+		}
+	}()
+}
+
+// fetchProcessList loads the currently running queries across the cluster
+// with their resource usage, longest running first
+func (a *App) fetchProcessList() []processListRow {
+	query := fmt.Sprintf(
+		"SELECT hostName() AS host, query_id, user, elapsed, memory_usage, read_bytes, left(query, 200) AS query "+
+			"FROM clusterAllReplicas('%s', merge(system,'^processes$')) "+
+			"WHERE is_initial_query AND query NOT LIKE '%%system.processes%%' "+
+			"SETTINGS skip_unavailable_shards=1",
+		a.cluster)
+
+	rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("processList"), query)
+	if err != nil {
+		log.Error().Err(err).Msg("can't fetch process list")
+		return nil
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close fetchProcessList rows")
+		}
+	}()
+
+	var processes []processListRow
+	for rows.Next() {
+		var row processListRow
+		if scanErr := rows.Scan(&row.host, &row.queryID, &row.user, &row.elapsed,
+			&row.memory, &row.readBytes, &row.query); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan process list row")
+			continue
+		}
+		processes = append(processes, row)
+	}
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].elapsed > processes[j].elapsed
+	})
+	return processes
+}
+
+// renderProcessList repopulates the table from the latest poll, keeping the
+// current selection where possible
+func (a *App) renderProcessList(filteredTable *widgets.FilteredTable, processes []processListRow) {
+	selectedRow, selectedCol := filteredTable.Table.GetSelection()
+	filteredTable.Table.Clear()
+	filteredTable.OriginalRows = nil
+	filteredTable.SetupHeaders(filteredTable.Headers)
+
+	for _, row := range processes {
+		color := tcell.ColorWhite
+		if row.elapsed > 600 {
+			color = tcell.ColorRed
+		} else if row.elapsed > 60 {
+			color = tcell.ColorYellow
+		}
+		filteredTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(row.host).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(row.user).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(fmt.Sprintf("%.1fs", row.elapsed)).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(fmt.Sprintf("%sB", utils.FormatReadable(float64(row.memory), 1))).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(fmt.Sprintf("%sB", utils.FormatReadable(float64(row.readBytes), 1))).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(row.queryID).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(row.query).SetTextColor(color).SetAlign(tview.AlignLeft),
+		})
+	}
+
+	if selectedRow > 0 && selectedRow < filteredTable.Table.GetRowCount() {
+		filteredTable.Table.Select(selectedRow, selectedCol)
+	} else if filteredTable.Table.GetRowCount() > 1 {
+		filteredTable.Table.Select(1, 0)
+	}
+}
+
+// confirmKillQuery asks for confirmation and then issues KILL QUERY for the
+// selected process
+func (a *App) confirmKillQuery(filteredTable *widgets.FilteredTable, process processListRow) {
+	killQuery := fmt.Sprintf("KILL QUERY WHERE query_id = '%s'", chquery.EscapeString(process.queryID))
+
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("%s\n\nUser: %s, elapsed: %.1fs\n%s\n\nKill this query?",
+			killQuery, process.user, process.elapsed, process.query)).
+		AddButtons([]string{"Kill", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("killQuery")
+			a.pages.SwitchToPage("processList")
+			a.tviewApp.SetFocus(filteredTable.Table)
+			if buttonLabel != "Kill" {
+				return
+			}
+			go func() {
+				_, killErr := a.clickHouse.Exec(killQuery)
+				a.tviewApp.QueueUpdateDraw(func() {
+					if killErr != nil {
+						log.Error().Err(killErr).Str("query_id", process.queryID).Msg("can't kill query")
+						filteredTable.Table.SetTitle(fmt.Sprintf("Processes [red](kill %s failed: %v)[-]", process.queryID, killErr))
+					} else {
+						filteredTable.Table.SetTitle(fmt.Sprintf("Processes [green](killed %s)[-]", process.queryID))
+					}
+				})
+			}()
+		})
+	a.pages.AddPage("killQuery", modal, true, true)
+	a.tviewApp.SetFocus(modal)
+}
@@ -0,0 +1,263 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// processWatchInterval is how often system.processes is snapshotted
+const processWatchInterval = 5 * time.Second
+
+// processWatchSnapshots is how many snapshots are taken (24 * 5s = 2 minutes)
+const processWatchSnapshots = 24
+
+// processSnapshotRow is one query observed in one system.processes snapshot
+type processSnapshotRow struct {
+	host    string
+	queryID string
+	user    string
+	elapsed float64
+	query   string
+}
+
+// runawayQuery aggregates one (host, query_id) across all snapshots
+type runawayQuery struct {
+	host       string
+	queryID    string
+	user       string
+	seen       int
+	maxElapsed float64
+	query      string
+}
+
+// userConcurrency tracks how many queries a user ran per snapshot
+type userConcurrency struct {
+	user  string
+	first int
+	last  int
+	peak  int
+}
+
+// ShowProcessWatch snapshots system.processes across the cluster every few
+// seconds and then reports queries present in most snapshots (long runners)
+// and users whose concurrency grew — a mini-report that works even when
+// query_log is disabled. Esc during sampling stops early and reports from
+// the snapshots collected so far.
+func (a *App) ShowProcessWatch() {
+	stop := make(chan struct{})
+	stopped := false
+
+	progress := tview.NewTextView().SetDynamicColors(true)
+	progress.SetBorder(true).SetTitle("Process watch")
+	progress.SetText(fmt.Sprintf("Snapshotting system.processes every %s, %d snapshots total...\n\n[yellow]Esc: stop early and report[-]",
+		processWatchInterval, processWatchSnapshots))
+	progress.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape && !stopped {
+			stopped = true
+			close(stop)
+			return nil
+		}
+		return event
+	})
+	a.pages.AddPage("processWatch", progress, true, true)
+	a.pages.SwitchToPage("processWatch")
+	a.tviewApp.SetFocus(progress)
+
+	go func() {
+		var snapshots [][]processSnapshotRow
+	sampling:
+		for i := 0; i < processWatchSnapshots; i++ {
+			snapshots = append(snapshots, a.fetchProcessSnapshot())
+			a.tviewApp.QueueUpdateDraw(func() {
+				progress.SetText(fmt.Sprintf("Snapshot %d/%d collected (%d running queries)...\n\n[yellow]Esc: stop early and report[-]",
+					len(snapshots), processWatchSnapshots, len(snapshots[len(snapshots)-1])))
+			})
+			if i == processWatchSnapshots-1 {
+				break
+			}
+			select {
+			case <-stop:
+				break sampling
+			case <-time.After(processWatchInterval):
+			}
+		}
+
+		runaways, users := analyzeProcessSnapshots(snapshots)
+		a.tviewApp.QueueUpdateDraw(func() {
+			a.pages.RemovePage("processWatch")
+			a.renderProcessWatchReport(len(snapshots), runaways, users)
+		})
+	}()
+}
+
+// fetchProcessSnapshot loads the currently running queries across the cluster
+func (a *App) fetchProcessSnapshot() []processSnapshotRow {
+	query := fmt.Sprintf(
+		"SELECT hostName() AS host, query_id, user, elapsed, left(query, 200) AS query "+
+			"FROM clusterAllReplicas('%s', merge(system,'^processes$')) "+
+			"WHERE is_initial_query AND query NOT LIKE '%%system.processes%%' "+
+			"SETTINGS skip_unavailable_shards=1",
+		a.cluster)
+
+	rows, err := a.clickHouse.Query(query)
+	if err != nil {
+		log.Error().Err(err).Msg("can't fetch process snapshot")
+		return nil
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close fetchProcessSnapshot rows")
+		}
+	}()
+
+	var snapshot []processSnapshotRow
+	for rows.Next() {
+		var row processSnapshotRow
+		if scanErr := rows.Scan(&row.host, &row.queryID, &row.user, &row.elapsed, &row.query); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan process snapshot row")
+			continue
+		}
+		snapshot = append(snapshot, row)
+	}
+	return snapshot
+}
+
+// analyzeProcessSnapshots finds queries present in multiple snapshots and
+// users whose concurrency grew between the first and the last snapshot
+func analyzeProcessSnapshots(snapshots [][]processSnapshotRow) ([]runawayQuery, []userConcurrency) {
+	queries := make(map[string]*runawayQuery)
+	userCounts := make(map[string][]int)
+	for i, snapshot := range snapshots {
+		perUser := make(map[string]int)
+		for _, row := range snapshot {
+			key := row.host + "|" + row.queryID
+			q, found := queries[key]
+			if !found {
+				q = &runawayQuery{host: row.host, queryID: row.queryID, user: row.user, query: row.query}
+				queries[key] = q
+			}
+			q.seen++
+			if row.elapsed > q.maxElapsed {
+				q.maxElapsed = row.elapsed
+			}
+			perUser[row.user]++
+		}
+		for user, count := range perUser {
+			if _, found := userCounts[user]; !found {
+				userCounts[user] = make([]int, len(snapshots))
+			}
+			userCounts[user][i] = count
+		}
+	}
+
+	var runaways []runawayQuery
+	for _, q := range queries {
+		if q.seen > 1 {
+			runaways = append(runaways, *q)
+		}
+	}
+	sort.Slice(runaways, func(i, j int) bool {
+		if runaways[i].seen != runaways[j].seen {
+			return runaways[i].seen > runaways[j].seen
+		}
+		return runaways[i].maxElapsed > runaways[j].maxElapsed
+	})
+
+	var users []userConcurrency
+	for user, counts := range userCounts {
+		uc := userConcurrency{user: user, first: counts[0], last: counts[len(counts)-1]}
+		for _, count := range counts {
+			if count > uc.peak {
+				uc.peak = count
+			}
+		}
+		users = append(users, uc)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].last-users[i].first > users[j].last-users[j].first
+	})
+	return runaways, users
+}
+
+// renderProcessWatchReport shows the long runners on top and the per-user
+// concurrency trend below
+func (a *App) renderProcessWatchReport(snapshotCount int, runaways []runawayQuery, users []userConcurrency) {
+	runawayTable := widgets.NewFilteredTable()
+	runawayTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	runawayTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Queries seen in multiple of %d snapshots [yellow](Tab: switch table, Esc: return)[-]", snapshotCount))
+	runawayTable.SetupHeaders([]string{"Seen", "Host", "User", "Max elapsed", "Query ID", "Query"})
+	for _, q := range runaways {
+		color := tcell.ColorWhite
+		if q.seen >= snapshotCount/2 {
+			color = tcell.ColorYellow
+		}
+		if q.seen == snapshotCount && snapshotCount > 1 {
+			color = tcell.ColorRed
+		}
+		runawayTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(fmt.Sprintf("%d/%d", q.seen, snapshotCount)).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(q.host).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(q.user).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(fmt.Sprintf("%.1fs", q.maxElapsed)).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(q.queryID).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(q.query).SetTextColor(color).SetAlign(tview.AlignLeft),
+		})
+	}
+
+	userTable := widgets.NewFilteredTable()
+	userTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	userTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle("Concurrency per user (first vs last snapshot)")
+	userTable.SetupHeaders([]string{"User", "First", "Last", "Peak"})
+	for _, uc := range users {
+		color := tcell.ColorWhite
+		if uc.last > uc.first {
+			color = tcell.ColorYellow
+		}
+		userTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(uc.user).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(fmt.Sprintf("%d", uc.first)).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(fmt.Sprintf("%d", uc.last)).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(fmt.Sprintf("%d", uc.peak)).SetTextColor(color).SetAlign(tview.AlignRight),
+		})
+	}
+
+	reportFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(runawayTable.Table, 0, 2, true).
+		AddItem(userTable.Table, 0, 1, false)
+
+	navigate := func(table *widgets.FilteredTable, other *widgets.FilteredTable) func(event *tcell.EventKey) *tcell.EventKey {
+		existingHandler := table.GetInputCapture(a.tviewApp, a.pages)
+		return func(event *tcell.EventKey) *tcell.EventKey {
+			if result := existingHandler(event); result == nil {
+				return nil
+			}
+			if event.Key() == tcell.KeyTab || event.Key() == tcell.KeyBacktab {
+				a.tviewApp.SetFocus(other.Table)
+				return nil
+			} else if event.Key() == tcell.KeyEscape {
+				a.pages.RemovePage("processWatchReport")
+				a.SwitchToMainPage("Returned from :processes")
+				return nil
+			}
+			return event
+		}
+	}
+	runawayTable.Table.SetInputCapture(navigate(runawayTable, userTable))
+	userTable.Table.SetInputCapture(navigate(userTable, runawayTable))
+
+	a.pages.AddPage("processWatchReport", reportFlex, true, true)
+	a.pages.SwitchToPage("processWatchReport")
+	a.tviewApp.SetFocus(runawayTable.Table)
+}
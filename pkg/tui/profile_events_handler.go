@@ -0,0 +1,248 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+const profileEventsQueryTemplate = `
+SELECT
+    key AS EventName,
+    count(),
+    quantile(0.5)(value) AS p50,
+    quantile(0.9)(value) AS p90,
+    quantile(0.99)(value) AS p99,
+    formatReadableQuantity(p50) AS p50_s,
+    formatReadableQuantity(p90) AS p90_s,
+    formatReadableQuantity(p99) AS p99_s,
+    any(normalizeQueryKeepNames(query)) AS normalized_query
+FROM clusterAllReplicas('%s', merge(system,'^query_log'))
+LEFT ARRAY JOIN mapKeys(ProfileEvents) AS key, mapValues(ProfileEvents) AS value
+WHERE
+    event_date >= toDate(parseDateTimeBestEffort('%s')) AND event_date <= toDate(parseDateTimeBestEffort('%s')) AND
+    event_time >= parseDateTimeBestEffort('%s') AND event_time <= parseDateTimeBestEffort('%s')
+    AND type != 'QueryStart'
+    %s
+GROUP BY key
+ORDER BY key
+`
+
+func (a *App) ShowProfileEvents(categoryType CategoryType, categoryValue string, fromTime, toTime time.Time, cluster string) {
+	if a.clickHouse == nil {
+		a.SwitchToMainPage("Error: Please connect to a ClickHouse instance first using :connect command")
+		return
+	}
+	if cluster == "" {
+		a.SwitchToMainPage("Error: Please select a cluster first using :cluster command")
+		return
+	}
+
+	a.mainView.SetText("Loading profile events, please wait...")
+
+	go func() {
+		// Format dates for the query
+		fromStr := fromTime.Format("2006-01-02 15:04:05 -07:00")
+		toStr := toTime.Format("2006-01-02 15:04:05 -07:00")
+
+		// Build categoryType filter if categoryValue is provided
+		var categoryFilter string
+		if categoryValue != "" {
+			switch categoryType {
+			case CategoryQueryHash:
+				categoryFilter = fmt.Sprintf("AND normalized_query_hash = '%s'", categoryValue)
+			case CategoryTable:
+				categoryFilter = fmt.Sprintf("AND has(tables, ['%s'])", categoryValue)
+			case CategoryHost:
+				categoryFilter = fmt.Sprintf("AND hostName() = '%s'", categoryValue)
+			case CategoryError:
+				parts := strings.Split(categoryValue, ":")
+				categoryFilter = fmt.Sprintf("AND errorCodeToName(exception_code)='%s' AND normalized_query_hash = %s", parts[0], parts[1])
+			}
+		}
+
+		query := fmt.Sprintf(
+			profileEventsQueryTemplate,
+			cluster,
+			fromStr, toStr, fromStr, toStr,
+			categoryFilter,
+		)
+
+		rows, err := a.clickHouse.Query(query)
+		if err != nil {
+			a.tviewApp.QueueUpdateDraw(func() {
+				a.mainView.SetText(fmt.Sprintf("Error executing query: %v\n%s", err, query))
+			})
+			return
+		}
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close ProfileEvents rows")
+			}
+		}()
+
+		// Create table to display results
+		a.tviewApp.QueueUpdateDraw(func() {
+			// Create filtered table widget
+			filteredTable := widgets.NewFilteredTable()
+			filteredTable.SetupHeaders([]string{"Event", "Count", "p50", "p90", "p99"})
+
+			// Create flex layout with table on left and query view on right
+			flex := tview.NewFlex().
+				SetDirection(tview.FlexColumn)
+
+			// Process rows
+			row := 1
+			for rows.Next() {
+				var (
+					event           string
+					count           int
+					p50             float64
+					p90             float64
+					p99             float64
+					p50s            string
+					p90s            string
+					p99s            string
+					normalizedQuery string
+				)
+
+				if err := rows.Scan(&event, &count, &p50, &p90, &p99, &p50s, &p90s, &p99s, &normalizedQuery); err != nil {
+					a.mainView.SetText(fmt.Sprintf("Error scanning row: %v", err))
+					return
+				}
+
+				// Determine cell colors based on percentile differences
+				color := tcell.ColorWhite
+				if p90 > 2*p50 || p99 > 2*p90 {
+					color = tcell.ColorYellow
+				}
+				if p90 > 4*p50 || p99 > 6*p50 {
+					color = tcell.ColorRed
+				}
+
+				// Add row to table
+				filteredTable.AddRow([]*tview.TableCell{
+					tview.NewTableCell(event).
+						SetTextColor(color).
+						SetAlign(tview.AlignLeft),
+					tview.NewTableCell(fmt.Sprintf("%d", count)).
+						SetTextColor(color).
+						SetAlign(tview.AlignRight),
+					tview.NewTableCell(p50s).
+						SetTextColor(color).
+						SetAlign(tview.AlignRight),
+					tview.NewTableCell(p90s).
+						SetTextColor(color).
+						SetAlign(tview.AlignRight),
+					tview.NewTableCell(p99s).
+						SetTextColor(color).
+						SetAlign(tview.AlignRight),
+					tview.NewTableCell(normalizedQuery),
+				})
+
+				row++
+			}
+
+			if err := rows.Err(); err != nil {
+				a.mainView.SetText(fmt.Sprintf("Error reading rows: %v", err))
+				return
+			}
+
+			// Set title
+			title := fmt.Sprintf("Profile Events: %s (%s to %s)",
+				categoryValue,
+				fromStr,
+				toStr)
+			filteredTable.Table.SetTitle(title).SetBorder(true)
+
+			// Add key handler for filtering table content
+			filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+				if event.Key() == tcell.KeyEscape {
+					if a.pages.HasPage("heatmap") {
+						a.pages.SwitchToPage("heatmap")
+					} else {
+						a.pages.SwitchToPage("main")
+					}
+					return nil
+				}
+				if filterHandler := filteredTable.GetInputCapture(a.tviewApp, a.pages); filterHandler != nil {
+					if result := filterHandler(event); result == nil {
+						return nil
+					}
+				}
+				if event.Key() == tcell.KeyEnter {
+					row, _ := filteredTable.Table.GetSelection()
+					eventName := filteredTable.Table.GetCell(row, 0).Text
+
+					// Query event description
+					go func() {
+						descrQuery := fmt.Sprintf("SELECT description FROM system.events WHERE name = '%s'", eventName)
+						descrRows, descrErr := a.clickHouse.Query(descrQuery)
+						if descrErr != nil {
+							a.tviewApp.QueueUpdateDraw(func() {
+								a.SwitchToMainPage(fmt.Sprintf("Error getting event description: %v", descrErr))
+							})
+							return
+						}
+						defer func() {
+							if closeErr := descrRows.Close(); closeErr != nil {
+								log.Error().Err(closeErr).Msg("can't close ProfileEvents descrRows")
+							}
+						}()
+
+						var description string
+						if descrRows.Next() {
+							if scanErr := rows.Scan(&description); scanErr != nil {
+								a.tviewApp.QueueUpdateDraw(func() {
+									a.mainView.SetText(fmt.Sprintf("Error scanning description: %v", scanErr))
+								})
+								return
+							}
+						}
+
+						// Show description in modal
+						a.tviewApp.QueueUpdateDraw(func() {
+							modal := tview.NewModal().
+								SetText(fmt.Sprintf("[yellow]%s[-]\n\n%s", eventName, description)).
+								AddButtons([]string{"OK"}).
+								SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+									a.pages.HidePage("event_desc")
+								})
+
+							a.pages.AddPage("event_desc", modal, true, true)
+						})
+					}()
+					return nil
+				}
+				return event
+			})
+
+			// Create query view widget
+			queryView := widgets.NewQueryView()
+
+			// Update query view when selection changes
+			filteredTable.Table.SetSelectionChangedFunc(func(row, column int) {
+				if row > 0 && row <= len(filteredTable.OriginalRows) {
+					rowData := filteredTable.OriginalRows[row]
+					if len(rowData) > 5 && rowData[5] != nil {
+						if normalizedQuery := rowData[5].Text; normalizedQuery != "" {
+							queryView.SetSQL(normalizedQuery)
+						}
+					}
+				}
+			})
+
+			// Add components to flex
+			flex.AddItem(filteredTable.Table, 0, 2, true).
+				AddItem(queryView, 0, 1, false)
+
+			a.pages.AddPage("profile_events", flex, true, true)
+			a.pages.SwitchToPage("profile_events")
+		})
+	}()
+}
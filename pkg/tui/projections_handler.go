@@ -0,0 +1,290 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// projectionCandidateMinReadRows is the read volume over the selected range
+// above which a table without any projection or skipping index is suggested
+// as a candidate for one
+const projectionCandidateMinReadRows = 100_000_000
+
+// projectionCandidateLimit caps how many candidate tables are listed
+const projectionCandidateLimit = 20
+
+// projectionUsageRow is one line of the analysis: a defined projection, a
+// defined data-skipping index, or a heavy table that has neither
+type projectionUsageRow struct {
+	kind    string
+	table   string
+	name    string
+	detail  string
+	bytes   float64
+	queries uint64
+	verdict string
+}
+
+// ShowProjectionUsage cross-references defined projections and data-skipping
+// indexes with their usage recorded in query_log over the selected range:
+// unused ones waste every merge that maintains them, and heavy tables without
+// either are listed as candidates
+func (a *App) ShowProjectionUsage() {
+	fromStr := a.fromTime.Format("2006-01-02 15:04:05 -07:00")
+	toStr := a.toTime.Format("2006-01-02 15:04:05 -07:00")
+
+	go func() {
+		projectionUsage := a.fetchUsageCounts(fmt.Sprintf(
+			"SELECT proj, count() AS cnt "+
+				"FROM clusterAllReplicas('%s', merge(system,'^query_log')) "+
+				"ARRAY JOIN projections AS proj "+
+				"WHERE event_date >= toDate(parseDateTimeBestEffort('%s')) AND event_date <= toDate(parseDateTimeBestEffort('%s')) "+
+				"AND event_time >= parseDateTimeBestEffort('%s') AND event_time <= parseDateTimeBestEffort('%s') "+
+				"AND type != 'QueryStart' "+
+				"GROUP BY proj SETTINGS skip_unavailable_shards=1",
+			a.cluster, fromStr, toStr, fromStr, toStr))
+
+		// older servers don't log skipping-index usage; nil means unknown,
+		// so defined indexes aren't falsely reported as unused
+		indexUsage := a.fetchUsageCounts(fmt.Sprintf(
+			"SELECT idx, count() AS cnt "+
+				"FROM clusterAllReplicas('%s', merge(system,'^query_log')) "+
+				"ARRAY JOIN used_data_skipping_indices AS idx "+
+				"WHERE event_date >= toDate(parseDateTimeBestEffort('%s')) AND event_date <= toDate(parseDateTimeBestEffort('%s')) "+
+				"AND event_time >= parseDateTimeBestEffort('%s') AND event_time <= parseDateTimeBestEffort('%s') "+
+				"AND type != 'QueryStart' "+
+				"GROUP BY idx SETTINGS skip_unavailable_shards=1",
+			a.cluster, fromStr, toStr, fromStr, toStr))
+
+		var analysis []projectionUsageRow
+		coveredTables := make(map[string]bool)
+
+		// Defined projections with their storage cost across the cluster
+		rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("projections"), fmt.Sprintf(
+			"SELECT database, table, name, sum(bytes_on_disk) AS bytes "+
+				"FROM clusterAllReplicas('%s', merge(system,'^projection_parts$')) "+
+				"WHERE active GROUP BY database, table, name "+
+				"SETTINGS skip_unavailable_shards=1", a.cluster))
+		if err != nil {
+			a.tviewApp.QueueUpdateDraw(func() {
+				a.SwitchToMainPage(fmt.Sprintf("ShowProjectionUsage query failed: %v", err))
+			})
+			return
+		}
+		for rows.Next() {
+			var database, table, name string
+			var bytes float64
+			if scanErr := rows.Scan(&database, &table, &name, &bytes); scanErr != nil {
+				log.Error().Err(scanErr).Msg("can't scan projection parts row")
+				continue
+			}
+			tableName := database + "." + table
+			coveredTables[tableName] = true
+			used := projectionUsage[tableName+"."+name]
+			verdict := fmt.Sprintf("used by %d queries", used)
+			if used == 0 {
+				verdict = "UNUSED in range, every merge still maintains it"
+			}
+			analysis = append(analysis, projectionUsageRow{
+				kind: "projection", table: tableName, name: name,
+				bytes: bytes, queries: used, verdict: verdict,
+			})
+		}
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close projection parts rows")
+		}
+
+		// Defined data-skipping indexes; usage is matched by index name, the
+		// granularity query_log records
+		rows, err = a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("projections"), fmt.Sprintf(
+			"SELECT database, table, name, any(type) AS index_type, sum(data_compressed_bytes) AS bytes "+
+				"FROM clusterAllReplicas('%s', merge(system,'^data_skipping_indices$')) "+
+				"GROUP BY database, table, name "+
+				"SETTINGS skip_unavailable_shards=1", a.cluster))
+		if err == nil {
+			for rows.Next() {
+				var database, table, name, indexType string
+				var bytes float64
+				if scanErr := rows.Scan(&database, &table, &name, &indexType, &bytes); scanErr != nil {
+					log.Error().Err(scanErr).Msg("can't scan skipping index row")
+					continue
+				}
+				tableName := database + "." + table
+				coveredTables[tableName] = true
+				verdict := "usage not recorded by this server version"
+				var used uint64
+				if indexUsage != nil {
+					used = indexUsage[name]
+					verdict = fmt.Sprintf("used by %d queries", used)
+					if used == 0 {
+						verdict = "UNUSED in range, every merge still maintains it"
+					}
+				}
+				analysis = append(analysis, projectionUsageRow{
+					kind: "skip index", table: tableName, name: name, detail: indexType,
+					bytes: bytes, queries: used, verdict: verdict,
+				})
+			}
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msg("can't close skipping index rows")
+			}
+		} else {
+			log.Warn().Err(err).Msg("can't fetch data_skipping_indices")
+		}
+
+		analysis = append(analysis, a.fetchProjectionCandidates(fromStr, toStr, coveredTables)...)
+
+		a.tviewApp.QueueUpdateDraw(func() {
+			a.renderProjectionUsage(analysis)
+		})
+	}()
+}
+
+// fetchUsageCounts runs a name->count aggregation over query_log; nil means
+// the query failed (e.g. the column doesn't exist on this server version),
+// which callers treat as "usage unknown" rather than zero
+func (a *App) fetchUsageCounts(query string) map[string]uint64 {
+	rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("projections"), query)
+	if err != nil {
+		log.Warn().Err(err).Msg("can't fetch usage counts")
+		return nil
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close fetchUsageCounts rows")
+		}
+	}()
+	usage := make(map[string]uint64)
+	for rows.Next() {
+		var name string
+		var count uint64
+		if scanErr := rows.Scan(&name, &count); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan usage count row")
+			continue
+		}
+		usage[name] = count
+	}
+	return usage
+}
+
+// fetchProjectionCandidates lists the heaviest-read tables of the range that
+// define neither a projection nor a skipping index, so the analysis also
+// answers "where would one help" and not only "which one is dead weight"
+func (a *App) fetchProjectionCandidates(fromStr, toStr string, coveredTables map[string]bool) []projectionUsageRow {
+	query := fmt.Sprintf(
+		"SELECT tbl, count() AS queries, sum(read_rows) AS read_rows "+
+			"FROM clusterAllReplicas('%s', merge(system,'^query_log')) "+
+			"ARRAY JOIN tables AS tbl "+
+			"WHERE event_date >= toDate(parseDateTimeBestEffort('%s')) AND event_date <= toDate(parseDateTimeBestEffort('%s')) "+
+			"AND event_time >= parseDateTimeBestEffort('%s') AND event_time <= parseDateTimeBestEffort('%s') "+
+			"AND type != 'QueryStart' AND tbl NOT LIKE 'system.%%' "+
+			"GROUP BY tbl ORDER BY read_rows DESC LIMIT %d "+
+			"SETTINGS skip_unavailable_shards=1",
+		a.cluster, fromStr, toStr, fromStr, toStr, projectionCandidateLimit)
+
+	rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("projections"), query)
+	if err != nil {
+		log.Warn().Err(err).Msg("can't fetch projection candidates")
+		return nil
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close fetchProjectionCandidates rows")
+		}
+	}()
+
+	var candidates []projectionUsageRow
+	for rows.Next() {
+		var table string
+		var queries, readRows uint64
+		if scanErr := rows.Scan(&table, &queries, &readRows); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan projection candidate row")
+			continue
+		}
+		if coveredTables[table] || readRows < projectionCandidateMinReadRows {
+			continue
+		}
+		candidates = append(candidates, projectionUsageRow{
+			kind: "candidate", table: table,
+			detail: fmt.Sprintf("%s rows read", utils.FormatReadable(float64(readRows), 1)), queries: queries,
+			verdict: "heavy reads, no projection or skipping index defined",
+		})
+	}
+	return candidates
+}
+
+// renderProjectionUsage shows the analysis in a filtered table: unused
+// definitions first (they cost merges right now), then used ones, then the
+// candidate tables
+func (a *App) renderProjectionUsage(analysis []projectionUsageRow) {
+	kindOrder := map[string]int{"projection": 0, "skip index": 1, "candidate": 2}
+	sort.SliceStable(analysis, func(i, j int) bool {
+		unusedI := strings.HasPrefix(analysis[i].verdict, "UNUSED")
+		unusedJ := strings.HasPrefix(analysis[j].verdict, "UNUSED")
+		if unusedI != unusedJ {
+			return unusedI
+		}
+		if kindOrder[analysis[i].kind] != kindOrder[analysis[j].kind] {
+			return kindOrder[analysis[i].kind] < kindOrder[analysis[j].kind]
+		}
+		return analysis[i].bytes > analysis[j].bytes
+	})
+
+	filteredTable := widgets.NewFilteredTable()
+	filteredTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	filteredTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Projections and skipping indexes | %s - %s [yellow](Esc: return)[-]",
+			utils.FormatTimestampShort(a.fromTime), utils.FormatTimestampShort(a.toTime)))
+	filteredTable.SetupHeaders([]string{"Kind", "Table", "Name", "Type", "Size", "Queries", "Verdict"})
+
+	for _, row := range analysis {
+		color := currentTheme.ok
+		switch {
+		case strings.HasPrefix(row.verdict, "UNUSED"):
+			color = currentTheme.critical
+		case row.kind == "candidate":
+			color = currentTheme.warning
+		case row.queries == 0:
+			color = currentTheme.text
+		}
+		size := ""
+		if row.bytes > 0 {
+			size = utils.FormatReadable(row.bytes, 1) + "B"
+		}
+		filteredTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(row.kind).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(row.table).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(row.name).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(row.detail).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(size).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(fmt.Sprintf("%d", row.queries)).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(row.verdict).SetTextColor(color).SetAlign(tview.AlignLeft),
+		})
+	}
+
+	existingHandler := filteredTable.GetInputCapture(a.tviewApp, a.pages)
+	filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if result := existingHandler(event); result == nil {
+			return nil
+		}
+		if event.Key() == tcell.KeyEscape {
+			a.pages.RemovePage("projections")
+			a.SwitchToMainPage("Returned from :projections")
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("projections", filteredTable.Table, true, true)
+	a.pages.SwitchToPage("projections")
+	a.tviewApp.SetFocus(filteredTable.Table)
+}
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/Slach/clickhouse-timeline/pkg/client"
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showQueryLog lists the SQL statements the tool itself executed, newest
+// first, with timing and outcome, so tool slowness and the load put on the
+// server can be inspected without leaving the TUI; the full journal lives in
+// ~/.clickhouse-timeline/queries.log
+func (a *App) showQueryLog() {
+	filteredTable := widgets.NewFilteredTable()
+	filteredTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	filteredTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle("Executed SQL [yellow](newest first, Esc: return)[-]")
+	filteredTable.SetupHeaders([]string{"Time", "Duration", "Rows", "Status", "Query"})
+
+	records := client.RecentQueries()
+	for i := len(records) - 1; i >= 0; i-- {
+		record := records[i]
+		rowsText := "-"
+		if record.Rows >= 0 {
+			rowsText = fmt.Sprintf("%d", record.Rows)
+		}
+		status := "ok"
+		color := tcell.ColorWhite
+		if record.Error != "" {
+			status = record.Error
+			color = tcell.ColorRed
+		}
+		filteredTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(utils.FormatTimestamp(record.Time)).SetTextColor(color),
+			tview.NewTableCell(fmt.Sprintf("%.1fms", record.DurationMs)).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(rowsText).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(status).SetTextColor(color),
+			tview.NewTableCell(record.Query).SetTextColor(color),
+		})
+	}
+
+	existingHandler := filteredTable.GetInputCapture(a.tviewApp, a.pages)
+	filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if result := existingHandler(event); result == nil {
+			return nil
+		}
+		if event.Key() == tcell.KeyEscape {
+			a.pages.RemovePage("queryLog")
+			a.pages.SwitchToPage("main")
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("queryLog", filteredTable.Table, true, true)
+	a.pages.SwitchToPage("queryLog")
+	a.tviewApp.SetFocus(filteredTable.Table)
+}
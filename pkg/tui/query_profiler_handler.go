@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// queryProfilerLimit caps how many fingerprints are shown, sorted by total time
+const queryProfilerLimit = 200
+
+// fingerprintStat aggregates query_log rows of one normalized_query_hash
+// over the selected time range
+type fingerprintStat struct {
+	hash      string
+	count     uint64
+	totalMs   float64
+	avgMs     float64
+	readRows  uint64
+	readBytes uint64
+	memory    uint64
+	errorPct  float64
+	sample    string
+}
+
+// ShowQueryProfiler aggregates query_log by normalized_query_hash for the
+// selected range and shows count, durations, read volume, memory and error
+// rate per fingerprint; Enter jumps into the explain flow for that hash
+func (a *App) ShowQueryProfiler() {
+	fromStr := a.fromTime.Format("2006-01-02 15:04:05 -07:00")
+	toStr := a.toTime.Format("2006-01-02 15:04:05 -07:00")
+
+	query := fmt.Sprintf(
+		"SELECT toString(normalized_query_hash) AS hash, count() AS cnt, "+
+			"sum(query_duration_ms) AS total_ms, avg(query_duration_ms) AS avg_ms, "+
+			"sum(read_rows) AS read_rows, sum(read_bytes) AS read_bytes, "+
+			"max(memory_usage) AS memory, countIf(exception_code != 0) * 100 / count() AS error_pct, "+
+			"any(left(query, 120)) AS sample "+
+			"FROM clusterAllReplicas('%s', merge(system,'^query_log')) "+
+			"WHERE event_date >= toDate(parseDateTimeBestEffort('%s')) AND event_date <= toDate(parseDateTimeBestEffort('%s')) "+
+			"AND event_time >= parseDateTimeBestEffort('%s') AND event_time <= parseDateTimeBestEffort('%s') "+
+			"AND type != 'QueryStart' "+
+			"GROUP BY hash ORDER BY total_ms DESC LIMIT %d "+
+			"SETTINGS skip_unavailable_shards=1",
+		a.cluster, fromStr, toStr, fromStr, toStr, queryProfilerLimit)
+
+	go func() {
+		logComment := newPanelLogComment("queryProfiler")
+		rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), logComment, query)
+		if err != nil {
+			a.tviewApp.QueueUpdateDraw(func() {
+				a.SwitchToMainPage(fmt.Sprintf("ShowQueryProfiler query failed: %v", err))
+			})
+			return
+		}
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				log.Error().Err(closeErr).Msgf("can't close ShowQueryProfiler rows")
+			}
+		}()
+
+		var stats []*fingerprintStat
+		for rows.Next() {
+			stat := &fingerprintStat{}
+			if scanErr := rows.Scan(&stat.hash, &stat.count, &stat.totalMs, &stat.avgMs,
+				&stat.readRows, &stat.readBytes, &stat.memory, &stat.errorPct, &stat.sample); scanErr != nil {
+				log.Error().Err(scanErr).Msg("can't scan query profiler row")
+				continue
+			}
+			stats = append(stats, stat)
+		}
+
+		a.tviewApp.QueueUpdateDraw(func() {
+			a.renderQueryProfiler(stats)
+		})
+	}()
+}
+
+// renderQueryProfiler shows the per-fingerprint aggregates in a filtered
+// table sorted by total duration
+func (a *App) renderQueryProfiler(stats []*fingerprintStat) {
+	filteredTable := widgets.NewFilteredTable()
+	filteredTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	filteredTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Query fingerprints | %s - %s [yellow](Enter: explain, Esc: return)[-]",
+			utils.FormatTimestampShort(a.fromTime), utils.FormatTimestampShort(a.toTime)))
+	filteredTable.SetupHeaders([]string{"Count", "Total", "Avg ms", "Read rows", "Read bytes", "Memory", "Err %", "Query"})
+
+	for _, stat := range stats {
+		color := tcell.ColorWhite
+		switch {
+		case stat.errorPct >= 50:
+			color = tcell.ColorRed
+		case stat.errorPct > 0:
+			color = tcell.ColorYellow
+		}
+		filteredTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(fmt.Sprintf("%d", stat.count)).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(fmt.Sprintf("%ss", utils.FormatReadable(stat.totalMs/1000, 1))).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(fmt.Sprintf("%.1f", stat.avgMs)).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(utils.FormatReadable(float64(stat.readRows), 1)).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(fmt.Sprintf("%sB", utils.FormatReadable(float64(stat.readBytes), 1))).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(fmt.Sprintf("%sB", utils.FormatReadable(float64(stat.memory), 1))).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(fmt.Sprintf("%.1f", stat.errorPct)).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(stat.sample).SetTextColor(color).SetAlign(tview.AlignLeft),
+		})
+	}
+
+	existingHandler := filteredTable.GetInputCapture(a.tviewApp, a.pages)
+	filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if result := existingHandler(event); result == nil {
+			return nil
+		}
+		switch event.Key() {
+		case tcell.KeyEnter:
+			row, _ := filteredTable.Table.GetSelection()
+			if row > 0 && row <= len(stats) {
+				a.pages.RemovePage("queryProfiler")
+				a.ShowExplain(CategoryQueryHash, stats[row-1].hash, a.fromTime, a.toTime, a.cluster)
+			}
+			return nil
+		case tcell.KeyEscape:
+			a.pages.RemovePage("queryProfiler")
+			a.SwitchToMainPage("Returned from :profiler")
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("queryProfiler", filteredTable.Table, true, true)
+	a.pages.SwitchToPage("queryProfiler")
+	a.tviewApp.SetFocus(filteredTable.Table)
+}
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/rs/zerolog/log"
+)
+
+// buildQueryViewsReport renders the materialized view chain for a single INSERT
+// from system.query_views_log, so the guilty MV is visible without handwritten SQL.
+func (a *App) buildQueryViewsReport(queryID, fromStr, toStr, cluster string) string {
+	q := fmt.Sprintf(
+		"SELECT view_name, view_type, status, view_duration_ms, read_rows, written_rows, peak_memory_usage FROM clusterAllReplicas('%s', merge(system,'^query_views_log')) WHERE initial_query_id = '%s' AND event_date >= toDate(parseDateTimeBestEffort('%s')) AND event_date <= toDate(parseDateTimeBestEffort('%s')) ORDER BY view_duration_ms DESC",
+		cluster, strings.ReplaceAll(queryID, "'", "''"), fromStr, toStr,
+	)
+
+	rows, err := a.clickHouse.Query(q)
+	if err != nil {
+		return fmt.Sprintf("Error fetching query_views_log: %v\n%s", err, q)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close query_views_log rows")
+		}
+	}()
+
+	type viewRec struct {
+		name       string
+		viewType   string
+		status     string
+		durationMs uint64
+		readRows   uint64
+		written    uint64
+		peakMemory int64
+	}
+	var views []viewRec
+	for rows.Next() {
+		var r viewRec
+		if scanErr := rows.Scan(&r.name, &r.viewType, &r.status, &r.durationMs, &r.readRows, &r.written, &r.peakMemory); scanErr != nil {
+			log.Error().Err(scanErr).Msg("scan query_views_log row")
+			continue
+		}
+		views = append(views, r)
+	}
+
+	if len(views) == 0 {
+		return "No materialized views found in system.query_views_log for this query\n(check that log_query_views=1 is enabled)"
+	}
+
+	// Compute column widths for a compact aligned table
+	w0 := len("view_name")
+	w1 := len("duration")
+	w2 := len("read_rows")
+	w3 := len("written_rows")
+	w4 := len("peak_memory")
+	for _, v := range views {
+		if len(v.name) > w0 {
+			w0 = len(v.name)
+		}
+		if l := len(fmt.Sprintf("%d ms", v.durationMs)); l > w1 {
+			w1 = l
+		}
+		if l := len(utils.FormatReadable(float64(v.readRows), 0)); l > w2 {
+			w2 = l
+		}
+		if l := len(utils.FormatReadable(float64(v.written), 0)); l > w3 {
+			w3 = l
+		}
+		if l := len(utils.FormatReadable(float64(v.peakMemory), 1)); l > w4 {
+			w4 = l
+		}
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-*s  %*s  %*s  %*s  %*s\n", w0, "view_name", w1, "duration", w2, "read_rows", w3, "written_rows", w4, "peak_memory")
+	fmt.Fprintf(&buf, "%s\n", strings.Repeat("-", w0+2+w1+2+w2+2+w3+2+w4))
+	for _, v := range views {
+		name := v.name
+		if v.status != "QueryFinish" {
+			name = fmt.Sprintf("[red]%s (%s)[-]", v.name, v.status)
+		}
+		fmt.Fprintf(&buf, "%-*s  %*s  %*s  %*s  %*s\n",
+			w0, name,
+			w1, fmt.Sprintf("%d ms", v.durationMs),
+			w2, utils.FormatReadable(float64(v.readRows), 0),
+			w3, utils.FormatReadable(float64(v.written), 0),
+			w4, utils.FormatReadable(float64(v.peakMemory), 1),
+		)
+	}
+	return buf.String()
+}
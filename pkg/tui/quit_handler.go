@@ -0,0 +1,5 @@
+package tui
+
+func (a *App) handleQuitCommand() {
+	a.tviewApp.Stop()
+}
@@ -0,0 +1,247 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// replicationRefreshInterval is how often system.replicas is re-polled while
+// the dashboard is open
+const replicationRefreshInterval = 5 * time.Second
+
+// replicationHistoryLen is how many samples the per-replica delay sparkline
+// keeps (60 * 5s = 5 minutes)
+const replicationHistoryLen = 60
+
+// replicaLagRow is the state of one replica in one poll
+type replicaLagRow struct {
+	host          string
+	database      string
+	table         string
+	absoluteDelay float64
+	queueSize     uint64
+	isReadonly    uint8
+	// relativeDelay is computed client-side: own delay minus the smallest
+	// delay among replicas of the same table, i.e. how far this replica
+	// trails the healthiest one
+	relativeDelay float64
+}
+
+// replicationLagSeverity reuses the audit thresholds (A1.6 for queue size,
+// A1.6.1 for delays) so the dashboard colors match a full audit run
+func replicationLagSeverity(delay float64, queueSize uint64) string {
+	severity := ""
+	switch {
+	case delay > 24*3600:
+		severity = "Critical"
+	case delay > 2*3600:
+		severity = "Major"
+	case delay > 1800:
+		severity = "Moderate"
+	case delay > 300:
+		severity = "Minor"
+	}
+	queueSeverity := ""
+	switch {
+	case queueSize > 500:
+		queueSeverity = "Critical"
+	case queueSize > 400:
+		queueSeverity = "Major"
+	case queueSize > 200:
+		queueSeverity = "Moderate"
+	case queueSize > 100:
+		queueSeverity = "Minor"
+	}
+	if severityRank(queueSeverity) > severityRank(severity) {
+		return queueSeverity
+	}
+	return severity
+}
+
+// severityRank orders audit severities so the worse of two can be picked
+func severityRank(severity string) int {
+	switch severity {
+	case "Critical":
+		return 4
+	case "Major":
+		return 3
+	case "Moderate":
+		return 2
+	case "Minor":
+		return 1
+	}
+	return 0
+}
+
+// severityColor matches the color coding used in the audit results table,
+// mapped through the active theme
+func severityColor(severity string) tcell.Color {
+	switch severity {
+	case "Critical":
+		return currentTheme.critical
+	case "Major":
+		return currentTheme.major
+	case "Moderate":
+		return currentTheme.warning
+	case "Minor":
+		return currentTheme.ok
+	}
+	return currentTheme.text
+}
+
+// ShowReplicationLag opens a dashboard that periodically polls system.replicas
+// across the cluster and shows absolute/relative delay and queue size per
+// replica, with a sparkline of the absolute delay collected while the page is
+// open; colors reuse the audit severity thresholds so replication issues are
+// visible without running a full audit
+func (a *App) ShowReplicationLag() {
+	filteredTable := widgets.NewFilteredTable()
+	filteredTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	filteredTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Replication lag | refresh every %s [yellow](Esc: return)[-]", replicationRefreshInterval))
+	filteredTable.SetupHeaders([]string{"Host", "Table", "Absolute", "Relative", "Queue", "RO", "Delay trend"})
+
+	stop := make(chan struct{})
+	stopped := false
+	existingHandler := filteredTable.GetInputCapture(a.tviewApp, a.pages)
+	filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if result := existingHandler(event); result == nil {
+			return nil
+		}
+		if event.Key() == tcell.KeyEscape {
+			if !stopped {
+				stopped = true
+				close(stop)
+			}
+			a.pages.RemovePage("replicationLag")
+			a.SwitchToMainPage("Returned from :replication")
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("replicationLag", filteredTable.Table, true, true)
+	a.pages.SwitchToPage("replicationLag")
+	a.tviewApp.SetFocus(filteredTable.Table)
+
+	go func() {
+		// delay history per host|database.table, trimmed to replicationHistoryLen
+		history := make(map[string][]float64)
+		for {
+			snapshot := a.fetchReplicationSnapshot()
+			for i := range snapshot {
+				key := snapshot[i].host + "|" + snapshot[i].database + "." + snapshot[i].table
+				values := append(history[key], snapshot[i].absoluteDelay)
+				if len(values) > replicationHistoryLen {
+					values = values[len(values)-replicationHistoryLen:]
+				}
+				history[key] = values
+			}
+			a.tviewApp.QueueUpdateDraw(func() {
+				a.renderReplicationLag(filteredTable, snapshot, history)
+			})
+			select {
+			case <-stop:
+				return
+			case <-time.After(replicationRefreshInterval):
+			}
+		}
+	}()
+}
+
+// fetchReplicationSnapshot loads the replica states across the cluster and
+// computes each replica's delay relative to the healthiest replica of the
+// same table
+func (a *App) fetchReplicationSnapshot() []replicaLagRow {
+	query := fmt.Sprintf(
+		"SELECT hostName() AS host, database, table, toFloat64(absolute_delay) AS absolute_delay, "+
+			"toUInt64(queue_size) AS queue_size, is_readonly "+
+			"FROM clusterAllReplicas('%s', merge(system,'^replicas$')) "+
+			"SETTINGS skip_unavailable_shards=1",
+		a.cluster)
+
+	rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("replicationLag"), query)
+	if err != nil {
+		log.Error().Err(err).Msg("can't fetch replication snapshot")
+		return nil
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close fetchReplicationSnapshot rows")
+		}
+	}()
+
+	var snapshot []replicaLagRow
+	minDelay := make(map[string]float64)
+	for rows.Next() {
+		var row replicaLagRow
+		if scanErr := rows.Scan(&row.host, &row.database, &row.table, &row.absoluteDelay, &row.queueSize, &row.isReadonly); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan replication snapshot row")
+			continue
+		}
+		tableKey := row.database + "." + row.table
+		if best, found := minDelay[tableKey]; !found || row.absoluteDelay < best {
+			minDelay[tableKey] = row.absoluteDelay
+		}
+		snapshot = append(snapshot, row)
+	}
+	for i := range snapshot {
+		snapshot[i].relativeDelay = snapshot[i].absoluteDelay - minDelay[snapshot[i].database+"."+snapshot[i].table]
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].absoluteDelay != snapshot[j].absoluteDelay {
+			return snapshot[i].absoluteDelay > snapshot[j].absoluteDelay
+		}
+		if snapshot[i].queueSize != snapshot[j].queueSize {
+			return snapshot[i].queueSize > snapshot[j].queueSize
+		}
+		return snapshot[i].host+snapshot[i].database+snapshot[i].table < snapshot[j].host+snapshot[j].database+snapshot[j].table
+	})
+	return snapshot
+}
+
+// renderReplicationLag repopulates the dashboard table from the latest poll,
+// keeping the current selection where possible
+func (a *App) renderReplicationLag(filteredTable *widgets.FilteredTable, snapshot []replicaLagRow, history map[string][]float64) {
+	selectedRow, selectedCol := filteredTable.Table.GetSelection()
+	filteredTable.Table.Clear()
+	filteredTable.OriginalRows = nil
+	filteredTable.SetupHeaders(filteredTable.Headers)
+
+	for _, row := range snapshot {
+		severity := replicationLagSeverity(row.absoluteDelay, row.queueSize)
+		color := severityColor(severity)
+		readonly := ""
+		if row.isReadonly != 0 {
+			readonly = "RO"
+			if color == tcell.ColorWhite {
+				color = tcell.ColorRed
+			}
+		}
+		key := row.host + "|" + row.database + "." + row.table
+		filteredTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(row.host).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(row.database + "." + row.table).SetTextColor(color).SetAlign(tview.AlignLeft),
+			tview.NewTableCell(fmt.Sprintf("%ss", utils.FormatReadable(row.absoluteDelay, 1))).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(fmt.Sprintf("%ss", utils.FormatReadable(row.relativeDelay, 1))).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(fmt.Sprintf("%d", row.queueSize)).SetTextColor(color).SetAlign(tview.AlignRight),
+			tview.NewTableCell(readonly).SetTextColor(color).SetAlign(tview.AlignCenter),
+			tview.NewTableCell(a.GenerateSparkline(history[key])).SetTextColor(color).SetAlign(tview.AlignLeft),
+		})
+	}
+
+	if selectedRow > 0 && selectedRow < filteredTable.Table.GetRowCount() {
+		filteredTable.Table.Select(selectedRow, selectedCol)
+	} else if filteredTable.Table.GetRowCount() > 1 {
+		filteredTable.Table.Select(1, 0)
+	}
+}
@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// getServerRestarts detects server restarts in the given time range as Uptime
+// resets in system.asynchronous_metric_log. Many timeline mysteries are simply
+// "the server restarted here", so time-based views mark these moments.
+func (a *App) getServerRestarts(fromTime, toTime time.Time, cluster string) []time.Time {
+	fromStr := fromTime.Format("2006-01-02 15:04:05 -07:00")
+	toStr := toTime.Format("2006-01-02 15:04:05 -07:00")
+
+	query := fmt.Sprintf(`
+SELECT event_time FROM (
+    SELECT
+        hostName() AS h,
+        event_time,
+        value,
+        lagInFrame(value, 1, value) OVER (PARTITION BY h ORDER BY event_time) AS prev_value
+    FROM clusterAllReplicas('%s', merge(system,'^asynchronous_metric_log'))
+    WHERE metric = 'Uptime'
+      AND event_date >= toDate(parseDateTimeBestEffort('%s'))
+      AND event_date <= toDate(parseDateTimeBestEffort('%s'))
+      AND event_time >= parseDateTimeBestEffort('%s')
+      AND event_time <= parseDateTimeBestEffort('%s')
+) WHERE value < prev_value ORDER BY event_time`,
+		cluster, fromStr, toStr, fromStr, toStr)
+
+	rows, err := a.clickHouse.Query(query)
+	if err != nil {
+		log.Error().Err(err).Msg("can't detect server restarts")
+		return nil
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("can't close server restarts rows")
+		}
+	}()
+
+	var restarts []time.Time
+	for rows.Next() {
+		var t time.Time
+		if scanErr := rows.Scan(&t); scanErr != nil {
+			log.Error().Err(scanErr).Msg("scan server restart row")
+			continue
+		}
+		restarts = append(restarts, t)
+	}
+	return restarts
+}
+
+// restartInBucket reports whether any restart falls into [bucketStart, bucketEnd)
+func restartInBucket(restarts []time.Time, bucketStart, bucketEnd time.Time) bool {
+	for _, restart := range restarts {
+		if !restart.Before(bucketStart) && restart.Before(bucketEnd) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatRestartsSuffix returns a title suffix describing detected restarts
+func formatRestartsSuffix(restarts []time.Time) string {
+	if len(restarts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" | [red]↻ %d server restart(s)[-]", len(restarts))
+}
@@ -0,0 +1,274 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func (a *App) ExecuteAndProcessSparklineQuery(query string, prefix string, fields []string, filteredTable *widgets.FilteredTable, row *int) error {
+	rows, err := a.clickHouse.Query(query)
+	if err != nil {
+		return fmt.Errorf("error executing %s query: %v", prefix, err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			a.mainView.SetText(fmt.Sprintf("can't close %s rows", prefix))
+		}
+	}()
+
+	// Store results for display
+	results := make(map[string][]float64)
+	for rows.Next() {
+		switch prefix {
+		case "AsynchronousMetric":
+			// Handle metrics which returns name, array(tuple(time,value))
+			var name string
+			var timeValue [][]interface{}
+
+			if scanErr := rows.Scan(&name, &timeValue); scanErr != nil {
+				return fmt.Errorf("error scanning %s row: %v", prefix, scanErr)
+			}
+
+			// Extract values
+			var values []float64
+			for _, tv := range timeValue {
+				if len(tv) >= 2 {
+					if val, ok := tv[1].(float64); ok {
+						values = append(values, val)
+					}
+				}
+			}
+			alias := "A_" + name
+			results[alias] = values
+		case "CurrentMetric":
+			// Handle metrics which returns multiple array(tuple(time,value)) for each field
+			valuePtrs := make([]interface{}, len(fields))
+			values := make([]*[][]interface{}, len(fields))
+			for i := range values {
+				values[i] = new([][]interface{})
+				valuePtrs[i] = values[i]
+			}
+
+			if scanErr := rows.Scan(valuePtrs...); scanErr != nil {
+				return fmt.Errorf("error scanning %s row: %v", prefix, scanErr)
+			}
+
+			for i, field := range fields {
+				alias := "M_" + strings.TrimPrefix(field, prefix+"_")
+				for _, tuple := range *values[i] {
+					if len(tuple) >= 2 {
+						if val, ok := tuple[1].(float64); ok {
+							results[alias] = append(results[alias], val)
+						}
+					}
+				}
+			}
+
+		case "ProfileEvent":
+			// Handle ProfileEvent which returns bucket_time + multiple sum()
+			values := make([]float64, len(fields))
+			valuePtrs := make([]interface{}, len(fields)+1)
+			for i := range values {
+				valuePtrs[i+1] = &values[i]
+			}
+			var bucketTime time.Time
+			valuePtrs[0] = &bucketTime
+			if scanErr := rows.Scan(valuePtrs...); scanErr != nil {
+				return fmt.Errorf("error scanning %s row: %v", prefix, scanErr)
+			}
+
+			for i, field := range fields {
+				alias := "P_" + strings.TrimPrefix(field, prefix+"_")
+				results[alias] = append(results[alias], values[i])
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading %s rows: %v", prefix, err)
+	}
+
+	// Add results to display table
+	for name, values := range results {
+		if len(values) == 0 {
+			continue
+		}
+
+		minVal := values[0]
+		maxVal := values[0]
+		for _, v := range values {
+			if v < minVal {
+				minVal = v
+			}
+			if v > maxVal {
+				maxVal = v
+			}
+		}
+
+		sparkline := a.GenerateSparkline(values)
+		color := tcell.ColorWhite
+		if maxVal > 2*minVal {
+			color = tcell.ColorYellow
+		}
+		if maxVal > 4*minVal {
+			color = tcell.ColorRed
+		}
+
+		displayName := name
+		if prefix != "AsynchronousMetric" {
+			displayName = strings.TrimPrefix(name, prefix+"_")
+		}
+
+		filteredTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(displayName).
+				SetTextColor(color).
+				SetAlign(tview.AlignLeft),
+			tview.NewTableCell(fmt.Sprintf("%.1f", minVal)).
+				SetTextColor(color).
+				SetAlign(tview.AlignRight),
+			tview.NewTableCell(sparkline).
+				SetTextColor(color).
+				SetAlign(tview.AlignLeft),
+			tview.NewTableCell(fmt.Sprintf("%.1f", maxVal)).
+				SetTextColor(color).
+				SetAlign(tview.AlignLeft),
+		})
+
+		*row++
+	}
+	return nil
+}
+
+// Sparkline y-axis scale strategies: linear min/max, log1p for bursty series
+// where a single spike flattens everything else, and share-of-total
+const (
+	SparklineScaleLinear  = "linear"
+	SparklineScaleLog     = "log"
+	SparklineScalePercent = "percent"
+)
+
+func (a *App) GenerateSparkline(values []float64) string {
+	return a.GenerateSparklineScaled(values, SparklineScaleLinear)
+}
+
+// nextSparklineScale cycles linear -> log -> percent for scale toggles
+func nextSparklineScale(scale string) string {
+	switch scale {
+	case SparklineScaleLinear:
+		return SparklineScaleLog
+	case SparklineScaleLog:
+		return SparklineScalePercent
+	default:
+		return SparklineScaleLinear
+	}
+}
+
+// GenerateSparklineScaled renders values as bar-height runes with the given
+// y-axis scale strategy; "log" keeps low-volume buckets visible next to
+// spikes, "percent" sizes each bucket by its share of the series total
+func (a *App) GenerateSparklineScaled(values []float64, scale string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	scaled := values
+	switch scale {
+	case SparklineScaleLog:
+		scaled = make([]float64, len(values))
+		for i, v := range values {
+			scaled[i] = math.Log1p(v)
+		}
+	case SparklineScalePercent:
+		total := 0.0
+		for _, v := range values {
+			total += v
+		}
+		scaled = make([]float64, len(values))
+		if total > 0 {
+			for i, v := range values {
+				scaled[i] = v / total
+			}
+		}
+	}
+
+	minVal := scaled[0]
+	maxVal := scaled[0]
+	for _, v := range scaled {
+		if v < minVal {
+			minVal = v
+		}
+		if v > maxVal {
+			maxVal = v
+		}
+	}
+
+	rangeVal := maxVal - minVal
+	if rangeVal == 0 {
+		rangeVal = 1
+	}
+
+	sparks := []rune("▁▂▃▄▅▆▇█")
+	var result strings.Builder
+	for _, v := range scaled {
+		pos := int(((v - minVal) / rangeVal) * float64(len(sparks)-1))
+		if pos < 0 {
+			pos = 0
+		}
+		if pos >= len(sparks) {
+			pos = len(sparks) - 1
+		}
+		result.WriteRune(sparks[pos])
+	}
+	return result.String()
+}
+
+// GenerateHeatStripe renders values as background intensity instead of bar
+// height; unlike the min/max-scaled sparkline a single extreme bucket keeps
+// its full brightness, so short bursts stay visible next to smoothed counts
+func (a *App) GenerateHeatStripe(values []float64) string {
+	maxVal := 0.0
+	for _, v := range values {
+		if v > maxVal {
+			maxVal = v
+		}
+	}
+
+	shades := []string{"#262626", "#5f0000", "#870000", "#af0000", "#d70000", "#ff0000"}
+	var result strings.Builder
+	for _, v := range values {
+		pos := 0
+		if maxVal > 0 {
+			pos = int(v / maxVal * float64(len(shades)-1))
+		}
+		if pos < 0 {
+			pos = 0
+		}
+		if pos >= len(shades) {
+			pos = len(shades) - 1
+		}
+		result.WriteString(fmt.Sprintf("[:%s] ", shades[pos]))
+	}
+	if len(values) > 0 {
+		result.WriteString("[-:-]")
+	}
+	return result.String()
+}
+
+func (a *App) ShowDescription(name, description string) {
+	a.tviewApp.QueueUpdateDraw(func() {
+		modal := tview.NewModal().
+			SetText(fmt.Sprintf("[yellow]%s[-]\n\n%s", name, description)).
+			AddButtons([]string{"OK"}).
+			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+				a.pages.HidePage("metric_desc")
+			})
+
+		a.pages.AddPage("metric_desc", modal, true, true)
+	})
+}
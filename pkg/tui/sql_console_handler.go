@@ -0,0 +1,269 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// sqlConsoleLimit is appended to SELECT queries without an explicit LIMIT so
+// an accidental full-table query doesn't flood the terminal
+const sqlConsoleLimit = 1000
+
+// sqlHistoryLimit caps how many queries the on-disk history keeps
+const sqlHistoryLimit = 100
+
+// sqlHistoryPath returns ~/.clickhouse-timeline/sql-history.yaml
+func sqlHistoryPath() string {
+	home, homeErr := os.UserHomeDir()
+	if homeErr != nil {
+		log.Error().Err(homeErr).Msg("can't detect home directory for sql history")
+		return ""
+	}
+	return filepath.Join(home, ".clickhouse-timeline", "sql-history.yaml")
+}
+
+// loadSQLHistory reads the persisted query history, a missing file is not an error
+func loadSQLHistory() []string {
+	path := sqlHistoryPath()
+	if path == "" {
+		return nil
+	}
+	data, readErr := os.ReadFile(path)
+	if readErr != nil {
+		if !os.IsNotExist(readErr) {
+			log.Error().Err(readErr).Str("path", path).Msg("can't read sql history")
+		}
+		return nil
+	}
+	var history []string
+	if unmarshalErr := yaml.Unmarshal(data, &history); unmarshalErr != nil {
+		log.Error().Err(unmarshalErr).Str("path", path).Msg("can't parse sql history")
+		return nil
+	}
+	return history
+}
+
+// appendSQLHistory persists a query at the end of the history, dropping an
+// earlier duplicate and trimming the history to sqlHistoryLimit entries
+func appendSQLHistory(query string) []string {
+	history := loadSQLHistory()
+	kept := make([]string, 0, len(history)+1)
+	for _, entry := range history {
+		if entry != query {
+			kept = append(kept, entry)
+		}
+	}
+	kept = append(kept, query)
+	if len(kept) > sqlHistoryLimit {
+		kept = kept[len(kept)-sqlHistoryLimit:]
+	}
+
+	path := sqlHistoryPath()
+	if path == "" {
+		return kept
+	}
+	data, marshalErr := yaml.Marshal(kept)
+	if marshalErr != nil {
+		log.Error().Err(marshalErr).Msg("can't serialize sql history")
+		return kept
+	}
+	if mkdirErr := os.MkdirAll(filepath.Dir(path), 0755); mkdirErr != nil {
+		log.Error().Err(mkdirErr).Str("path", path).Msg("can't create sql history directory")
+		return kept
+	}
+	if writeErr := os.WriteFile(path, data, 0644); writeErr != nil {
+		log.Error().Err(writeErr).Str("path", path).Msg("can't write sql history")
+	}
+	return kept
+}
+
+// enforceSQLLimit appends LIMIT to read queries without an explicit one
+func enforceSQLLimit(query string) string {
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	upper := strings.ToUpper(query)
+	isRead := strings.HasPrefix(upper, "SELECT") || strings.HasPrefix(upper, "WITH") || strings.HasPrefix(upper, "SHOW")
+	if isRead && !regexp.MustCompile(`(?i)\bLIMIT\s+\d`).MatchString(query) {
+		query += fmt.Sprintf(" LIMIT %d", sqlConsoleLimit)
+	}
+	return query
+}
+
+// formatSQLValue renders one scanned cell for display
+func formatSQLValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05")
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// ShowSQLConsole opens an ad-hoc query editor with results rendered below;
+// Ctrl+Enter (or F5) executes, Ctrl+P/Ctrl+N walk the on-disk query history,
+// SELECTs without LIMIT get one appended
+func (a *App) ShowSQLConsole() {
+	statusText := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(fmt.Sprintf("Ctrl+Enter/F5: execute (LIMIT %d enforced), Ctrl+P/Ctrl+N: history, Tab: results, Esc: return", sqlConsoleLimit))
+
+	queryArea := tview.NewTextArea().
+		SetPlaceholder("SELECT ... FROM ... WHERE ...")
+	queryArea.SetBorder(true).SetTitle("SQL")
+
+	resultsTable := widgets.NewFilteredTable()
+	resultsTable.Table.SetBorders(false).
+		SetSelectable(true, true).
+		SetFixed(1, 0)
+	resultsTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).SetTitle("Results")
+
+	consoleFlex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(statusText, 1, 0, false).
+		AddItem(queryArea, 0, 1, true).
+		AddItem(resultsTable.Table, 0, 2, false)
+
+	history := loadSQLHistory()
+	// historyPos == len(history) means "past the newest entry", i.e. editing a new query
+	historyPos := len(history)
+
+	closeConsole := func() {
+		a.pages.RemovePage("sqlConsole")
+		a.SwitchToMainPage("Returned from :sql")
+	}
+
+	execute := func() {
+		query := enforceSQLLimit(queryArea.GetText())
+		if query == "" {
+			statusText.SetText("[red]Query is empty[-]")
+			return
+		}
+		history = appendSQLHistory(query)
+		historyPos = len(history)
+		statusText.SetText("[yellow]Running query...[-]")
+		go a.runSQLConsoleQuery(query, statusText, resultsTable)
+	}
+
+	queryArea.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if (event.Key() == tcell.KeyEnter && event.Modifiers()&tcell.ModCtrl != 0) || event.Key() == tcell.KeyF5 {
+			execute()
+			return nil
+		}
+		switch event.Key() {
+		case tcell.KeyCtrlP:
+			if historyPos > 0 {
+				historyPos--
+				queryArea.SetText(history[historyPos], true)
+			}
+			return nil
+		case tcell.KeyCtrlN:
+			if historyPos < len(history)-1 {
+				historyPos++
+				queryArea.SetText(history[historyPos], true)
+			} else if historyPos == len(history)-1 {
+				historyPos++
+				queryArea.SetText("", true)
+			}
+			return nil
+		case tcell.KeyTab:
+			a.tviewApp.SetFocus(resultsTable.Table)
+			return nil
+		case tcell.KeyEscape:
+			closeConsole()
+			return nil
+		}
+		return event
+	})
+
+	existingHandler := resultsTable.GetInputCapture(a.tviewApp, a.pages)
+	resultsTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if result := existingHandler(event); result == nil {
+			return nil
+		}
+		if event.Key() == tcell.KeyTab || event.Key() == tcell.KeyBacktab {
+			a.tviewApp.SetFocus(queryArea)
+			return nil
+		} else if event.Key() == tcell.KeyEscape {
+			closeConsole()
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("sqlConsole", consoleFlex, true, true)
+	a.pages.SwitchToPage("sqlConsole")
+	a.tviewApp.SetFocus(queryArea)
+}
+
+// runSQLConsoleQuery executes one console query and renders the result set
+// with the response columns as table headers
+func (a *App) runSQLConsoleQuery(query string, statusText *tview.TextView, resultsTable *widgets.FilteredTable) {
+	started := time.Now()
+	rows, err := a.clickHouse.QueryWithLogComment(a.queryContext(), newPanelLogComment("sqlConsole"), query)
+	if err != nil {
+		a.tviewApp.QueueUpdateDraw(func() {
+			statusText.SetText(fmt.Sprintf("[red]Query failed: %v[-]", err))
+		})
+		return
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close runSQLConsoleQuery rows")
+		}
+	}()
+
+	columns, columnsErr := rows.Columns()
+	if columnsErr != nil {
+		a.tviewApp.QueueUpdateDraw(func() {
+			statusText.SetText(fmt.Sprintf("[red]Can't read result columns: %v[-]", columnsErr))
+		})
+		return
+	}
+
+	var resultRows [][]string
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if scanErr := rows.Scan(pointers...); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan sql console row")
+			continue
+		}
+		cells := make([]string, len(columns))
+		for i, value := range values {
+			cells[i] = formatSQLValue(value)
+		}
+		resultRows = append(resultRows, cells)
+	}
+
+	a.tviewApp.QueueUpdateDraw(func() {
+		resultsTable.Table.Clear()
+		resultsTable.OriginalRows = nil
+		resultsTable.SetupHeaders(columns)
+		for _, cells := range resultRows {
+			tableCells := make([]*tview.TableCell, len(cells))
+			for i, cell := range cells {
+				tableCells[i] = tview.NewTableCell(cell).SetAlign(tview.AlignLeft)
+			}
+			resultsTable.AddRow(tableCells)
+		}
+		if resultsTable.Table.GetRowCount() > 1 {
+			resultsTable.Table.Select(1, 0)
+		}
+		statusText.SetText(fmt.Sprintf("[green]%d rows in %s[-] | Ctrl+Enter/F5: execute, Ctrl+P/Ctrl+N: history, Tab: editor, Esc: return",
+			len(resultRows), time.Since(started).Round(time.Millisecond)))
+	})
+}
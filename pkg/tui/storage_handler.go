@@ -0,0 +1,230 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Slach/clickhouse-timeline/pkg/chquery"
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+// storageBarWidth is how many cells the proportional size bar occupies
+const storageBarWidth = 30
+
+// storageRow is one entry of the current breakdown level
+type storageRow struct {
+	name              string
+	bytesOnDisk       uint64
+	uncompressedBytes uint64
+	parts             uint64
+}
+
+// storagePanel browses disk usage from system.parts level by level:
+// databases, then one database's tables, then one table's partitions
+type storagePanel struct {
+	app *App
+	// drill path: empty = databases, [db] = tables, [db, table] = partitions
+	path []string
+	// show data_uncompressed_bytes instead of bytes_on_disk
+	uncompressed bool
+}
+
+// ShowStorageBreakdown opens a browsable breakdown of bytes on disk by
+// database, table and partition with proportional bars, complementing the
+// audit's fixed thresholds with an explorable view; 'u' toggles between
+// compressed and uncompressed bytes
+func (a *App) ShowStorageBreakdown() {
+	panel := &storagePanel{app: a}
+	panel.load()
+}
+
+// load fetches the current level in the background and renders it
+func (sp *storagePanel) load() {
+	go func() {
+		rows, err := sp.fetchLevel()
+		sp.app.tviewApp.QueueUpdateDraw(func() {
+			if err != nil {
+				sp.app.SwitchToMainPage(fmt.Sprintf("ShowStorageBreakdown query failed: %v", err))
+				return
+			}
+			sp.render(rows)
+		})
+	}()
+}
+
+func (sp *storagePanel) fetchLevel() ([]storageRow, error) {
+	groupColumn := "database"
+	where := "active"
+	var args []interface{}
+	switch len(sp.path) {
+	case 1:
+		groupColumn = "table"
+		where += " AND database = ?"
+		args = append(args, sp.path[0])
+	case 2:
+		groupColumn = "partition"
+		where += " AND database = ? AND table = ?"
+		args = append(args, sp.path[0], sp.path[1])
+	}
+
+	query := fmt.Sprintf(
+		"SELECT toString(%s) AS name, sum(bytes_on_disk) AS bytes_on_disk, "+
+			"sum(data_uncompressed_bytes) AS uncompressed_bytes, count() AS parts "+
+			"FROM clusterAllReplicas('%s', merge(system,'^parts$')) "+
+			"WHERE %s GROUP BY name ORDER BY %s DESC "+
+			"SETTINGS skip_unavailable_shards=1",
+		groupColumn, chquery.EscapeString(sp.app.cluster), where, sp.metricColumn())
+
+	rows, err := sp.app.clickHouse.QueryWithLogComment(sp.app.queryContext(), newPanelLogComment("storage"), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msgf("can't close fetchLevel rows")
+		}
+	}()
+
+	var level []storageRow
+	for rows.Next() {
+		var row storageRow
+		if scanErr := rows.Scan(&row.name, &row.bytesOnDisk, &row.uncompressedBytes, &row.parts); scanErr != nil {
+			log.Error().Err(scanErr).Msg("can't scan storage row")
+			continue
+		}
+		level = append(level, row)
+	}
+	return level, nil
+}
+
+func (sp *storagePanel) metricColumn() string {
+	if sp.uncompressed {
+		return "uncompressed_bytes"
+	}
+	return "bytes_on_disk"
+}
+
+func (sp *storagePanel) metricValue(row storageRow) uint64 {
+	if sp.uncompressed {
+		return row.uncompressedBytes
+	}
+	return row.bytesOnDisk
+}
+
+// storageBar renders a proportional bar for value against the level's total
+func storageBar(value, total uint64) string {
+	if total == 0 {
+		return strings.Repeat("░", storageBarWidth)
+	}
+	filled := int(float64(value) / float64(total) * storageBarWidth)
+	return strings.Repeat("█", filled) + strings.Repeat("░", storageBarWidth-filled)
+}
+
+// levelTitle names the current drill level for the table title
+func (sp *storagePanel) levelTitle() string {
+	switch len(sp.path) {
+	case 1:
+		return fmt.Sprintf("tables of `%s`", sp.path[0])
+	case 2:
+		return fmt.Sprintf("partitions of `%s`.`%s`", sp.path[0], sp.path[1])
+	}
+	return "databases"
+}
+
+func (sp *storagePanel) render(level []storageRow) {
+	var total uint64
+	for _, row := range level {
+		total += sp.metricValue(row)
+	}
+
+	metricName := "bytes on disk"
+	if sp.uncompressed {
+		metricName = "uncompressed bytes"
+	}
+
+	filteredTable := widgets.NewFilteredTable()
+	filteredTable.Table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	keysHint := "Enter: open, u: toggle uncompressed, Esc: up"
+	if len(sp.path) == 1 {
+		keysHint = "Enter: open, c: column compression, u: toggle uncompressed, Esc: up"
+	}
+	filteredTable.Table.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle(fmt.Sprintf("Storage: %s | %s, total %sB [yellow](%s)[-]",
+			sp.levelTitle(), metricName, utils.FormatReadable(float64(total), 1), keysHint))
+	filteredTable.SetupHeaders([]string{"Name", "Size", "%", "Parts", ""})
+
+	for _, row := range level {
+		value := sp.metricValue(row)
+		share := 0.0
+		if total > 0 {
+			share = float64(value) / float64(total) * 100
+		}
+		color := tcell.ColorWhite
+		if share > 50 {
+			color = tcell.ColorRed
+		} else if share > 20 {
+			color = tcell.ColorYellow
+		}
+		filteredTable.AddRow([]*tview.TableCell{
+			tview.NewTableCell(row.name).SetTextColor(color),
+			tview.NewTableCell(utils.FormatReadable(float64(value), 1) + "B").SetTextColor(color),
+			tview.NewTableCell(fmt.Sprintf("%.1f%%", share)).SetTextColor(color),
+			tview.NewTableCell(fmt.Sprintf("%d", row.parts)).SetTextColor(color),
+			tview.NewTableCell(storageBar(value, total)).SetTextColor(tcell.ColorGreen),
+		})
+	}
+
+	existingHandler := filteredTable.GetInputCapture(sp.app.tviewApp, sp.app.pages)
+	filteredTable.Table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if result := existingHandler(event); result == nil {
+			return nil
+		}
+		switch event.Key() {
+		case tcell.KeyEscape:
+			if len(sp.path) == 0 {
+				sp.app.pages.RemovePage("storage")
+				sp.app.SwitchToMainPage("Returned from :storage")
+				return nil
+			}
+			sp.path = sp.path[:len(sp.path)-1]
+			sp.load()
+			return nil
+		case tcell.KeyEnter:
+			if len(sp.path) >= 2 {
+				return nil // partitions are the deepest level
+			}
+			row, _ := filteredTable.Table.GetSelection()
+			if row >= 1 && row <= len(level) {
+				sp.path = append(sp.path, level[row-1].name)
+				sp.load()
+			}
+			return nil
+		}
+		switch event.Rune() {
+		case 'u':
+			sp.uncompressed = !sp.uncompressed
+			sp.load()
+			return nil
+		case 'c':
+			// per-column compression analysis for the selected table
+			if len(sp.path) == 1 {
+				row, _ := filteredTable.Table.GetSelection()
+				if row >= 1 && row <= len(level) {
+					sp.app.ShowColumnCompression(sp.path[0], level[row-1].name)
+				}
+				return nil
+			}
+		}
+		return event
+	})
+
+	sp.app.pages.AddPage("storage", filteredTable.Table, true, true)
+	sp.app.pages.SwitchToPage("storage")
+	sp.app.tviewApp.SetFocus(filteredTable.Table)
+}
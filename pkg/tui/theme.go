@@ -0,0 +1,213 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// theme maps the semantic color roles used across panels to concrete colors,
+// so palettes can swap consistently instead of hardcoding ANSI colors that
+// look broken on light terminals
+type theme struct {
+	background tcell.Color
+	text       tcell.Color
+	border     tcell.Color
+	title      tcell.Color
+	// severity / level roles, shared by audit findings and log levels
+	critical tcell.Color
+	major    tcell.Color
+	warning  tcell.Color
+	ok       tcell.Color
+	accent   tcell.Color
+	// symbols conveys severity and level by markers and bold/underline in
+	// addition to color, for color-blind users and monochrome terminals
+	symbols bool
+}
+
+// themes are the built-in palettes selectable via the config file or :theme;
+// "dark" matches the colors the tool always used
+var themes = map[string]theme{
+	"dark": {
+		background: tcell.ColorBlack,
+		text:       tcell.ColorWhite,
+		border:     tcell.ColorWhite,
+		title:      tcell.ColorWhite,
+		critical:   tcell.ColorRed,
+		major:      tcell.ColorOrange,
+		warning:    tcell.ColorYellow,
+		ok:         tcell.ColorGreen,
+		accent:     tcell.ColorAqua,
+	},
+	"light": {
+		background: tcell.ColorWhite,
+		text:       tcell.ColorBlack,
+		border:     tcell.ColorGray,
+		title:      tcell.ColorBlack,
+		critical:   tcell.ColorDarkRed,
+		major:      tcell.ColorDarkOrange,
+		warning:    tcell.ColorDarkGoldenrod,
+		ok:         tcell.ColorDarkGreen,
+		accent:     tcell.ColorDarkBlue,
+	},
+	"monochrome": {
+		background: tcell.ColorDefault,
+		text:       tcell.ColorDefault,
+		border:     tcell.ColorDefault,
+		title:      tcell.ColorDefault,
+		critical:   tcell.ColorDefault,
+		major:      tcell.ColorDefault,
+		warning:    tcell.ColorDefault,
+		ok:         tcell.ColorDefault,
+		accent:     tcell.ColorDefault,
+		symbols:    true,
+	},
+	"high-contrast": {
+		background: tcell.ColorBlack,
+		text:       tcell.ColorWhite,
+		border:     tcell.ColorWhite,
+		title:      tcell.ColorWhite,
+		critical:   tcell.NewHexColor(0xff5555),
+		major:      tcell.NewHexColor(0xffaa00),
+		warning:    tcell.NewHexColor(0xffff55),
+		ok:         tcell.NewHexColor(0x55ff55),
+		accent:     tcell.NewHexColor(0x55ffff),
+		symbols:    true,
+	},
+	"solarized": {
+		background: tcell.NewHexColor(0x002b36),
+		text:       tcell.NewHexColor(0x839496),
+		border:     tcell.NewHexColor(0x586e75),
+		title:      tcell.NewHexColor(0x93a1a1),
+		critical:   tcell.NewHexColor(0xdc322f),
+		major:      tcell.NewHexColor(0xcb4b16),
+		warning:    tcell.NewHexColor(0xb58900),
+		ok:         tcell.NewHexColor(0x859900),
+		accent:     tcell.NewHexColor(0x268bd2),
+	},
+}
+
+// currentTheme is what the color helpers below read; the default matches the
+// historical hardcoded palette
+var currentTheme = themes["dark"]
+
+// themeNames returns the available palette names sorted for stable display
+func themeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyTheme switches the active palette and pushes it into the global tview
+// styles (backgrounds, borders, titles) that primitives pick up on creation;
+// unknown names are an error so a config typo doesn't silently keep "dark"
+func applyTheme(name string) error {
+	if name == "" {
+		name = "dark"
+	}
+	selected, found := themes[name]
+	if !found {
+		return fmt.Errorf("unknown theme '%s', available: %v", name, themeNames())
+	}
+	currentTheme = selected
+
+	tview.Styles.PrimitiveBackgroundColor = selected.background
+	tview.Styles.ContrastBackgroundColor = selected.background
+	tview.Styles.PrimaryTextColor = selected.text
+	tview.Styles.BorderColor = selected.border
+	tview.Styles.TitleColor = selected.title
+	tview.Styles.GraphicsColor = selected.border
+	return nil
+}
+
+// severityMarker prefixes a severity with a symbol in themes that don't rely
+// on color alone; the markers keep their meaning when colors are identical
+func severityMarker(severity string) string {
+	if !currentTheme.symbols {
+		return ""
+	}
+	switch severity {
+	case "Critical":
+		return "!!! "
+	case "Major":
+		return "!!  "
+	case "Moderate":
+		return "!   "
+	case "Minor":
+		return "~   "
+	}
+	return "    "
+}
+
+// severityAttrs returns the text attributes for a severity row in themes that
+// don't rely on color alone
+func severityAttrs(severity string) tcell.AttrMask {
+	if !currentTheme.symbols {
+		return tcell.AttrNone
+	}
+	switch severity {
+	case "Critical":
+		return tcell.AttrBold | tcell.AttrUnderline
+	case "Major":
+		return tcell.AttrBold
+	}
+	return tcell.AttrNone
+}
+
+// levelMarker is the log-level counterpart of severityMarker; callers pass
+// values through canonicalLevel first, like with levelColor
+func levelMarker(level string) string {
+	if !currentTheme.symbols {
+		return ""
+	}
+	switch strings.ToLower(level) {
+	case "error", "exception", "fatal", "critical":
+		return "[E] "
+	case "warning", "warn":
+		return "[W] "
+	case "info", "information", "notice":
+		return "[I] "
+	case "debug":
+		return "[D] "
+	case "trace":
+		return "[T] "
+	}
+	return ""
+}
+
+// showThemeSelector switches the palette for this session; already drawn
+// pages keep their colors until re-rendered, so it lands back on main
+func (a *App) showThemeSelector() {
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).SetTitleAlign(tview.AlignLeft).
+		SetTitle("Theme [yellow](Esc: cancel)[-]")
+	for _, name := range themeNames() {
+		themeName := name
+		list.AddItem(name, "", 0, func() {
+			if err := applyTheme(themeName); err != nil {
+				a.SwitchToMainPage(err.Error())
+				return
+			}
+			a.pages.RemovePage("themepicker")
+			a.SwitchToMainPage(fmt.Sprintf("Theme set to %s (already open pages re-color when reopened)", themeName))
+		})
+	}
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			a.pages.RemovePage("themepicker")
+			a.pages.SwitchToPage("main")
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("themepicker", list, true, true)
+	a.pages.SwitchToPage("themepicker")
+	a.tviewApp.SetFocus(list)
+}
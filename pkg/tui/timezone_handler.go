@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Slach/clickhouse-timeline/pkg/timezone"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showTimezoneSelector opens an input with IANA zone autocomplete to pick the
+// zone every displayed timestamp is converted to; "utc", "local" and "server"
+// (the connected instance's timezone()) are accepted alongside zone names.
+// Query WHERE clauses are unaffected, their layouts carry explicit offsets.
+func (a *App) showTimezoneSelector() {
+	statusText := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(fmt.Sprintf("Current display timezone: [green]%s[white]", utils.DisplayLocationName()))
+
+	tzInput := tview.NewInputField().
+		SetLabel("Timezone: ").
+		SetFieldWidth(40).
+		SetPlaceholder("utc, local, server, or type to search...")
+
+	tzInput.SetAutocompleteFunc(func(currentText string) []string {
+		if currentText == "" {
+			return nil
+		}
+		var matches []string
+		lowerText := strings.ToLower(currentText)
+		for _, tz := range timezone.TimeZones {
+			if strings.Contains(strings.ToLower(tz.DisplayText), lowerText) {
+				matches = append(matches, tz.DisplayText)
+				// Limit results to avoid overwhelming the UI
+				if len(matches) >= 15 {
+					break
+				}
+			}
+		}
+		return matches
+	})
+
+	tzInput.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			a.pages.RemovePage("tzpicker")
+			a.pages.SwitchToPage("main")
+			return
+		}
+		if key != tcell.KeyEnter {
+			return
+		}
+		name := strings.TrimSpace(tzInput.GetText())
+		if name == "" {
+			return
+		}
+		// autocomplete inserts the display text, map it back to the zone name
+		for _, tz := range timezone.TimeZones {
+			if tz.DisplayText == name {
+				name = tz.Name
+				break
+			}
+		}
+		a.pages.RemovePage("tzpicker")
+		a.applyDisplayTimezone(name)
+	})
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(statusText, 1, 0, false).
+		AddItem(nil, 1, 0, false). // Add padding
+		AddItem(tzInput, 1, 0, true)
+	flex.SetBorder(true).SetTitle("Set Display Timezone")
+
+	a.pages.AddPage("tzpicker", flex, true, true)
+	a.pages.SwitchToPage("tzpicker")
+	a.tviewApp.SetFocus(tzInput)
+}
+
+// applyDisplayTimezone resolves "server" against the connected instance and
+// applies the zone to the shared timestamp formatters
+func (a *App) applyDisplayTimezone(name string) {
+	if strings.EqualFold(name, "server") {
+		if a.clickHouse == nil {
+			a.SwitchToMainPage("Not connected - use :connect first to use the server timezone")
+			return
+		}
+		go func() {
+			var serverTz string
+			scanErr := a.clickHouse.QueryRow("SELECT timezone()").Scan(&serverTz)
+			a.tviewApp.QueueUpdateDraw(func() {
+				if scanErr != nil {
+					a.SwitchToMainPage(fmt.Sprintf("Can't read server timezone: %v", scanErr))
+					return
+				}
+				a.setDisplayTimezone(serverTz)
+			})
+		}()
+		return
+	}
+	a.setDisplayTimezone(name)
+}
+
+func (a *App) setDisplayTimezone(name string) {
+	if tzErr := utils.SetDisplayLocation(name); tzErr != nil {
+		a.SwitchToMainPage(fmt.Sprintf("Unknown timezone %q: %v", name, tzErr))
+		return
+	}
+	a.SwitchToMainPage(fmt.Sprintf("Display timezone set to %s (applies to newly rendered timestamps)", utils.DisplayLocationName()))
+}
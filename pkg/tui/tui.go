@@ -0,0 +1,688 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/client"
+	"github.com/Slach/clickhouse-timeline/pkg/config"
+	"github.com/Slach/clickhouse-timeline/pkg/tui/widgets"
+	"github.com/Slach/clickhouse-timeline/pkg/types"
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rs/zerolog/log"
+)
+
+var logo = `
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]  [yellow::b]██
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]  [yellow::b]██  
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]  [yellow::b]██  
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]  [yellow::b]██  
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]  [yellow::b]██  
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]  [yellow::b]██
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]
+[yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]
+[red::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]  
+[red::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]  
+[red::b]████[white] [yellow::b]████[white] [yellow::b]████[white] [yellow::b]████[white]  
+                                                      
+`
+
+type App struct {
+	cfg *config.Config
+	// connection
+	clickHouse      *client.Client
+	cluster         string
+	clusterList     *tview.List
+	selectedContext *config.Context
+
+	tviewApp *tview.Application
+	// screen is kept so clipboard escapes can be sent through the terminal
+	screen       tcell.Screen
+	pages        *tview.Pages
+	connectList  *tview.List
+	mainView     *tview.TextView
+	commandInput *tview.InputField
+	mainFlex     *tview.Flex
+	version      string
+	CLI          *types.CLI
+
+	// Date range fields
+	fromTime        time.Time
+	toTime          time.Time
+	initialFromTime time.Time
+	initialToTime   time.Time
+	rangeForm       *tview.Form
+
+	// Heatmap fields
+	categoryType  CategoryType
+	heatmapMetric HeatmapMetric
+	scaleType     ScaleType
+	heatmapTable  *tview.Table
+	// sorted values of the current visualization, used by the percentile scale
+	scaleSamples []float64
+	categoryList  *tview.List
+	metricList    *tview.List
+	scaleList     *tview.List
+
+	// Selection fields for flamegraph integration
+	categoryValue       string
+	flamegraphTimeStamp time.Time
+
+	//use Native Flamegraph widget
+	flamegraphNative bool
+
+	// defer automatic queries until explicitly requested
+	safeMode bool
+
+	// Log panel state
+	logPanel *LogPanel
+	// panels saved by pivot actions, restorable with Ctrl+B
+	logPanelStack []*LogPanel
+	// queryCtx is cancelled when the user returns to the main page, aborting in-flight queries
+	queryCtx    context.Context
+	queryCancel context.CancelFunc
+
+	// visited pages for Alt+Left/Alt+Right back and forward navigation
+	nav navigationHistory
+}
+
+func NewApp(cfg *config.Config, version string) *App {
+	if cfg != nil {
+		if themeErr := applyTheme(cfg.Theme); themeErr != nil {
+			log.Error().Err(themeErr).Msg("can't apply configured theme")
+		}
+	}
+	now := time.Now()
+	app := &App{
+		cfg:             cfg,
+		tviewApp:        tview.NewApplication(),
+		version:         version,
+		fromTime:        now.Add(-24 * time.Hour), // Default: 24 hours ago
+		toTime:          now,                      // Default: now
+		initialFromTime: now.Add(-24 * time.Hour), // Store initial range
+		initialToTime:   now,                      // Store initial range
+		categoryType:    CategoryQueryHash,        // Default categoryType
+		heatmapMetric:   MetricCount,              // Default metric
+		scaleType:       ScaleLinear,              // Default scale
+		CLI:             &types.CLI{},             // Initialize empty CLI
+	}
+
+	app.setupUI()
+	return app
+}
+
+// copyToClipboard sends text to the system clipboard and returns a short
+// status message the caller can show in a title or status line
+func (a *App) copyToClipboard(text string) string {
+	if err := widgets.CopyToClipboard(a.screen, text); err != nil {
+		return fmt.Sprintf("Copy failed: %v", err)
+	}
+	return fmt.Sprintf("Copied %d bytes to clipboard", len(text))
+}
+
+// queryContext returns the context panel queries should run under; it stays
+// valid until cancelQueries is called on navigation
+func (a *App) queryContext() context.Context {
+	if a.queryCtx == nil {
+		a.queryCtx, a.queryCancel = context.WithCancel(context.Background())
+	}
+	return a.queryCtx
+}
+
+// cancelQueries aborts in-flight panel queries server-side and prepares a
+// fresh context for the next panel
+func (a *App) cancelQueries() {
+	if a.queryCancel != nil {
+		a.queryCancel()
+	}
+	a.queryCtx, a.queryCancel = context.WithCancel(context.Background())
+}
+
+func (a *App) SwitchToMainPage(mainMsg string) {
+	a.cancelQueries()
+	if a.selectedContext != nil {
+		mainMsg += fmt.Sprintf("\nConnected to %s", a.getContextString(*a.selectedContext))
+	}
+	if a.heatmapMetric != "" {
+		mainMsg += fmt.Sprintf("\nSet heatmap metric to %s", a.heatmapMetric)
+	}
+	if a.categoryType != "" {
+		mainMsg += fmt.Sprintf("\nSet flamegraph category to %s", a.categoryValue)
+	}
+	mainMsg += "\nPress ':' to continue"
+	a.mainView.SetText(mainMsg)
+	a.pages.SwitchToPage("main")
+	a.tviewApp.SetFocus(a.mainView)
+}
+
+func (a *App) ApplyCLIParameters(c *types.CLI, commandName string) {
+	mainMsg := ""
+	a.flamegraphNative = c.FlamegraphNative
+	a.safeMode = c.SafeMode
+	if a.safeMode {
+		mainMsg += "Safe mode enabled: automatic queries are deferred until explicitly requested\n"
+	}
+	// Check if flamelens binary exists, if not then use native flamegraph
+	if _, err := exec.LookPath("flamelens"); err != nil {
+		a.flamegraphNative = true
+		log.Info().Msg("flamelens binary not found in PATH, using native flamegraph viewer")
+	}
+
+	// --resume restores the previous clean exit's session first, explicit
+	// flags below still win over the restored values
+	if c.Resume {
+		if session := readSessionFile(); session != nil {
+			mainMsg += fmt.Sprintf("Resumed session saved at %s%s\n", session.WrittenAt, a.applyJournalState(session))
+		} else {
+			mainMsg += "No saved session to resume\n"
+		}
+	}
+
+	if c.ConnectTo != "" {
+		if found := a.SetConnectByName(c.ConnectTo); !found {
+			mainMsg += fmt.Sprintf("Error: Context '%s' not found\nAvailable contexts:\n%s", c.ConnectTo, a.GetContextList())
+		} else {
+			mainMsg += fmt.Sprintf("Set connect context to: '%s'\n", c.ConnectTo)
+		}
+	}
+
+	if c.FromTime != "" {
+		if t, err := utils.ParseTimeFlag(c.FromTime, time.Now()); err == nil {
+			a.SetFromTime(t)
+			mainMsg += fmt.Sprintf("Set time range from: '%s'\n", utils.FormatTimestamp(a.fromTime))
+		} else {
+			mainMsg += fmt.Sprintf("can't parse --from='%s': %v\n", c.FromTime, err)
+		}
+	}
+
+	if c.ToTime != "" {
+		if t, err := utils.ParseTimeFlag(c.ToTime, time.Now()); err == nil {
+			a.SetToTime(t)
+			mainMsg += fmt.Sprintf("Set time range to: '%s'\n", utils.FormatTimestamp(a.toTime))
+		} else {
+			mainMsg += fmt.Sprintf("can't parse --to='%s': %v\n", c.ToTime, err)
+		}
+	}
+
+	if c.RangeOption != "" {
+		a.ApplyPredefinedRange(c.RangeOption)
+		mainMsg += fmt.Sprintf("Set time range '%s' from: '%s' to: '%s'\n", c.RangeOption, utils.FormatTimestamp(a.fromTime), utils.FormatTimestamp(a.toTime))
+	}
+
+	// Update initial time range after applying CLI parameters
+	a.initialFromTime = a.fromTime
+	a.initialToTime = a.toTime
+
+	if c.Cluster != "" {
+		a.SetCluster(c.Cluster)
+		mainMsg += fmt.Sprintf("Set cluster '%s'\n", c.Cluster)
+	}
+
+	if c.Metric != "" {
+		a.SetMetric(c.Metric)
+		mainMsg += fmt.Sprintf("Set metric '%s'\n", c.Metric)
+	}
+
+	if c.Category != "" {
+		a.SetCategory(c.Category)
+		mainMsg += fmt.Sprintf("Set categoryType '%s'\n", c.Category)
+	}
+
+	// Handle command execution if specified
+	if commandName != "" {
+		mainMsg += a.executeCommand(commandName)
+	}
+
+	if mainMsg != "" {
+		mainMsg += "Press ':' to continue"
+		a.mainView.SetText(mainMsg)
+	}
+}
+
+func (a *App) SetFromTime(t time.Time) {
+	a.fromTime = t
+	// Update initial time if this is the first setting or a reset
+	if a.initialFromTime.IsZero() || a.initialToTime.IsZero() {
+		a.initialFromTime = t
+	}
+}
+
+func (a *App) SetToTime(t time.Time) {
+	a.toTime = t
+	// Update initial time if this is the first setting or a reset
+	if a.initialFromTime.IsZero() || a.initialToTime.IsZero() {
+		a.initialToTime = t
+	}
+}
+
+func (a *App) SetCluster(cluster string) {
+	a.cluster = cluster
+}
+
+func (a *App) SetMetric(metric string) {
+	a.heatmapMetric = HeatmapMetric(metric)
+}
+
+func (a *App) SetCategory(category string) {
+	a.categoryType = CategoryType(category)
+}
+
+func (a *App) GetContextList() string {
+	var contextList strings.Builder
+	for _, ctx := range a.cfg.Contexts {
+		contextList.WriteString(fmt.Sprintf("  - %s\n", ctx.Name))
+	}
+	return contextList.String()
+}
+
+func (a *App) SetConnectByName(contextName string) bool {
+	if len(a.cfg.Contexts) == 0 {
+		return false
+	}
+
+	found := false
+	for i, ctx := range a.cfg.Contexts {
+		if ctx.Name == contextName {
+			a.handleContextSelection(i)
+			found = true
+			break
+		}
+	}
+	return found
+}
+
+// executeCommand return message if something wrong, return empty string if all OK
+func (a *App) executeCommand(commandName string) string {
+	// Check prerequisites for commands that need them
+	if slices.Contains([]string{CmdHeatmap, CmdFlamegraph, CmdProfileEvents, CmdMetricLog, CmdAsyncMetricLog, CmdExplain, CmdLogs, CmdMemory, CmdProcesses, CmdProfiler, CmdReplication, CmdMerges, CmdTop, CmdIncident, CmdErrors, CmdStorage, CmdDictionaries, CmdBackups, CmdProjections}, commandName) {
+		if a.clickHouse == nil {
+			return "Error: Please connect to a ClickHouse instance first using :connect command\n"
+		}
+		if a.cluster == "" {
+			return "Error: Please select a cluster first using :cluster command\n"
+		}
+	}
+
+	switch commandName {
+	case CmdHeatmap:
+		a.ShowHeatmap()
+	case CmdFlamegraph:
+		a.ShowFlamegraphForm()
+	case CmdExplain:
+		a.ShowExplain(a.categoryType, a.categoryValue, a.fromTime, a.toTime, a.cluster)
+	case CmdProfileEvents:
+		a.ShowProfileEvents(
+			a.categoryType,
+			a.categoryValue,
+			a.fromTime,
+			a.toTime,
+			a.cluster,
+		)
+	case CmdMetricLog:
+		a.ShowMetricLog(a.fromTime, a.toTime, a.cluster)
+	case CmdAsyncMetricLog:
+		a.ShowAsynchronousMetricLog(a.fromTime, a.toTime, a.cluster)
+	case CmdMemory:
+		a.ShowMemory()
+	case CmdProcesses:
+		a.ShowProcessWatch()
+	case CmdTop:
+		a.ShowProcessList()
+	case CmdIncident:
+		a.ShowIncidentReport()
+	case CmdErrors:
+		a.ShowErrorExplorer()
+	case CmdStorage:
+		a.ShowStorageBreakdown()
+	case CmdDictionaries:
+		a.ShowDictionaries()
+	case CmdBackups:
+		a.ShowBackups()
+	case CmdProjections:
+		a.ShowProjectionUsage()
+	case CmdProfiler:
+		a.ShowQueryProfiler()
+	case CmdReplication:
+		a.ShowReplicationLag()
+	case CmdMerges:
+		a.ShowMergesMonitor()
+	case CmdSQL:
+		// the console queries the connected server directly, no cluster needed
+		if a.clickHouse == nil {
+			return "Error: Please connect to a ClickHouse instance first using :connect command\n"
+		}
+		a.ShowSQLConsole()
+	case CmdLogs:
+		// Only apply CLI params when explicitly executing logs command
+		// Initialize log panel with CLI params if available
+		a.logPanel = &LogPanel{
+			app:          a,
+			windowSize:   1000,
+			database:     "",
+			table:        "",
+			messageField: "",
+			timeField:    "",
+			timeMsField:  "",
+			dateField:    "",
+			levelField:   "",
+		}
+
+		if a.CLI != nil {
+			if a.CLI.LogsParams.Database != "" {
+				a.logPanel.database = a.CLI.LogsParams.Database
+			}
+			if a.CLI.LogsParams.Table != "" {
+				a.logPanel.table = a.CLI.LogsParams.Table
+			}
+			if a.CLI.LogsParams.Message != "" {
+				a.logPanel.messageField = a.CLI.LogsParams.Message
+			}
+			if a.CLI.LogsParams.Time != "" {
+				a.logPanel.timeField = a.CLI.LogsParams.Time
+			}
+			if a.CLI.LogsParams.TimeMs != "" {
+				a.logPanel.timeMsField = a.CLI.LogsParams.TimeMs
+			}
+			if a.CLI.LogsParams.Date != "" {
+				a.logPanel.dateField = a.CLI.LogsParams.Date
+			}
+			if a.CLI.LogsParams.Level != "" {
+				a.logPanel.levelField = a.CLI.LogsParams.Level
+			}
+			if len(a.CLI.LogsParams.ExtraSources) > 0 {
+				a.logPanel.extraSources = parseLogSources(a.CLI.LogsParams.ExtraSources)
+			}
+			if a.CLI.LogsParams.Window > 0 {
+				a.logPanel.windowSize = a.CLI.LogsParams.Window
+			}
+			if a.CLI.LogsParams.MessageLength > 0 {
+				a.logPanel.maxMessageLength = a.CLI.LogsParams.MessageLength
+			}
+		}
+		a.logPanel.Show()
+	case CmdAudit:
+		a.ShowAudit()
+	case CmdDensity:
+		return fmt.Sprintf("Table row density set to %s (applies to newly opened tables)\n", utils.ToggleRowDensity())
+	}
+	return ""
+}
+
+func (a *App) ApplyPredefinedRange(rangeOption string) {
+	a.toTime = time.Now()
+	switch rangeOption {
+	case "1h":
+		a.fromTime = a.toTime.Add(-1 * time.Hour)
+	case "24h":
+		a.fromTime = a.toTime.Add(-24 * time.Hour)
+	case "7d":
+		a.fromTime = a.toTime.Add(-7 * 24 * time.Hour)
+	case "30d":
+		a.fromTime = a.toTime.Add(-30 * 24 * time.Hour)
+	default:
+		a.fromTime = a.toTime.Add(-24 * time.Hour)
+	}
+}
+
+func (a *App) setupUI() {
+	a.pages = tview.NewPages()
+	a.initNavigationHistory()
+
+	// ClickHouse ASCII logo
+
+	a.mainView = tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft).
+		SetScrollable(true).
+		SetText(logo + "\nWelcome to ClickHouse Timeline\nPress ':' to enter command mode\n\nTip: To copy text from any view, use your terminal's selection (mouse drag) and copy (Ctrl+Shift+C or Cmd+C)")
+
+	a.connectList = tview.NewList()
+	a.connectList.SetMainTextColor(tcell.ColorWhite)
+	a.connectList.SetShortcutColor(tcell.ColorYellow)
+	a.connectList.SetSelectedTextColor(tcell.ColorBlack)
+	a.connectList.SetSelectedBackgroundColor(tcell.ColorGreen)
+	a.connectList.SetWrapAround(true)
+	a.connectList.SetBorder(true)
+	a.connectList.SetTitle("Connections")
+	a.connectList.ShowSecondaryText(false)
+	a.connectList.SetHighlightFullLine(true)
+
+	// Initialize connections list
+	// Prepare items for filtering
+	var items []string
+	for _, ctx := range a.cfg.Contexts {
+		items = append(items, a.getContextString(ctx))
+	}
+
+	fl := widgets.NewFilteredList(
+		a.connectList,
+		"Connections",
+		items,
+		"contexts",
+	)
+	fl.ResetList()
+
+	a.commandInput = tview.NewInputField().
+		SetLabel(":").
+		SetFieldWidth(30)
+
+	a.mainFlex = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(a.commandInput, 0, 0, false). // height 0 to hide
+		AddItem(a.mainView, 0, 1, true)
+
+	a.pages.AddPage("main", a.mainFlex, true, true)
+	a.pages.AddPage("contexts", a.connectList, true, false)
+
+	a.tviewApp.SetRoot(a.pages, true)
+	a.tviewApp.EnableMouse(true)
+
+	a.setupKeybindings()
+}
+
+func (a *App) defaultInputHandler(event *tcell.EventKey) *tcell.EventKey {
+	if event.Rune() == ':' {
+		currentFocus := a.tviewApp.GetFocus()
+		// Don't trigger command mode when editing time fields
+		if frontPage, _ := a.pages.GetFrontPage(); frontPage == "datepicker" {
+			// If we're editing a time field, don't trigger command mode
+			if _, ok := currentFocus.(*tview.InputField); ok {
+				return event
+			}
+		}
+
+		// Otherwise proceed with command mode
+		if a.pages.HasPage("main") {
+			a.pages.SwitchToPage("main")
+			a.commandInput.SetText("")
+			a.mainFlex.ResizeItem(a.commandInput, 1, 0) // Show with height 1
+			a.tviewApp.SetFocus(a.commandInput)
+			return nil
+		}
+	}
+
+	// Alt+Left / Alt+Right walk back and forward through the visited pages,
+	// restoring them with their state intact
+	if event.Modifiers()&tcell.ModAlt != 0 {
+		if event.Key() == tcell.KeyLeft {
+			a.navigateHistory(-1)
+			return nil
+		}
+		if event.Key() == tcell.KeyRight {
+			a.navigateHistory(1)
+			return nil
+		}
+	}
+
+	// Global cheatsheet toggle; skipped while typing in an input so '?'
+	// still works in filters and the SQL console
+	if event.Rune() == '?' {
+		switch a.tviewApp.GetFocus().(type) {
+		case *tview.InputField, *tview.TextArea:
+		default:
+			a.toggleKeymapOverlay()
+			return nil
+		}
+	}
+
+	// Ctrl+K opens the command palette; skipped in inputs where Ctrl+K is the
+	// readline kill-to-end-of-line
+	if event.Key() == tcell.KeyCtrlK {
+		switch a.tviewApp.GetFocus().(type) {
+		case *tview.InputField, *tview.TextArea:
+		default:
+			a.showCommandPalette()
+			return nil
+		}
+	}
+
+	return event
+}
+
+func (a *App) setupKeybindings() {
+	a.tviewApp.SetInputCapture(a.defaultInputHandler)
+
+	a.commandInput.
+		SetAutocompleteFunc(func(currentText string) []string {
+			var matches []string
+			for _, cmd := range availableCommands {
+				if strings.Contains(cmd, currentText) {
+					matches = append(matches, cmd)
+				}
+			}
+			return matches
+		}).
+		SetDoneFunc(func(key tcell.Key) {
+			if key == tcell.KeyEnter {
+				a.mainFlex.ResizeItem(a.commandInput, 0, 0) // Hide with height 0
+				cmd := strings.TrimSpace(a.commandInput.GetText())
+				a.dispatchCommand(cmd)
+			}
+		})
+
+	a.connectList.SetSelectedFunc(func(i int, _ string, _ string, _ rune) {
+		a.handleContextSelection(i)
+	})
+}
+
+// dispatchCommand runs one ':' command by name; it is shared between the
+// command prompt and the command palette so both go through the same handlers
+func (a *App) dispatchCommand(cmd string) {
+	switch cmd {
+	case CmdHelp:
+		a.mainView.SetText(helpText)
+	case CmdConnect:
+		a.handleConnectCommand()
+	case CmdQuit:
+		a.handleQuitCommand()
+	case CmdFrom:
+		a.showFromDatePicker()
+	case CmdTo:
+		a.showToDatePicker()
+	case CmdRange:
+		a.showRangePicker()
+	case CmdBookmarks:
+		a.showBookmarksMenu()
+	case CmdTz:
+		a.showTimezoneSelector()
+	case CmdTheme:
+		a.showThemeSelector()
+	case CmdQueries:
+		a.showQueryLog()
+	case CmdCategory:
+		a.showCategorySelector()
+	case CmdCluster:
+		a.showClusterSelector()
+	case CmdMetric:
+		a.showMetricSelector()
+	case CmdScale:
+		a.showScaleSelector()
+	case CmdAudit:
+		a.executeCommand(CmdAudit)
+	case CmdMemory:
+		mainMsg := a.executeCommand(CmdMemory)
+		if mainMsg != "" {
+			a.SwitchToMainPage(mainMsg)
+		}
+	default:
+		mainMsg := a.executeCommand(cmd)
+		if mainMsg != "" {
+			a.SwitchToMainPage(mainMsg)
+		}
+	}
+}
+
+func (a *App) Run() (runErr error) {
+	defer func() {
+		if a.clickHouse != nil {
+			if err := a.clickHouse.Close(); err != nil {
+				log.Error().Err(err).Stack().Send()
+			}
+		}
+	}()
+	// restore the terminal and leave a crash report instead of dying with a
+	// garbled screen; the state journal stays on disk so the next start can
+	// offer to resume
+	defer func() {
+		if panicValue := recover(); panicValue != nil {
+			a.tviewApp.Stop()
+			stack := debug.Stack()
+			log.Error().Interface("panic", panicValue).Bytes("stack", stack).Msg("TUI panic")
+			if reportPath := a.writeCrashReport(panicValue, stack); reportPath != "" {
+				runErr = fmt.Errorf("panic: %v (crash report written to %s)", panicValue, reportPath)
+			} else {
+				runErr = fmt.Errorf("panic: %v", panicValue)
+			}
+		}
+	}()
+
+	// Create the screen ourselves so copyToClipboard can reach it later;
+	// when that fails tview falls back to creating its own
+	if screen, screenErr := tcell.NewScreen(); screenErr == nil {
+		a.screen = screen
+		a.tviewApp.SetScreen(screen)
+	}
+
+	if journal := readStateJournal(); journal != nil {
+		a.offerResumeFromJournal(journal)
+	}
+
+	stopJournal := make(chan struct{})
+	defer close(stopJournal)
+	go func() {
+		for {
+			select {
+			case <-stopJournal:
+				return
+			case <-time.After(journalInterval):
+				a.writeStateJournal()
+			}
+		}
+	}()
+
+	runErr = a.tviewApp.Run()
+	if runErr == nil {
+		clearStateJournal()
+		a.writeSessionFile()
+	}
+	return runErr
+}
@@ -0,0 +1,44 @@
+package widgets
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// clipboardTools are native clipboard writers tried in order as a fallback
+// for terminals that ignore the OSC 52 escape
+var clipboardTools = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+}
+
+// CopyToClipboard posts text to the system clipboard. The OSC 52 escape is
+// emitted through the screen first (terminals forward it even over SSH), then
+// a native clipboard tool is tried as a fallback. An error is returned only
+// when neither path could plausibly have worked.
+func CopyToClipboard(screen tcell.Screen, text string) error {
+	if screen != nil {
+		screen.SetClipboard([]byte(text))
+	}
+	for _, tool := range clipboardTools {
+		path, lookErr := exec.LookPath(tool[0])
+		if lookErr != nil {
+			continue
+		}
+		cmd := exec.Command(path, tool[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if runErr := cmd.Run(); runErr == nil {
+			return nil
+		}
+	}
+	if screen != nil {
+		// OSC 52 was sent, assume the terminal handled it
+		return nil
+	}
+	return fmt.Errorf("no clipboard tool found (tried pbcopy, wl-copy, xclip, xsel)")
+}
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"time"
+
+	"github.com/Slach/clickhouse-timeline/pkg/utils"
+)
+
+type CLI struct {
+	FromTime         string
+	ToTime           string
+	RangeOption      string
+	ConnectTo        string
+	Cluster          string
+	Metric           string
+	Category         string
+	ConfigPath       string
+	LogPath          string
+	Pprof            bool
+	PprofPath        string
+	FlamegraphNative bool
+	SafeMode         bool
+	Resume           bool
+	Format           string
+	AuditOutput      string
+	AuditFile        string
+	AuditBaseline    string
+	AuditCheck       string
+	AuditWatch       time.Duration
+	MetricsListen    string
+	LogsParams       LogsParams
+}
+
+type LogsParams struct {
+	Database      string
+	Table         string
+	Message       string
+	Time          string
+	TimeMs        string
+	Date          string
+	Level         string
+	Window        int
+	MessageLength int
+	ExtraSources  []string
+	Where         string
+	Output        string
+	Limit         int
+}
+
+func (c *CLI) ParseToTime() (time.Time, error) {
+	return utils.ParseTimeFlag(c.ToTime, time.Now())
+}
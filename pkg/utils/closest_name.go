@@ -0,0 +1,51 @@
+package utils
+
+import "strings"
+
+// ClosestName returns the candidate with the smallest Levenshtein distance to
+// target (case-insensitive), or "" when nothing is reasonably close.
+func ClosestName(target string, candidates []string) string {
+	best := ""
+	bestDistance := len(target)/2 + 1 // don't suggest anything further away than half the name
+	for _, candidate := range candidates {
+		d := levenshtein(strings.ToLower(target), strings.ToLower(candidate))
+		if d < bestDistance {
+			bestDistance = d
+			best = candidate
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	if a == b {
+		return 0
+	}
+	prev := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := 0; j <= len(b); j++ {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(prev[j]+1, current[j-1]+1, prev[j-1]+cost)
+		}
+		prev, current = current, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, v := range values[1:] {
+		if v < result {
+			result = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"strings"
+	"time"
+)
+
+// Table row density, selected via the row_density config option and toggled
+// with the :density command. Comfortable (the default) keeps the short
+// timestamp layout and the column separator space; compact collapses table
+// timestamps to time-only and drops the separator so small terminals fit
+// substantially more content per screen.
+var compactRows bool
+
+// timestampCompactFormat is used for table cells in compact density
+const timestampCompactFormat = "15:04:05"
+
+// SetRowDensity selects the density mode, anything but "compact" is comfortable
+func SetRowDensity(density string) {
+	compactRows = strings.EqualFold(density, "compact")
+}
+
+// ToggleRowDensity flips the density mode and returns the new mode name
+func ToggleRowDensity() string {
+	compactRows = !compactRows
+	if compactRows {
+		return "compact"
+	}
+	return "comfortable"
+}
+
+// CompactRows reports whether tables should render in compact density
+func CompactRows() bool {
+	return compactRows
+}
+
+// FormatTimestampTable renders t for table cells: the short layout in
+// comfortable density, time-only in compact density
+func FormatTimestampTable(t time.Time) string {
+	if compactRows {
+		return inDisplayLocation(t).Format(timestampCompactFormat)
+	}
+	return FormatTimestampShort(t)
+}
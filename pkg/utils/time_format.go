@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"strings"
+	"time"
+)
+
+// Display layouts for timestamps, overridable via the timestamp_format and
+// timestamp_short_format config options. Query-building code keeps its own
+// fixed layouts understood by parseDateTimeBestEffort and must not use these.
+var (
+	timestampFormat      = "2006-01-02 15:04:05.000 MST"
+	timestampShortFormat = "2006-01-02 15:04:05"
+)
+
+// SetTimestampFormats overrides the display layouts, empty values keep defaults
+func SetTimestampFormats(full, short string) {
+	if full != "" {
+		timestampFormat = full
+	}
+	if short != "" {
+		timestampShortFormat = short
+	}
+}
+
+// displayLocation, when set, converts every displayed timestamp into one
+// zone before formatting; nil keeps timestamps in whatever zone they arrived
+// with, which on mixed-timezone clusters means mixed offsets on screen
+var displayLocation *time.Location
+
+// SetDisplayLocation selects the zone displayed timestamps are converted to:
+// "utc", "local", or an IANA name like "Europe/Berlin"; empty keeps
+// timestamps untouched. Query-building code is unaffected, the layouts it
+// uses carry explicit offsets.
+func SetDisplayLocation(name string) error {
+	switch strings.ToLower(name) {
+	case "":
+		displayLocation = nil
+		return nil
+	case "local":
+		displayLocation = time.Local
+		return nil
+	case "utc":
+		displayLocation = time.UTC
+		return nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return err
+	}
+	displayLocation = location
+	return nil
+}
+
+// DisplayLocationName names the current display zone for status messages
+func DisplayLocationName() string {
+	if displayLocation == nil {
+		return "as delivered"
+	}
+	return displayLocation.String()
+}
+
+// inDisplayLocation converts t for display when a zone is configured
+func inDisplayLocation(t time.Time) time.Time {
+	if displayLocation == nil {
+		return t
+	}
+	return t.In(displayLocation)
+}
+
+// FormatTimestamp renders t in the configured full display layout
+func FormatTimestamp(t time.Time) string {
+	return inDisplayLocation(t).Format(timestampFormat)
+}
+
+// FormatTimestampShort renders t in the configured short layout for dense tables
+func FormatTimestampShort(t time.Time) string {
+	return inDisplayLocation(t).Format(timestampShortFormat)
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/araddon/dateparse"
+	"github.com/pkg/errors"
+)
+
+// relativeFlagRegexp matches offsets like "-2h", "+30m" or "now-1h"
+var relativeFlagRegexp = regexp.MustCompile(`^(?:now)?([+-])(\d+)([smhdwMyY])$`)
+
+// RelativeDuration converts a Grafana-style value/unit pair into a duration
+func RelativeDuration(value int, unit string) (time.Duration, bool) {
+	switch unit {
+	case "s", "SS":
+		return time.Duration(value) * time.Second, true
+	case "m", "mm":
+		return time.Duration(value) * time.Minute, true
+	case "h", "H", "HH":
+		return time.Duration(value) * time.Hour, true
+	case "d", "D":
+		return time.Duration(value*24) * time.Hour, true
+	case "w", "W":
+		return time.Duration(value*7*24) * time.Hour, true
+	case "M", "MM":
+		return time.Duration(value*30*24) * time.Hour, true
+	case "y", "Y":
+		return time.Duration(value*365*24) * time.Hour, true
+	}
+	return 0, false
+}
+
+// ParseTimeFlag parses a --from/--to value: "now", relative offsets like
+// "-2h" or "now-30m", "today"/"yesterday" with an optional "09:00" time of
+// day, and finally any absolute timestamp dateparse understands
+func ParseTimeFlag(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, errors.New("empty time expression")
+	}
+	if strings.EqualFold(value, "now") {
+		return now, nil
+	}
+
+	if matches := relativeFlagRegexp.FindStringSubmatch(value); len(matches) == 4 {
+		amount, atoiErr := strconv.Atoi(matches[2])
+		if atoiErr != nil {
+			return time.Time{}, errors.Wrapf(atoiErr, "can't parse %q", value)
+		}
+		duration, ok := RelativeDuration(amount, matches[3])
+		if !ok {
+			return time.Time{}, errors.Errorf("unknown time unit %q in %q", matches[3], value)
+		}
+		if matches[1] == "-" {
+			duration = -duration
+		}
+		return now.Add(duration), nil
+	}
+
+	fields := strings.Fields(value)
+	if keyword := strings.ToLower(fields[0]); keyword == "today" || keyword == "yesterday" {
+		day := now
+		if keyword == "yesterday" {
+			day = now.AddDate(0, 0, -1)
+		}
+		y, m, d := day.Date()
+		if len(fields) == 1 {
+			return time.Date(y, m, d, 0, 0, 0, 0, now.Location()), nil
+		}
+		if len(fields) == 2 {
+			clock, clockErr := time.Parse("15:04:05", fields[1])
+			if clockErr != nil {
+				clock, clockErr = time.Parse("15:04", fields[1])
+			}
+			if clockErr == nil {
+				return time.Date(y, m, d, clock.Hour(), clock.Minute(), clock.Second(), 0, now.Location()), nil
+			}
+		}
+		return time.Time{}, errors.Errorf("can't parse time of day in %q, expected e.g. %q", value, keyword+" 09:00")
+	}
+
+	return dateparse.ParseAny(value)
+}
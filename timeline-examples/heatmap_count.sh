@@ -0,0 +1 @@
+﻿clickhouse-client -q "SELECT toStartOfInterval(event_time, INTERVAL 1 minute) AS interval, normalized_query_hash, count() AS query_count FROM system.query_log WHERE type = 'QueryFinish' AND event_date >= today() GROUP BY interval, normalized_query_hash ORDER BY query_count DESC, normalized_query_hash FORMAT TSVRaw" | rare heat -m "([^\t]+)\t([^\t]+)\t([^\t]+)" -e '{timeformat {time {1}} "15:04" }' -e "{2}" -e "{3}" --rows 50
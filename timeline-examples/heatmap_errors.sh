@@ -0,0 +1 @@
+﻿clickhouse-client -q "SELECT toStartOfInterval(event_time, INTERVAL 1 minute) AS t, concat(errorCodeToName(exception_code),':',normalized_query_hash) AS err_q, count() AS err_count FROM system.query_log WHERE exception_code > 0 AND event_date >= today() GROUP BY ALL FORMAT TSVRaw" | rare heat -m "([^\t]+)\t([^\t]+)\t([^\t]+)" -e '{timeformat {time {1}} "15:04" }' -e "{2}" -e "{3}" --rows 50